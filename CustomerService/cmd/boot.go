@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"log"
 	config2 "tesodev-korpes/CustomerService/config"
 	"tesodev-korpes/CustomerService/internal"
 	"tesodev-korpes/pkg"
-	//"tesodev-korpes/pkg/middleware"
+	"tesodev-korpes/pkg/auth"
+	"tesodev-korpes/pkg/middleware"
+	sharedConfig "tesodev-korpes/shared/config"
 
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -17,9 +21,52 @@ func BootCustomerService(client *mongo.Client, e *echo.Echo) {
 		panic(err)
 	}
 
+	refreshTokenCol, err := pkg.GetMongoCollection(client, config.DbConfig.DBName, config.DbConfig.RefreshTokenColName)
+	if err != nil {
+		panic(err)
+	}
+
+	loginAttemptCol, err := pkg.GetMongoCollection(client, config.DbConfig.DBName, config.DbConfig.LoginAttemptColName)
+	if err != nil {
+		panic(err)
+	}
+
+	passwordResetCol, err := pkg.GetMongoCollection(client, config.DbConfig.DBName, config.DbConfig.PasswordResetColName)
+	if err != nil {
+		panic(err)
+	}
+
+	revokedTokenCol, err := pkg.GetMongoCollection(client, config.DbConfig.DBName, config.DbConfig.RevokedTokenColName)
+	if err != nil {
+		panic(err)
+	}
+
 	repo := internal.NewRepository(customerCol)
-	service := internal.NewService(repo)
-	internal.NewHandler(e, service, client)
+	tokenRepo := internal.NewRefreshTokenRepository(refreshTokenCol)
+	if err := tokenRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("refresh token indexes could not be created: %v", err)
+	}
+	attemptRepo := internal.NewLoginAttemptRepository(loginAttemptCol)
+	if err := attemptRepo.EnsureIndexes(context.Background(), config2.LoginLockout.Window); err != nil {
+		log.Printf("login attempt indexes could not be created: %v", err)
+	}
+	resetRepo := internal.NewPasswordResetRepository(passwordResetCol)
+	if err := resetRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("password reset indexes could not be created: %v", err)
+	}
+	denylist := auth.NewDenylist(revokedTokenCol, sharedConfig.GetAuthConfig().RevocationCacheTTL)
+	if err := denylist.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("token denylist indexes could not be created: %v", err)
+	}
+	mailConfig := config2.GetMailConfig()
+	var notifier internal.Notifier = internal.NewLogNotifier(mailConfig.OutboxFile)
+	if mailConfig.SMTPHost != "" {
+		notifier = internal.NewSMTPNotifier(mailConfig.SMTPHost, mailConfig.SMTPPort, mailConfig.SMTPUsername, mailConfig.SMTPPassword, mailConfig.From)
+	}
+	statuses := auth.NewUserStatusCache(customerCol, sharedConfig.GetAuthConfig().UserStatusCacheTTL)
+	service := internal.NewService(repo, tokenRepo, attemptRepo, resetRepo, denylist, statuses, notifier, mailConfig.VerificationURL)
+	internal.NewHandler(e, service)
+	middleware.MustCheckRoutePolicies(e, &sharedConfig.Cfg, "customer")
 	e.Logger.Fatal(e.Start(config.Port))
 
 }
@@ -1,13 +1,56 @@
 package config
 
+import "time"
+
 type CustomerConfig struct {
 	Port     string
 	DbConfig struct {
-		DBName  string
-		ColName string
+		DBName               string
+		ColName              string
+		RefreshTokenColName  string
+		LoginAttemptColName  string
+		PasswordResetColName string
+		RevokedTokenColName  string
 	}
 }
 
+// RefreshTokenTTL is how long a refresh token can be used. Every refresh
+// issues a new token with a fresh TTL.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// LoginLockout tunes the protection against password guessing. Once an
+// account or an IP reaches its threshold of failed logins it is locked for
+// BaseLock, doubling with every further failure up to MaxLock. Counters are
+// dropped Window after the last failure, and an account's counter is reset by
+// a successful login.
+var LoginLockout = struct {
+	AccountThreshold int
+	IPThreshold      int
+	BaseLock         time.Duration
+	MaxLock          time.Duration
+	Window           time.Duration
+}{
+	AccountThreshold: 5,
+	IPThreshold:      20,
+	BaseLock:         time.Minute,
+	MaxLock:          time.Hour,
+	Window:           24 * time.Hour,
+}
+
+// PasswordResetTTL is how long a password reset token can be used.
+const PasswordResetTTL = 30 * time.Minute
+
+// EmailVerification tunes the verification of new customers' email
+// addresses. A verification link is valid for TokenTTL, and a customer can
+// ask for a new one at most once per ResendInterval.
+var EmailVerification = struct {
+	TokenTTL       time.Duration
+	ResendInterval time.Duration
+}{
+	TokenTTL:       24 * time.Hour,
+	ResendInterval: time.Minute,
+}
+
 var RoleStatus = struct {
 	System struct {
 		Admin   string
@@ -41,31 +84,55 @@ var cfgs = map[string]CustomerConfig{
 	"prod": {
 		Port: ":8001",
 		DbConfig: struct {
-			DBName  string
-			ColName string
+			DBName               string
+			ColName              string
+			RefreshTokenColName  string
+			LoginAttemptColName  string
+			PasswordResetColName string
+			RevokedTokenColName  string
 		}{
-			DBName:  "tesodev",
-			ColName: "customer",
+			DBName:               "tesodev",
+			ColName:              "customer",
+			RefreshTokenColName:  "refresh_token",
+			LoginAttemptColName:  "login_attempt",
+			PasswordResetColName: "password_reset",
+			RevokedTokenColName:  "revoked_token",
 		},
 	},
 	"qa": {
 		Port: ":8001",
 		DbConfig: struct {
-			DBName  string
-			ColName string
+			DBName               string
+			ColName              string
+			RefreshTokenColName  string
+			LoginAttemptColName  string
+			PasswordResetColName string
+			RevokedTokenColName  string
 		}{
-			DBName:  "tesodev",
-			ColName: "customer",
+			DBName:               "tesodev",
+			ColName:              "customer",
+			RefreshTokenColName:  "refresh_token",
+			LoginAttemptColName:  "login_attempt",
+			PasswordResetColName: "password_reset",
+			RevokedTokenColName:  "revoked_token",
 		},
 	},
 	"dev": {
 		Port: ":8001",
 		DbConfig: struct {
-			DBName  string
-			ColName string
+			DBName               string
+			ColName              string
+			RefreshTokenColName  string
+			LoginAttemptColName  string
+			PasswordResetColName string
+			RevokedTokenColName  string
 		}{
-			DBName:  "tesodev",
-			ColName: "customer",
+			DBName:               "tesodev",
+			ColName:              "customer",
+			RefreshTokenColName:  "refresh_token",
+			LoginAttemptColName:  "login_attempt",
+			PasswordResetColName: "password_reset",
+			RevokedTokenColName:  "revoked_token",
 		},
 	},
 }
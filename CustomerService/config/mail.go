@@ -0,0 +1,37 @@
+package config
+
+import "os"
+
+// MailConfig selects how notifications reach customers. With SMTPHost set
+// they are sent through that server; otherwise they are appended to
+// OutboxFile, or written to the log when that is empty too.
+type MailConfig struct {
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	OutboxFile   string
+	// VerificationURL is the address of the email verification endpoint as
+	// customers reach it; the token is appended as a query parameter.
+	VerificationURL string
+}
+
+func GetMailConfig() MailConfig {
+	return MailConfig{
+		SMTPHost:        os.Getenv("SMTP_HOST"),
+		SMTPPort:        getenv("SMTP_PORT", "587"),
+		SMTPUsername:    os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:    os.Getenv("SMTP_PASSWORD"),
+		From:            getenv("MAIL_FROM", "no-reply@tesodev-korpes.local"),
+		OutboxFile:      os.Getenv("NOTIFIER_OUTBOX_FILE"),
+		VerificationURL: getenv("EMAIL_VERIFICATION_URL", "http://localhost:8001/customer/verification/confirm"),
+	}
+}
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"strings"
+	"tesodev-korpes/CustomerService/config"
+	"tesodev-korpes/pkg/customError"
+	"time"
+)
+
+// Unlock clears the failed logins of the customer's account. Locks on client
+// IPs are left alone.
+func (s *Service) Unlock(ctx context.Context, id string) error {
+	customer, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.attemptRepo.Reset(ctx, accountAttemptKey(customer.Email))
+}
+
+// Deactivate disables the account and ends all of its sessions. Access tokens
+// already issued are denied, so this service refuses them at once and the
+// others once their revocation caches expire.
+func (s *Service) Deactivate(ctx context.Context, id string) error {
+	if err := s.repo.SetActive(ctx, id, false); err != nil {
+		return err
+	}
+	s.statuses.Invalidate(id)
+
+	now := time.Now()
+	if err := s.tokenRepo.RevokeCustomer(ctx, id, now); err != nil {
+		return err
+	}
+	return s.revokeAccessTokens(ctx, id, now)
+}
+
+// Activate enables the account again and clears its failed logins.
+func (s *Service) Activate(ctx context.Context, id string) error {
+	if err := s.repo.SetActive(ctx, id, true); err != nil {
+		return err
+	}
+	s.statuses.Invalidate(id)
+	return s.Unlock(ctx, id)
+}
+
+// checkLockout refuses a login while the account or the IP is locked.
+func (s *Service) checkLockout(ctx context.Context, email, ip string) error {
+	attempts, err := s.attemptRepo.Get(ctx, accountAttemptKey(email), ipAttemptKey(ip))
+	if err != nil {
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	for _, attempt := range attempts {
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			wait = max(wait, attempt.LockedUntil.Sub(now))
+		}
+	}
+	if wait > 0 {
+		return customError.NewTooManyRequests(customError.AccountLocked, int(math.Ceil(wait.Seconds())))
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login against the account and the IP,
+// locking either once it reaches its threshold.
+func (s *Service) recordLoginFailure(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	counters := []struct {
+		key       string
+		threshold int
+	}{
+		{accountAttemptKey(email), config.LoginLockout.AccountThreshold},
+		{ipAttemptKey(ip), config.LoginLockout.IPThreshold},
+	}
+
+	for _, counter := range counters {
+		failures, err := s.attemptRepo.RecordFailure(ctx, counter.key, now)
+		if err != nil {
+			return err
+		}
+		if lock := lockDuration(failures, counter.threshold); lock > 0 {
+			if err := s.attemptRepo.Lock(ctx, counter.key, now.Add(lock)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lockDuration is BaseLock at the threshold, doubling with every failure
+// after it, capped at MaxLock.
+func lockDuration(failures, threshold int) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+	lock := config.LoginLockout.BaseLock
+	for i := threshold; i < failures && lock < config.LoginLockout.MaxLock; i++ {
+		lock *= 2
+	}
+	return min(lock, config.LoginLockout.MaxLock)
+}
+
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/customError"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Unlock godoc
+// @Summary Unlock a customer account
+// @Description Clear the failed login attempts of the account so it can log in again at once.
+// @Tags customers
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Customer ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Customer not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /customer/{id}/unlock [patch]
+func (h *Handler) Unlock(c echo.Context) error {
+	return h.changeAccount(c, h.service.Unlock)
+}
+
+// Deactivate godoc
+// @Summary Deactivate a customer account
+// @Description Refuse further logins of the account and end all of its sessions.
+// @Tags customers
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Customer ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Customer not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /customer/{id}/deactivate [patch]
+func (h *Handler) Deactivate(c echo.Context) error {
+	return h.changeAccount(c, h.service.Deactivate)
+}
+
+// Activate godoc
+// @Summary Activate a customer account
+// @Description Allow a deactivated account to log in again and clear its failed login attempts.
+// @Tags customers
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Customer ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Customer not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /customer/{id}/activate [patch]
+func (h *Handler) Activate(c echo.Context) error {
+	return h.changeAccount(c, h.service.Activate)
+}
+
+func (h *Handler) changeAccount(c echo.Context, change func(ctx context.Context, id string) error) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidCustomerID)
+	}
+
+	if err := change(c.Request().Context(), id); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return customError.NewNotFound(customError.CustomerNotFound)
+		}
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/CustomerService/config"
+	"tesodev-korpes/pkg/auth"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestLockDuration(t *testing.T) {
+	if config.LoginLockout.BaseLock != time.Minute || config.LoginLockout.MaxLock != time.Hour {
+		t.Fatalf("LoginLockout = %+v, the cases below assume a 1m base and a 1h cap", config.LoginLockout)
+	}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{7, 4 * time.Minute},
+		{10, 32 * time.Minute},
+		{11, time.Hour},
+		{12, time.Hour},
+		{1000, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := lockDuration(tt.failures, 5); got != tt.want {
+			t.Errorf("lockDuration(%d, 5) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestAttemptKeys(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{accountAttemptKey("jane@example.com"), "account:jane@example.com"},
+		{accountAttemptKey("  Jane@Example.COM "), "account:jane@example.com"},
+		{ipAttemptKey("192.0.2.1"), "ip:192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("key = %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestDeactivateRevokesAccessTokens(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("live access tokens are denied", func(mt *mtest.T) {
+		expiresAt := time.Now().Add(30 * time.Minute).Truncate(time.Millisecond)
+		updated := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1})
+		mt.AddMockResponses(
+			updated, // is_active
+			updated, // refresh tokens
+			mtest.CreateCursorResponse(0, "tesodev.refresh_token", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: "hash-1"}, {Key: "access_token_id", Value: "jti-1"}, {Key: "access_expires_at", Value: expiresAt}},
+				bson.D{{Key: "_id", Value: "hash-2"}, {Key: "access_token_id", Value: "jti-2"}, {Key: "access_expires_at", Value: expiresAt}},
+			),
+			updated, updated, // denylist
+		)
+
+		denylist := auth.NewDenylist(mt.Coll, time.Minute)
+		service := &Service{
+			repo:      NewRepository(mt.Coll),
+			tokenRepo: NewRefreshTokenRepository(mt.Coll),
+			denylist:  denylist,
+			statuses:  auth.NewUserStatusCache(mt.Coll, time.Minute),
+		}
+		if err := service.Deactivate(context.Background(), "customer-1"); err != nil {
+			mt.Fatalf("Deactivate error = %v", err)
+		}
+
+		lookup := mt.GetAllStartedEvents()[2].Command
+		if gt := lookup.Lookup("filter", "access_expires_at", "$gt"); gt.Type == 0 {
+			mt.Fatalf("access tokens looked up with %s, want only the live ones", lookup.Lookup("filter"))
+		}
+		for _, jti := range []string{"jti-1", "jti-2"} {
+			if revoked, err := denylist.IsRevoked(context.Background(), jti); !revoked || err != nil {
+				mt.Fatalf("IsRevoked(%s) = %v, %v, want true", jti, revoked, err)
+			}
+		}
+		if started := len(mt.GetAllStartedEvents()); started != 5 {
+			mt.Fatalf("%d commands, want 5", started)
+		}
+	})
+}
@@ -3,14 +3,15 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"tesodev-korpes/pkg/auth"
 	"tesodev-korpes/pkg/customError"
 	"tesodev-korpes/pkg/middleware"
 	"tesodev-korpes/shared/config"
 
 	"net/http"
-	"strconv"
 	"tesodev-korpes/CustomerService/internal/types"
 	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/pagination"
 
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -27,28 +28,50 @@ type Handler struct {
 	service *Service
 }
 
-func NewHandler(e *echo.Echo, service *Service, mongoClient *mongo.Client) {
+func NewHandler(e *echo.Echo, service *Service) {
 	handler := &Handler{
 		service: service,
 	}
 
-	e.Use(middleware.Authentication(mongoClient, pkg.Skipper))
+	// Failed logins are counted per client IP, so the IP is the address of
+	// the connection: X-Forwarded-For and X-Real-IP are set by the client,
+	// which could then start a fresh counter with every attempt.
+	e.IPExtractor = echo.ExtractIPDirect()
+
+	e.Use(middleware.Authentication(service.denylist, service.statuses, middleware.PublicRoute(&config.Cfg)))
+	e.Use(middleware.AuthorizationMiddleware(&config.Cfg))
+
+	e.GET("/.well-known/jwks.json", handler.JWKS)
 
 	g := e.Group("/customer")
+	g.Use(
+		middleware.Ownership("id", middleware.SelfOwned),
+		middleware.Ownership("email", service.EmailOwner),
+	)
 	g.POST("/create", handler.Create)
 	g.POST("/login", handler.Login)
+	g.POST("/refresh", handler.Refresh)
+	g.POST("/logout", handler.Logout)
+	g.POST("/password/forgot", handler.ForgotPassword)
+	g.POST("/password/reset", handler.ResetPassword)
+	g.POST("/password/change", handler.ChangePassword)
+	g.GET("/verification/confirm", handler.ConfirmVerification)
+	g.POST("/verification/resend", handler.ResendVerification)
 
 	g.GET("/:id", handler.GetByID)
-	g.GET("/email/:email", handler.GetByEmail, middleware.AuthorizationMiddleware(&config.Cfg))
+	g.GET("/email/:email", handler.GetByEmail)
 	g.PUT("/:id", handler.Update)
 	g.DELETE("/:id", handler.Delete)
 	g.GET("/list", handler.GetListCustomer)
 	g.GET("/verify", handler.VerifyAuthentication)
+	g.PATCH("/:id/unlock", handler.Unlock)
+	g.PATCH("/:id/deactivate", handler.Deactivate)
+	g.PATCH("/:id/activate", handler.Activate)
 }
 
 // Login godoc
 // @Summary User login
-// @Description Authenticate user with email and password, return access token and user info.
+// @Description Authenticate user with email and password, return access and refresh tokens and user info.
 // @Tags authentication
 // @Accept json
 // @Produce json
@@ -56,7 +79,9 @@ func NewHandler(e *echo.Echo, service *Service, mongoClient *mongo.Client) {
 // @Success 200 {object} types.LoginResponse "Returns access token and customer info"
 // @Failure 400 {object} errorPackage.AppError "Invalid request payload"
 // @Failure 401 {object} errorPackage.AppError "Unauthorized, invalid credentials"
+// @Failure 403 {object} errorPackage.AppError "Account deactivated"
 // @Failure 422 {object} errorPackage.AppError "Validation error on input data"
+// @Failure 429 {object} errorPackage.AppError "Too many failed attempts for the account or IP"
 // @Failure 500 {object} errorPackage.AppError "Internal server error"
 // @Router /customer/login [post]
 func (h *Handler) Login(c echo.Context) error {
@@ -71,15 +96,27 @@ func (h *Handler) Login(c echo.Context) error {
 		return err
 	}
 
-	token, customer, err := h.service.Login(c.Request().Context(), req.Email, req.Password, correlationID)
+	tokens, customer, err := h.service.Login(c.Request().Context(), req.Email, req.Password, c.RealIP(), correlationID)
 	if err != nil {
 		return err
 	}
 
-	response := ToLoginResponse(token, customer)
+	response := ToLoginResponse(tokens, customer)
 	return c.JSON(http.StatusOK, response)
 }
 
+// JWKS godoc
+// @Summary Public keys for verifying tokens
+// @Description Returns the keys, by kid, that access tokens are signed with (RFC 7517). Services verifying tokens can fetch them instead of sharing a secret.
+// @Tags authentication
+// @Produce json
+// @Success 200 {object} auth.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) JWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, auth.JWKS())
+}
+
 // VerifyAuthentication godoc
 // @Summary Verify user authentication
 // @Description Verify the authentication token and return user details if valid.
@@ -176,7 +213,7 @@ func (h *Handler) GetByID(c echo.Context) error {
 
 // Create godoc
 // @Summary Create a new customer
-// @Description Create a new customer with the given data
+// @Description Create a new customer with the given data. The customer starts pending email verification and is sent a verification link.
 // @Tags customers
 // @Accept json
 // @Produce json
@@ -204,7 +241,8 @@ func (h *Handler) Create(c echo.Context) error {
 	}
 
 	// service.Create ham (raw) error döndürür. Tanımadığımız için Internal olarak sarmalıyoruz.
-	createdID, err := h.service.Create(c.Request().Context(), &req)
+	correlationID, _ := c.Get("CorrelationID").(string)
+	createdID, err := h.service.Create(c.Request().Context(), &req, correlationID)
 	if err != nil {
 		return customError.NewInternal(customError.CustomerServiceError, err)
 	}
@@ -227,6 +265,8 @@ func (h *Handler) Create(c echo.Context) error {
 // @Success 200 {object} types.CustomerResponseModel
 // @Failure 400 {object} errorPackage.AppError "Invalid ID format or request body"
 // @Failure 404 {object} errorPackage.AppError "Customer not found"
+// @Failure 409 {object} errorPackage.AppError "Customer was modified concurrently"
+// @Failure 422 {object} errorPackage.AppError "Password given; use the password endpoints"
 // @Failure 500 {object} errorPackage.AppError "Internal server error"
 // @Router /customer/{id} [put]
 func (h *Handler) Update(c echo.Context) error {
@@ -239,6 +279,9 @@ func (h *Handler) Update(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return customError.NewBadRequest(customError.InvalidCustomerBody)
 	}
+	if req.Password != "" {
+		return customError.NewUnprocessableEntity(customError.PasswordChangeNotAllowed, nil)
+	}
 
 	existing, err := h.service.GetByID(c.Request().Context(), id)
 	if err != nil {
@@ -250,6 +293,15 @@ func (h *Handler) Update(c echo.Context) error {
 	updatedCustomer := FromUpdateCustomerRequest(existingCustomer, &req)
 
 	if err := h.service.Update(c.Request().Context(), id, updatedCustomer); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return customError.NewNotFound(customError.CustomerNotFound)
+		}
+
+		var appErr *customError.AppError
+		if errors.As(err, &appErr) {
+			return err
+		}
+
 		return customError.NewInternal(customError.CustomerServiceError, err)
 	}
 	response := ToCustomerResponse(updatedCustomer)
@@ -287,43 +339,32 @@ func (h *Handler) Delete(c echo.Context) error {
 
 // GetListCustomer godoc
 // @Summary List customers with pagination
-// @Description Retrieve a paginated list of customers
+// @Description Retrieve customers newest first, one page at a time
 // @Tags customers
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Number of items per page"
-// @Success 200 {object} map[string]interface{} "Returns list of customers"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Number of items per page (at most 100)" default(10)
+// @Param total query bool false "Also count all customers"
+// @Success 200 {object} map[string]interface{} "Returns data, next_cursor and, if asked for, total"
+// @Failure 400 {object} errorPackage.AppError "Invalid cursor"
 // @Failure 500 {object} errorPackage.AppError "Internal server error"
 // @Router /customer/list [get]
 func (h *Handler) GetListCustomer(c echo.Context) error {
-	params := types.Pagination{
-		Limit: 10,
-		Page:  1,
-	}
-
-	if p := c.QueryParam("page"); p != "" {
-		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
-			params.Page = pageInt
-		}
-	}
-
-	if l := c.QueryParam("limit"); l != "" {
-		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 {
-			params.Limit = limitInt
-		}
+	params, err := pagination.ParseParams(c.QueryParam("limit"), c.QueryParam("cursor"), c.QueryParam("total"))
+	if err != nil {
+		return err
 	}
 
-	customers, err := h.service.Get(c.Request().Context(), params)
+	page, err := h.service.Get(c.Request().Context(), params)
 	if err != nil {
-		// Listelemede kayıt bulunamaması bir hata değildir, boş liste dönülür.
-		// Ancak yine de mongo.ErrNoDocuments gelirse diye kontrol ediyoruz.
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return c.JSON(http.StatusOK, map[string]interface{}{"data": []types.CustomerResponseModel{}})
+		var appErr *customError.AppError
+		if errors.As(err, &appErr) {
+			return err
 		}
 		return customError.NewInternal(customError.CustomerServiceError, err)
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": customers})
+	return c.JSON(http.StatusOK, page)
 }
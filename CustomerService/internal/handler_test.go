@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tesodev-korpes/pkg/customError"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestLoginLockoutIgnoresForwardedFor(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("spoofed headers", func(mt *mtest.T) {
+		e := echo.New()
+		service := &Service{repo: NewRepository(mt.Coll), attemptRepo: NewLoginAttemptRepository(mt.Coll)}
+		NewHandler(e, service)
+		handler := &Handler{service: service}
+
+		locked := mtest.CreateCursorResponse(0, "tesodev.login_attempt", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "ip:203.0.113.7"},
+			{Key: "failures", Value: 20},
+			{Key: "locked_until", Value: time.Now().Add(time.Hour)},
+		})
+
+		for _, spoofed := range []string{"198.51.100.1", "198.51.100.2"} {
+			mt.ClearEvents()
+			mt.AddMockResponses(locked)
+
+			req := httptest.NewRequest(http.MethodPost, "/customer/login", strings.NewReader(`{"email":"jane@example.com","password":"secret-password"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderXForwardedFor, spoofed)
+			req.Header.Set(echo.HeaderXRealIP, spoofed)
+			req.RemoteAddr = "203.0.113.7:51234"
+
+			err := handler.Login(e.NewContext(req, httptest.NewRecorder()))
+			var appErr *customError.AppError
+			if !errors.As(err, &appErr) || appErr.Code != customError.ErrorDefinitions[customError.AccountLocked].TypeCode {
+				mt.Fatalf("Login with X-Forwarded-For %s error = %v, want AccountLocked", spoofed, err)
+			}
+
+			keys := mt.GetStartedEvent().Command.Lookup("filter", "_id", "$in").Array().String()
+			if !strings.Contains(keys, `"ip:203.0.113.7"`) || strings.Contains(keys, spoofed) {
+				mt.Fatalf("lockout looked up %s, want the connection's address only", keys)
+			}
+		}
+	})
+}
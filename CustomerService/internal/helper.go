@@ -17,16 +17,18 @@ func ToCustomerResponse(customer *types.Customer) *types.CustomerResponseModel {
 		return nil
 	}
 	return &types.CustomerResponseModel{
-		ID:        customer.Id,
-		FirstName: customer.FirstName,
-		LastName:  customer.LastName,
-		Email:     customer.Email,
-		Phone:     customer.Phone,
-		Address:   customer.Address,
-		IsActive:  customer.IsActive,
-		CreatedAt: customer.CreatedAt,
-		UpdatedAt: customer.UpdatedAt,
-		Role:      customer.Role,
+		ID:            customer.Id,
+		FirstName:     customer.FirstName,
+		LastName:      customer.LastName,
+		Email:         customer.Email,
+		Phone:         customer.Phone,
+		Address:       customer.Address,
+		IsActive:      customer.IsActive,
+		EmailVerified: !customer.VerificationPending,
+		CreatedAt:     customer.CreatedAt,
+		UpdatedAt:     customer.UpdatedAt,
+		Role:          customer.Role,
+		Version:       customer.Version,
 	}
 }
 func FromCreateCustomerRequest(req *types.CreateCustomerRequestModel) *types.Customer {
@@ -64,7 +66,8 @@ func FromCreateCustomerRequest(req *types.CreateCustomerRequestModel) *types.Cus
 			SystemRole: config.RoleStatus.System.User,
 			Membership: config.RoleStatus.Membership.NonPremium,
 		},
-		IsActive: true,
+		IsActive:            true,
+		VerificationPending: true,
 	}
 
 }
@@ -97,7 +100,9 @@ func FromUpdateCustomerRequest(customer *types.Customer, req *types.UpdateCustom
 		customer.Address = req.Address
 	}
 
-	customer.IsActive = req.IsActive
+	if req.Version != nil {
+		customer.Version = *req.Version
+	}
 	return customer
 }
 
@@ -106,16 +111,18 @@ func FromCustomerResponse(resp *types.CustomerResponseModel) *types.Customer {
 		return nil
 	}
 	return &types.Customer{
-		Id:        resp.ID,
-		FirstName: resp.FirstName,
-		LastName:  resp.LastName,
-		Email:     resp.Email,
-		Phone:     resp.Phone,
-		Address:   resp.Address,
-		IsActive:  resp.IsActive,
-		CreatedAt: resp.CreatedAt,
-		UpdatedAt: resp.UpdatedAt,
-		Role:      resp.Role,
+		Id:                  resp.ID,
+		FirstName:           resp.FirstName,
+		LastName:            resp.LastName,
+		Email:               resp.Email,
+		Phone:               resp.Phone,
+		Address:             resp.Address,
+		IsActive:            resp.IsActive,
+		VerificationPending: !resp.EmailVerified,
+		CreatedAt:           resp.CreatedAt,
+		UpdatedAt:           resp.UpdatedAt,
+		Role:                resp.Role,
+		Version:             resp.Version,
 	}
 }
 
@@ -132,10 +139,11 @@ func ToVerifyTokenResponse(user *types.CustomerResponseModel) types.VerifyTokenR
 		User:    ToVerifiedUserFromResponse(user),
 	}
 }
-func ToLoginResponse(token string, customer *types.Customer) types.LoginResponse {
+func ToLoginResponse(tokens *types.TokenResponse, customer *types.Customer) types.LoginResponse {
 	return types.LoginResponse{
-		Token:   token,
-		User:    ToCustomerResponse(customer),
-		Message: "Login successful",
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		User:         ToCustomerResponse(customer),
+		Message:      "Login successful",
 	}
 }
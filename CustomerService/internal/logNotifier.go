@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+)
+
+// LogNotifier is a Notifier for local development. It appends every
+// notification as a JSON line to a file, or writes it to the log when no file
+// is set. Messages carry secrets such as reset tokens, so it must not be used
+// in production.
+type LogNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewLogNotifier(path string) *LogNotifier {
+	return &LogNotifier{path: path}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, notification Notification) error {
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	if n.path == "" {
+		log.Printf("notification: %s", line)
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
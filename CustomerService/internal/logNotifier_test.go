@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLogNotifierAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	notifier := NewLogNotifier(path)
+
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	sent := []Notification{
+		{CustomerId: "c1", To: "jane@example.com", Subject: "Password reset", Body: "token one", CreatedAt: at},
+		{CustomerId: "c1", To: "jane@example.com", Subject: "Password reset", Body: "token two", CreatedAt: at.Add(time.Minute)},
+	}
+	for _, notification := range sent {
+		if err := notifier.Notify(context.Background(), notification); err != nil {
+			t.Fatalf("Notify error = %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var got []Notification
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var notification Notification
+		if err := json.Unmarshal(scanner.Bytes(), &notification); err != nil {
+			t.Fatalf("line %q is not a notification: %v", scanner.Text(), err)
+		}
+		got = append(got, notification)
+	}
+	if !reflect.DeepEqual(got, sent) {
+		t.Fatalf("file holds %+v, want %+v", got, sent)
+	}
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/CustomerService/internal/types"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LoginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+func NewLoginAttemptRepository(col *mongo.Collection) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		collection: col,
+	}
+}
+
+// EnsureIndexes creates the TTL index that forgets counters window after the
+// last failure.
+func (r *LoginAttemptRepository) EnsureIndexes(ctx context.Context, window time.Duration) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "updated_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(window.Seconds())),
+	})
+	return err
+}
+
+// Get returns the attempts recorded under the keys that exist.
+func (r *LoginAttemptRepository) Get(ctx context.Context, keys ...string) ([]types.LoginAttempt, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": keys}})
+	if err != nil {
+		return nil, err
+	}
+	var attempts []types.LoginAttempt
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// RecordFailure counts one more failure under key and returns the new count.
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, now time.Time) (int, error) {
+	update := bson.M{
+		"$inc": bson.M{"failures": 1},
+		"$set": bson.M{"updated_at": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempt types.LoginAttempt
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&attempt); err != nil {
+		return 0, err
+	}
+	return attempt.Failures, nil
+}
+
+// Lock locks key until the given time.
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$set": bson.M{"locked_until": until}})
+	return err
+}
+
+// Reset forgets the failures recorded under key.
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
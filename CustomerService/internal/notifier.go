@@ -0,0 +1,21 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// Notifier delivers messages to customers, e.g. password reset tokens and
+// email verification links. A returned error means the message could not be
+// handed over for delivery.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+type Notification struct {
+	CustomerId string    `json:"customer_id"`
+	To         string    `json:"to"`
+	Subject    string    `json:"subject"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
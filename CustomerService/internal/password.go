@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tesodev-korpes/CustomerService/config"
+	"tesodev-korpes/CustomerService/internal/types"
+	"tesodev-korpes/pkg/auth"
+	"tesodev-korpes/pkg/customError"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ForgotPassword sends the customer a single-use reset token valid for
+// config.PasswordResetTTL and drops any older one. Unknown and deactivated
+// accounts are ignored silently so the endpoint does not reveal which emails
+// are registered.
+func (s *Service) ForgotPassword(ctx context.Context, email, correlationID string) error {
+	customer, err := s.repo.GetByEmail(ctx, email)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	if !customer.IsActive {
+		return nil
+	}
+
+	if err := s.resetRepo.DeleteByCustomer(ctx, customer.Id); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	now := time.Now()
+	err = s.resetRepo.Create(ctx, &types.PasswordReset{
+		Hash:       hash,
+		CustomerId: customer.Id,
+		ExpiresAt:  now.Add(config.PasswordResetTTL),
+		CreatedAt:  now,
+	})
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+
+	// A failed delivery is logged only; answering differently would tell
+	// the caller that the email is registered.
+	err = s.notifier.Notify(ctx, Notification{
+		CustomerId: customer.Id,
+		To:         customer.Email,
+		Subject:    "Password reset",
+		Body: fmt.Sprintf("Use this token to reset your password within %d minutes: %s",
+			int(config.PasswordResetTTL.Minutes()), token),
+		CreatedAt: now,
+	})
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+	}
+	return nil
+}
+
+// ResetPassword redeems a reset token and sets the new password. Every
+// session of the customer is ended and the account's failed logins are
+// cleared.
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword, correlationID string) error {
+	reset, err := s.resetRepo.Consume(ctx, auth.HashOpaqueToken(token), time.Now())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return customError.NewBadRequest(customError.InvalidPasswordResetToken)
+	}
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+
+	customer, err := s.repo.GetByID(ctx, reset.CustomerId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return customError.NewBadRequest(customError.InvalidPasswordResetToken)
+	}
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+
+	if err := s.setPassword(ctx, customer.Id, newPassword); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	if err := s.tokenRepo.RevokeCustomer(ctx, customer.Id, time.Now()); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	if err := s.attemptRepo.Reset(ctx, accountAttemptKey(customer.Email)); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+	}
+	return nil
+}
+
+// ChangePassword sets a new password after checking the current one. Wrong
+// current passwords count as failed logins, so guessing them runs into the
+// same lockout. Sessions other than the caller's are ended.
+func (s *Service) ChangePassword(ctx context.Context, customerID, sessionID, currentPassword, newPassword, ip, correlationID string) error {
+	customer, err := s.repo.GetByID(ctx, customerID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return customError.NewNotFound(customError.CustomerNotFound)
+	}
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+
+	if err := s.checkLockout(ctx, customer.Email, ip); err != nil {
+		return err
+	}
+
+	valid, err := auth.VerifyPassword(currentPassword, customer.Password)
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	if !valid {
+		if err := s.recordLoginFailure(ctx, customer.Email, ip); err != nil {
+			customError.LogErrorWithCorrelation(err, correlationID)
+		}
+		return customError.NewUnauthorized(customError.InvalidCurrentPassword)
+	}
+
+	if err := s.setPassword(ctx, customer.Id, newPassword); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	if err := s.tokenRepo.RevokeOtherFamilies(ctx, customer.Id, sessionID, time.Now()); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	if err := s.attemptRepo.Reset(ctx, accountAttemptKey(customer.Email)); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+	}
+	return nil
+}
+
+func (s *Service) setPassword(ctx context.Context, customerID, password string) error {
+	hashedPwd, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdatePassword(ctx, customerID, string(hashedPwd))
+}
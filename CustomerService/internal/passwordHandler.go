@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"net/http"
+	"tesodev-korpes/CustomerService/internal/types"
+	"tesodev-korpes/pkg/customError"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Send a single-use password reset token to the customer with the email. The answer is the same whether or not the email is registered.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param forgotPasswordRequest body types.ForgotPasswordRequestModel true "Email of the account"
+// @Success 202 "Accepted"
+// @Failure 400 {object} errorPackage.AppError "Invalid request payload"
+// @Failure 422 {object} errorPackage.AppError "Invalid email format"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /customer/password/forgot [post]
+func (h *Handler) ForgotPassword(c echo.Context) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+
+	var req types.ForgotPasswordRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidCustomerBody)
+	}
+
+	if err := req.ForgotValidate(); err != nil {
+		return err
+	}
+
+	if err := h.service.ForgotPassword(c.Request().Context(), req.Email, correlationID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+// ResetPassword godoc
+// @Summary Reset the password
+// @Description Set a new password with a reset token. The token can be used once; all sessions of the customer are ended.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param resetPasswordRequest body types.ResetPasswordRequestModel true "Reset token and new password"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorPackage.AppError "Invalid request payload or invalid, expired or used token"
+// @Failure 422 {object} errorPackage.AppError "Invalid password format"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /customer/password/reset [post]
+func (h *Handler) ResetPassword(c echo.Context) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+
+	var req types.ResetPasswordRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidCustomerBody)
+	}
+
+	if err := req.ResetValidate(); err != nil {
+		return err
+	}
+
+	if err := h.service.ResetPassword(c.Request().Context(), req.Token, req.NewPassword, correlationID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ChangePassword godoc
+// @Summary Change the password
+// @Description Set a new password after confirming the current one. Sessions other than the caller's are ended.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param changePasswordRequest body types.ChangePasswordRequestModel true "Current and new password"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorPackage.AppError "Invalid request payload"
+// @Failure 401 {object} errorPackage.AppError "Unauthorized or wrong current password"
+// @Failure 422 {object} errorPackage.AppError "Invalid password format"
+// @Failure 429 {object} errorPackage.AppError "Too many failed attempts for the account or IP"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /customer/password/change [post]
+func (h *Handler) ChangePassword(c echo.Context) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+	userID, _ := c.Get("userId").(string)
+	sessionID, _ := c.Get("sessionId").(string)
+	if userID == "" {
+		return customError.NewUnauthorized(customError.MissingAuthToken)
+	}
+
+	var req types.ChangePasswordRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidCustomerBody)
+	}
+
+	if err := req.ChangeValidate(); err != nil {
+		return err
+	}
+
+	err := h.service.ChangePassword(c.Request().Context(), userID, sessionID, req.CurrentPassword, req.NewPassword, c.RealIP(), correlationID)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/CustomerService/internal/types"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PasswordResetRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPasswordResetRepository(col *mongo.Collection) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		collection: col,
+	}
+}
+
+// EnsureIndexes creates the TTL index that drops expired reset tokens and the
+// index used to drop the tokens of a customer.
+func (r *PasswordResetRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{Keys: bson.D{{Key: "customer_id", Value: 1}}},
+	})
+	return err
+}
+
+func (r *PasswordResetRepository) Create(ctx context.Context, reset *types.PasswordReset) error {
+	_, err := r.collection.InsertOne(ctx, reset)
+	return err
+}
+
+// DeleteByCustomer drops every reset token of the customer, so only the
+// latest one can be used.
+func (r *PasswordResetRepository) DeleteByCustomer(ctx context.Context, customerID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"customer_id": customerID})
+	return err
+}
+
+// Consume marks the token used if it is unused and not expired, so it can be
+// redeemed only once. It returns mongo.ErrNoDocuments otherwise.
+func (r *PasswordResetRepository) Consume(ctx context.Context, hash string, now time.Time) (*types.PasswordReset, error) {
+	filter := bson.M{
+		"_id":        hash,
+		"used_at":    nil,
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"used_at": now}}
+
+	var reset types.PasswordReset
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update).Decode(&reset); err != nil {
+		return nil, err
+	}
+	return &reset, nil
+}
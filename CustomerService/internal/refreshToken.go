@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"tesodev-korpes/CustomerService/config"
+	"tesodev-korpes/CustomerService/internal/types"
+	"tesodev-korpes/pkg/auth"
+	"tesodev-korpes/pkg/customError"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Refresh trades a refresh token for a new access token and a new refresh
+// token of the same family; the presented token is spent. The new access
+// token carries the customer's current role and membership. Presenting a
+// token that was already spent means it leaked, so the whole family is
+// revoked and the session has to log in again.
+func (s *Service) Refresh(ctx context.Context, refreshToken, correlationID string) (*types.TokenResponse, error) {
+	now := time.Now()
+	hash := auth.HashOpaqueToken(refreshToken)
+
+	stored, err := s.tokenRepo.Consume(ctx, hash, now)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if err := s.revokeReusedFamily(ctx, hash, now); err != nil {
+			customError.LogErrorWithCorrelation(err, correlationID)
+			return nil, customError.NewInternal(customError.CustomerServiceError, err)
+		}
+		return nil, customError.NewUnauthorized(customError.InvalidRefreshToken)
+	}
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return nil, customError.NewInternal(customError.CustomerServiceError, err)
+	}
+
+	customer, err := s.repo.GetByID(ctx, stored.CustomerId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, customError.NewUnauthorized(customError.InvalidRefreshToken)
+	}
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return nil, customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	if !customer.IsActive {
+		return nil, customError.NewUnauthorized(customError.InvalidRefreshToken)
+	}
+
+	tokens, err := s.issueTokens(ctx, customer, stored.FamilyId)
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return nil, customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	return tokens, nil
+}
+
+// Logout ends the session of the refresh token, or every session of the
+// customer when none is given, and revokes the access token in use.
+func (s *Service) Logout(ctx context.Context, customerID, refreshToken, tokenID string, tokenExpiresAt time.Time, correlationID string) error {
+	now := time.Now()
+
+	if refreshToken != "" {
+		stored, err := s.tokenRepo.GetByHash(ctx, auth.HashOpaqueToken(refreshToken))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return customError.NewUnauthorized(customError.InvalidRefreshToken)
+		}
+		if err != nil {
+			customError.LogErrorWithCorrelation(err, correlationID)
+			return customError.NewInternal(customError.CustomerServiceError, err)
+		}
+		if stored.CustomerId != customerID {
+			return customError.NewUnauthorized(customError.InvalidRefreshToken)
+		}
+		if err := s.tokenRepo.RevokeFamily(ctx, stored.FamilyId, now); err != nil {
+			customError.LogErrorWithCorrelation(err, correlationID)
+			return customError.NewInternal(customError.CustomerServiceError, err)
+		}
+	} else if err := s.tokenRepo.RevokeCustomer(ctx, customerID, now); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+
+	if err := s.denylist.Revoke(ctx, tokenID, tokenExpiresAt); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	return nil
+}
+
+// issueTokens signs an access token and stores a new refresh token in the
+// given family. The family id doubles as the session id of the token.
+func (s *Service) issueTokens(ctx context.Context, customer *types.Customer, familyID string) (*types.TokenResponse, error) {
+	accessToken, claims, err := auth.IssueJWT(auth.Identity{
+		Id:         customer.Id,
+		Role:       customer.Role.SystemRole,
+		Membership: customer.Role.Membership,
+		SessionId:  familyID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	err = s.tokenRepo.Create(ctx, &types.RefreshToken{
+		Hash:            hash,
+		CustomerId:      customer.Id,
+		FamilyId:        familyID,
+		AccessTokenId:   claims.RegisteredClaims.ID,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		ExpiresAt:       now.Add(config.RefreshTokenTTL),
+		CreatedAt:       now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.TokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// revokeAccessTokens denies every access token of the customer that has not
+// expired yet. Other services see a denied jti within their revocation cache
+// ttl, far sooner than a status change through their status caches.
+func (s *Service) revokeAccessTokens(ctx context.Context, customerID string, now time.Time) error {
+	tokens, err := s.tokenRepo.LiveAccessTokens(ctx, customerID, now)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if err := s.denylist.Revoke(ctx, token.AccessTokenId, token.AccessExpiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeReusedFamily revokes the family of a refresh token that was spent
+// already. Unknown and expired tokens are left alone.
+func (s *Service) revokeReusedFamily(ctx context.Context, hash string, now time.Time) error {
+	stored, err := s.tokenRepo.GetByHash(ctx, hash)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if stored.RevokedAt == nil {
+		return nil
+	}
+	return s.tokenRepo.RevokeFamily(ctx, stored.FamilyId, now)
+}
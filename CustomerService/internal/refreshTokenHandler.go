@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"net/http"
+	"tesodev-korpes/CustomerService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Refresh godoc
+// @Summary Refresh the access token
+// @Description Trade a refresh token for a new access token and a new refresh token. The presented refresh token can not be used again; reusing it ends the session.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param refreshRequest body types.RefreshRequestModel true "Refresh token"
+// @Success 200 {object} types.TokenResponse
+// @Failure 400 {object} errorPackage.AppError "Invalid request payload"
+// @Failure 401 {object} errorPackage.AppError "Invalid, expired or revoked refresh token"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /customer/refresh [post]
+func (h *Handler) Refresh(c echo.Context) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+
+	var req types.RefreshRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidCustomerBody)
+	}
+
+	if err := req.RefreshValidate(); err != nil {
+		return err
+	}
+
+	tokens, err := h.service.Refresh(c.Request().Context(), req.RefreshToken, correlationID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revoke the access token in use and end the session of the given refresh token, or every session of the caller when no refresh token is given.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param logoutRequest body types.LogoutRequestModel false "Refresh token of the session to end"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorPackage.AppError "Invalid request payload"
+// @Failure 401 {object} errorPackage.AppError "Unauthorized or unknown refresh token"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /customer/logout [post]
+func (h *Handler) Logout(c echo.Context) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+	userID, _ := c.Get("userId").(string)
+	tokenID, _ := c.Get("tokenId").(string)
+	tokenExpiresAt, _ := c.Get("tokenExpiresAt").(time.Time)
+	if userID == "" || tokenID == "" {
+		return customError.NewUnauthorized(customError.MissingAuthToken)
+	}
+
+	var req types.LogoutRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidCustomerBody)
+	}
+
+	if err := h.service.Logout(c.Request().Context(), userID, req.RefreshToken, tokenID, tokenExpiresAt, correlationID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/CustomerService/internal/types"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RefreshTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRefreshTokenRepository(col *mongo.Collection) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		collection: col,
+	}
+}
+
+// EnsureIndexes creates the TTL index that drops expired refresh tokens and
+// the indexes used to revoke tokens by family and by customer.
+func (r *RefreshTokenRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{Keys: bson.D{{Key: "family_id", Value: 1}}},
+		{Keys: bson.D{{Key: "customer_id", Value: 1}}},
+	})
+	return err
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *types.RefreshToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*types.RefreshToken, error) {
+	var token types.RefreshToken
+	if err := r.collection.FindOne(ctx, bson.M{"_id": hash}).Decode(&token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Consume revokes the token if it is still live and returns it as it was, so
+// of two concurrent refreshes with the same token only one succeeds. It
+// returns mongo.ErrNoDocuments for unknown, expired or revoked tokens.
+func (r *RefreshTokenRepository) Consume(ctx context.Context, hash string, now time.Time) (*types.RefreshToken, error) {
+	filter := bson.M{
+		"_id":        hash,
+		"revoked_at": nil,
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"revoked_at": now}}
+
+	var token types.RefreshToken
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update).Decode(&token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeFamily revokes every live token rotated from the same login.
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, now time.Time) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"family_id": familyID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": now}},
+	)
+	return err
+}
+
+// RevokeCustomer revokes every live token of the customer.
+func (r *RefreshTokenRepository) RevokeCustomer(ctx context.Context, customerID string, now time.Time) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"customer_id": customerID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": now}},
+	)
+	return err
+}
+
+// LiveAccessTokens returns the tokens of the customer whose access token has
+// not expired yet, spent and revoked ones included.
+func (r *RefreshTokenRepository) LiveAccessTokens(ctx context.Context, customerID string, now time.Time) ([]types.RefreshToken, error) {
+	cursor, err := r.collection.Find(ctx,
+		bson.M{"customer_id": customerID, "access_expires_at": bson.M{"$gt": now}},
+		options.Find().SetProjection(bson.M{"access_token_id": 1, "access_expires_at": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tokens []types.RefreshToken
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RevokeOtherFamilies revokes every live token of the customer except those
+// of the given family, ending all other sessions.
+func (r *RefreshTokenRepository) RevokeOtherFamilies(ctx context.Context, customerID, familyID string, now time.Time) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"customer_id": customerID, "family_id": bson.M{"$ne": familyID}, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": now}},
+	)
+	return err
+}
@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/CustomerService/internal/types"
+	"tesodev-korpes/pkg/auth"
+	"tesodev-korpes/pkg/customError"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestRefresh(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	customer := func(active bool) bson.D {
+		return mtest.CreateCursorResponse(0, "tesodev.customer", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "customer-1"},
+			{Key: "role", Value: bson.D{{Key: "role", Value: "user"}, {Key: "membership", Value: "premium"}}},
+			{Key: "is_active", Value: active},
+		})
+	}
+
+	// seed stores a live token of the family and returns it.
+	seed := func(mt *mtest.T, store *memoryRefreshTokens, familyID string, expiresAt time.Time) string {
+		token, hash, err := auth.NewOpaqueToken()
+		if err != nil {
+			mt.Fatal(err)
+		}
+		store.tokens[hash] = &types.RefreshToken{Hash: hash, CustomerId: "customer-1", FamilyId: familyID, ExpiresAt: expiresAt}
+		return token
+	}
+	expectRefused := func(mt *mtest.T, err error) {
+		mt.Helper()
+		appErr, ok := err.(*customError.AppError)
+		if !ok || appErr.Code != customError.ErrorDefinitions[customError.InvalidRefreshToken].TypeCode {
+			mt.Fatalf("Refresh error = %v, want InvalidRefreshToken", err)
+		}
+	}
+	later := time.Now().Add(time.Hour)
+
+	mt.Run("rotates within the family", func(mt *mtest.T) {
+		store := newMemoryRefreshTokens()
+		first := seed(mt, store, "family-1", later)
+		mt.AddMockResponses(customer(true))
+		s := &Service{repo: NewRepository(mt.Coll), tokenRepo: store}
+
+		tokens, err := s.Refresh(context.Background(), first, "")
+		if err != nil {
+			mt.Fatalf("Refresh error = %v", err)
+		}
+		now := time.Now()
+		if store.live(auth.HashOpaqueToken(first), now) {
+			mt.Fatalf("presented token still live, want it spent")
+		}
+		next, ok := store.tokens[auth.HashOpaqueToken(tokens.RefreshToken)]
+		if !ok || next.FamilyId != "family-1" || !store.live(next.Hash, now) {
+			mt.Fatalf("new refresh token = %+v, want a live token of family-1", next)
+		}
+
+		claims, err := auth.VerifyJWT(tokens.Token)
+		if err != nil {
+			mt.Fatalf("VerifyJWT error = %v", err)
+		}
+		if claims.SessionID != "family-1" || claims.Membership != "premium" || claims.RegisteredClaims.ID != next.AccessTokenId {
+			mt.Fatalf("access token = %+v, want session family-1, the current membership and the jti stored with the refresh token", claims)
+		}
+	})
+
+	mt.Run("a spent token revokes its family", func(mt *mtest.T) {
+		store := newMemoryRefreshTokens()
+		first := seed(mt, store, "family-1", later)
+		other := seed(mt, store, "family-2", later)
+		mt.AddMockResponses(customer(true))
+		s := &Service{repo: NewRepository(mt.Coll), tokenRepo: store}
+
+		tokens, err := s.Refresh(context.Background(), first, "")
+		if err != nil {
+			mt.Fatalf("Refresh error = %v", err)
+		}
+		_, err = s.Refresh(context.Background(), first, "")
+		expectRefused(mt, err)
+
+		now := time.Now()
+		for _, hash := range store.family("family-1") {
+			if store.live(hash, now) {
+				mt.Fatalf("token %s of the reused family still live", hash)
+			}
+		}
+		if !store.live(auth.HashOpaqueToken(other), now) {
+			mt.Fatalf("token of another session revoked, want it left alone")
+		}
+		_, err = s.Refresh(context.Background(), tokens.RefreshToken, "")
+		expectRefused(mt, err)
+	})
+
+	mt.Run("unknown and expired tokens revoke nothing", func(mt *mtest.T) {
+		store := newMemoryRefreshTokens()
+		expired := seed(mt, store, "family-1", time.Now().Add(-time.Minute))
+		sibling := seed(mt, store, "family-1", later)
+		s := &Service{repo: NewRepository(mt.Coll), tokenRepo: store}
+
+		for _, token := range []string{"not-a-token", expired} {
+			_, err := s.Refresh(context.Background(), token, "")
+			expectRefused(mt, err)
+		}
+		if !store.live(auth.HashOpaqueToken(sibling), time.Now()) {
+			mt.Fatalf("family revoked for an unknown or expired token")
+		}
+	})
+
+	mt.Run("a deactivated customer gets no new token", func(mt *mtest.T) {
+		store := newMemoryRefreshTokens()
+		first := seed(mt, store, "family-1", later)
+		mt.AddMockResponses(customer(false))
+		s := &Service{repo: NewRepository(mt.Coll), tokenRepo: store}
+
+		_, err := s.Refresh(context.Background(), first, "")
+		expectRefused(mt, err)
+		if len(store.tokens) != 1 {
+			mt.Fatalf("%d refresh tokens stored, want no new one", len(store.tokens))
+		}
+	})
+}
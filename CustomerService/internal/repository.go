@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"tesodev-korpes/CustomerService/internal/types"
+	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/pagination"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -53,8 +56,13 @@ func (r *Repository) Create(ctx context.Context, customer *types.Customer) (stri
 	return customer.Id, nil
 }
 
+// Update writes the customer only if it is still at customer.Version, and bumps
+// the version, so concurrent updates cannot silently overwrite each other.
 func (r *Repository) Update(ctx context.Context, id string, customer *types.Customer) error {
-	filter := bson.M{"_id": id}
+	filter := bson.M{
+		"_id":     id,
+		"version": pkg.VersionFilter(customer.Version),
+	}
 	update := bson.M{
 		"$set": bson.M{
 			"first_name": customer.FirstName,
@@ -62,14 +70,31 @@ func (r *Repository) Update(ctx context.Context, id string, customer *types.Cust
 			"email":      customer.Email,
 			"phone":      customer.Phone,
 			"address":    customer.Address,
-			"password":   customer.Password,
-			"is_active":  customer.IsActive,
 			"updated_at": time.Now(),
 		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
 	}
 
-	_, err := r.collection.UpdateOne(ctx, filter, update)
-	return err
+	if res.MatchedCount == 0 {
+		count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return mongo.ErrNoDocuments
+		}
+		return customError.NewConflict(customError.CustomerVersionConflict)
+	}
+
+	customer.Version++
+	return nil
 }
 
 func (r *Repository) Delete(ctx context.Context, id string) error {
@@ -83,10 +108,20 @@ func (r *Repository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *Repository) Get(ctx context.Context, opt *options.FindOptions) ([]types.Customer, error) {
+// Get returns the customers, newest first, that follow the cursor of params,
+// plus one more so the caller can tell whether another page follows.
+func (r *Repository) Get(ctx context.Context, params pagination.Params) ([]types.Customer, error) {
 	var customers []types.Customer
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, opt)
+	filter, err := pagination.After(bson.M{}, "created_at", true, params.After)
+	if err != nil {
+		return nil, err
+	}
+	opt := options.Find().
+		SetSort(pagination.Sort("created_at", true)).
+		SetLimit(int64(params.Limit + 1))
+
+	cursor, err := r.collection.Find(ctx, filter, opt)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, fmt.Errorf("document not found")
@@ -101,3 +136,106 @@ func (r *Repository) Get(ctx context.Context, opt *options.FindOptions) ([]types
 
 	return customers, nil
 }
+
+func (r *Repository) Count(ctx context.Context) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{})
+}
+
+// UpdatePassword stores a new password hash and bumps the version.
+func (r *Repository) UpdatePassword(ctx context.Context, id string, hashedPassword string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"password":   hashedPassword,
+			"updated_at": time.Now(),
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// MarkVerificationSent records that a verification email is being sent, unless
+// the customer is verified already or one was sent less than interval ago.
+// It reports whether the send was recorded, so concurrent resends cannot
+// slip past the interval.
+func (r *Repository) MarkVerificationSent(ctx context.Context, id string, now time.Time, interval time.Duration) (bool, error) {
+	filter := bson.M{
+		"_id":                        id,
+		"email_verification_pending": true,
+		"$or": bson.A{
+			bson.M{"verification_sent_at": nil},
+			bson.M{"verification_sent_at": bson.M{"$lte": now.Add(-interval)}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"verification_sent_at": now}}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// MarkEmailVerified ends the pending verification of the customer, provided
+// the email address is still the one the token was issued for. Verifying
+// twice is not an error.
+func (r *Repository) MarkEmailVerified(ctx context.Context, id, email string, now time.Time) error {
+	filter := bson.M{"_id": id, "email": email, "email_verification_pending": true}
+	update := bson.M{
+		"$set": bson.M{
+			"email_verification_pending": false,
+			"email_verified_at":          now,
+			"updated_at":                 now,
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id, "email": email})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetActive activates or deactivates the customer and bumps its version.
+func (r *Repository) SetActive(ctx context.Context, id string, active bool) error {
+	update := bson.M{
+		"$set": bson.M{
+			"is_active":  active,
+			"updated_at": time.Now(),
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -6,47 +6,86 @@ import (
 	"tesodev-korpes/CustomerService/internal/types"
 	"tesodev-korpes/pkg/auth"
 	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/pagination"
 	"time"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Service struct {
-	repo *Repository
+	repo        *Repository
+	tokenRepo   refreshTokenStore
+	attemptRepo *LoginAttemptRepository
+	resetRepo   *PasswordResetRepository
+	denylist    *auth.Denylist
+	statuses    *auth.UserStatusCache
+	notifier    Notifier
+	// verificationURL is the email verification endpoint that links in
+	// verification emails point to.
+	verificationURL string
 }
 
-func NewService(repo *Repository) *Service {
+func NewService(repo *Repository, tokenRepo *RefreshTokenRepository, attemptRepo *LoginAttemptRepository, resetRepo *PasswordResetRepository, denylist *auth.Denylist, statuses *auth.UserStatusCache, notifier Notifier, verificationURL string) *Service {
 	return &Service{
-		repo: repo,
+		repo:            repo,
+		tokenRepo:       tokenRepo,
+		attemptRepo:     attemptRepo,
+		resetRepo:       resetRepo,
+		denylist:        denylist,
+		statuses:        statuses,
+		notifier:        notifier,
+		verificationURL: verificationURL,
 	}
 }
-func (s *Service) Login(ctx context.Context, email, password, correlationID string) (string, *types.Customer, error) {
+
+// Login checks the credentials and starts a session: an access token and the
+// first refresh token of a new token family. Failed attempts count against
+// both the account and the client IP, and either can be locked out; see
+// config.LoginLockout. Deactivated accounts are refused.
+func (s *Service) Login(ctx context.Context, email, password, ip, correlationID string) (*types.TokenResponse, *types.Customer, error) {
+	if err := s.checkLockout(ctx, email, ip); err != nil {
+		return nil, nil, err
+	}
+
 	customer, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return "", nil, customError.NewNotFound(customError.CustomerNotFound)
+			if err := s.recordLoginFailure(ctx, email, ip); err != nil {
+				customError.LogErrorWithCorrelation(err, correlationID)
+			}
+			return nil, nil, customError.NewNotFound(customError.CustomerNotFound)
 		}
 		customError.LogErrorWithCorrelation(err, correlationID)
-		return "", nil, customError.NewInternal(customError.CustomerServiceError, err)
+		return nil, nil, customError.NewInternal(customError.CustomerServiceError, err)
 	}
 
 	valid, err := auth.VerifyPassword(password, customer.Password)
 	if err != nil {
 		customError.LogErrorWithCorrelation(err, correlationID)
-		return "", nil, customError.NewInternal(customError.CustomerServiceError, err)
+		return nil, nil, customError.NewInternal(customError.CustomerServiceError, err)
 	}
 	if !valid {
-		return "", nil, customError.NewUnauthorized(customError.InvalidCredentials)
+		if err := s.recordLoginFailure(ctx, email, ip); err != nil {
+			customError.LogErrorWithCorrelation(err, correlationID)
+		}
+		return nil, nil, customError.NewUnauthorized(customError.InvalidCredentials)
 	}
 
-	token, err := auth.GenerateJWT(customer.Id)
+	if err := s.attemptRepo.Reset(ctx, accountAttemptKey(email)); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+	}
+	if !customer.IsActive {
+		return nil, nil, customError.NewForbidden(customError.AccountDisabled)
+	}
+
+	tokens, err := s.issueTokens(ctx, customer, uuid.NewString())
 	if err != nil {
 		customError.LogErrorWithCorrelation(err, correlationID)
-		return "", nil, customError.NewInternal(customError.CustomerServiceError, err)
+		return nil, nil, customError.NewInternal(customError.CustomerServiceError, err)
 	}
 
-	return token, customer, nil
+	return tokens, customer, nil
 }
 func (s *Service) GetByEmail(ctx context.Context, email string) (*types.Customer, error) {
 	customer, err := s.repo.GetByEmail(ctx, email)
@@ -60,6 +99,16 @@ func (s *Service) GetByEmail(ctx context.Context, email string) (*types.Customer
 	return customer, nil
 }
 
+// EmailOwner returns the id of the customer with the email; it backs the
+// ownership check of the routes that take an email.
+func (s *Service) EmailOwner(ctx context.Context, email string) (string, error) {
+	customer, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+	return customer.Id, nil
+}
+
 func (s *Service) GetByID(ctx context.Context, id string) (*types.CustomerResponseModel, error) {
 	customer, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -69,7 +118,10 @@ func (s *Service) GetByID(ctx context.Context, id string) (*types.CustomerRespon
 	return ToCustomerResponse(customer), nil
 }
 
-func (s *Service) Create(ctx context.Context, req *types.CreateCustomerRequestModel) (string, error) {
+// Create stores the customer pending email verification and sends the
+// verification link. A failed send is only logged; the customer can ask for
+// the link again.
+func (s *Service) Create(ctx context.Context, req *types.CreateCustomerRequestModel, correlationID string) (string, error) {
 	hashedPwd, err := auth.HashPassword(req.Password)
 	if err != nil {
 		return "", err
@@ -77,14 +129,20 @@ func (s *Service) Create(ctx context.Context, req *types.CreateCustomerRequestMo
 	req.Password = string(hashedPwd)
 
 	customer := FromCreateCustomerRequest(req)
-	customer.CreatedAt = time.Now()
-	customer.UpdatedAt = time.Now()
+	now := time.Now()
+	customer.CreatedAt = now
+	customer.UpdatedAt = now
+	customer.VerificationSentAt = &now
 
 	id, err := s.repo.Create(ctx, customer)
 	if err != nil {
 		return "", err
 	}
 
+	if err := s.sendVerification(ctx, customer, now); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+	}
+
 	return id, nil
 }
 
@@ -105,27 +163,35 @@ func (s *Service) Delete(ctx context.Context, id string) error {
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return err
 	}
-	return nil
+	s.statuses.Invalidate(id)
+	return s.revokeAccessTokens(ctx, id, time.Now())
 }
 
-func (s *Service) Get(ctx context.Context, params types.Pagination) ([]types.CustomerResponseModel, error) {
-	skip := (params.Page - 1) * params.Limit
-
-	findOptions := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(params.Limit))
-
-	customers, err := s.repo.Get(ctx, findOptions)
-
+func (s *Service) Get(ctx context.Context, params pagination.Params) (*pagination.Page[types.CustomerResponseModel], error) {
+	customers, err := s.repo.Get(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []types.CustomerResponseModel
-	for _, c := range customers {
-		customerResponse := ToCustomerResponse(&c)
-		responses = append(responses, *customerResponse)
+	customers, next := pagination.Trim(customers, params, "created_at", true, func(c types.Customer) (interface{}, string) {
+		return c.CreatedAt, c.Id
+	})
+
+	page := &pagination.Page[types.CustomerResponseModel]{
+		Data:       make([]types.CustomerResponseModel, 0, len(customers)),
+		NextCursor: next,
+	}
+	for i := range customers {
+		page.Data = append(page.Data, *ToCustomerResponse(&customers[i]))
+	}
+
+	if params.WithTotal {
+		total, err := s.repo.Count(ctx)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
 	}
 
-	return responses, nil
+	return page, nil
 }
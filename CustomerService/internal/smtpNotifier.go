@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier is a Notifier that sends notifications as plain text email
+// through an SMTP server. STARTTLS is used when the server offers it; the
+// credentials, if any, are only sent over TLS or to localhost.
+type SMTPNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPNotifier(host, port, username, password, from string) *SMTPNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPNotifier{
+		addr: net.JoinHostPort(host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (n *SMTPNotifier) Notify(_ context.Context, notification Notification) error {
+	if strings.ContainsAny(notification.To, "\r\n") || strings.ContainsAny(notification.Subject, "\r\n") {
+		return fmt.Errorf("notification for %s has a malformed header", notification.CustomerId)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", notification.To)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", notification.Subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", notification.CreatedAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(notification.Body, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{notification.To}, []byte(msg.String()))
+}
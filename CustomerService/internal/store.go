@@ -0,0 +1,21 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/CustomerService/internal/types"
+	"time"
+)
+
+// refreshTokenStore is what the service needs from the refresh token
+// collection. RefreshTokenRepository implements it against MongoDB; tests use
+// an in-memory store. Consume spends a live token at most once and returns
+// mongo.ErrNoDocuments for unknown, expired or spent ones.
+type refreshTokenStore interface {
+	Create(ctx context.Context, token *types.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*types.RefreshToken, error)
+	Consume(ctx context.Context, hash string, now time.Time) (*types.RefreshToken, error)
+	RevokeFamily(ctx context.Context, familyID string, now time.Time) error
+	RevokeCustomer(ctx context.Context, customerID string, now time.Time) error
+	RevokeOtherFamilies(ctx context.Context, customerID, familyID string, now time.Time) error
+	LiveAccessTokens(ctx context.Context, customerID string, now time.Time) ([]types.RefreshToken, error)
+}
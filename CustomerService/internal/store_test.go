@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/CustomerService/internal/types"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryRefreshTokens is an in-memory refreshTokenStore with the same
+// conditions as RefreshTokenRepository. Methods the tests do not need are
+// left to the embedded nil interface and panic.
+type memoryRefreshTokens struct {
+	refreshTokenStore
+	tokens map[string]*types.RefreshToken
+}
+
+func newMemoryRefreshTokens(tokens ...*types.RefreshToken) *memoryRefreshTokens {
+	m := &memoryRefreshTokens{tokens: map[string]*types.RefreshToken{}}
+	for _, token := range tokens {
+		c := *token
+		m.tokens[c.Hash] = &c
+	}
+	return m
+}
+
+// live reports whether the token can still be spent.
+func (m *memoryRefreshTokens) live(hash string, now time.Time) bool {
+	token, ok := m.tokens[hash]
+	return ok && token.RevokedAt == nil && now.Before(token.ExpiresAt)
+}
+
+// family returns the hashes of the tokens of a family.
+func (m *memoryRefreshTokens) family(familyID string) []string {
+	var hashes []string
+	for hash, token := range m.tokens {
+		if token.FamilyId == familyID {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes
+}
+
+func (m *memoryRefreshTokens) Create(_ context.Context, token *types.RefreshToken) error {
+	c := *token
+	m.tokens[c.Hash] = &c
+	return nil
+}
+
+func (m *memoryRefreshTokens) GetByHash(_ context.Context, hash string) (*types.RefreshToken, error) {
+	token, ok := m.tokens[hash]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	c := *token
+	return &c, nil
+}
+
+func (m *memoryRefreshTokens) Consume(_ context.Context, hash string, now time.Time) (*types.RefreshToken, error) {
+	if !m.live(hash, now) {
+		return nil, mongo.ErrNoDocuments
+	}
+	token := m.tokens[hash]
+	c := *token
+	token.RevokedAt = &now
+	return &c, nil
+}
+
+func (m *memoryRefreshTokens) RevokeFamily(_ context.Context, familyID string, now time.Time) error {
+	for _, token := range m.tokens {
+		if token.FamilyId == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
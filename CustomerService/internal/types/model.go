@@ -17,6 +17,13 @@ type Customer struct {
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
 	IsActive  bool      `bson:"is_active" json:"is_active"`
 	Token     string    `bson:"token" json:"token"`
+	Version   int       `bson:"version" json:"version"`
+	// VerificationPending is set until the customer proves the email
+	// address is theirs. Customers stored before verification existed lack
+	// the field and count as verified.
+	VerificationPending bool       `bson:"email_verification_pending" json:"email_verification_pending"`
+	VerificationSentAt  *time.Time `bson:"verification_sent_at,omitempty" json:"verification_sent_at,omitempty"`
+	EmailVerifiedAt     *time.Time `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
 }
 
 type Address struct {
@@ -35,3 +42,38 @@ type Role struct {
 	SystemRole string `bson:"role"`
 	Membership string `bson:"membership"`
 }
+
+// RefreshToken is the server-side record of a refresh token. Only the hash of
+// the token is stored. Tokens rotated from the same login share a FamilyId;
+// presenting a token that was already rotated revokes the whole family.
+// AccessTokenId and AccessExpiresAt are the jti and expiry of the access token
+// issued with it, so the access token can be denied with its account.
+type RefreshToken struct {
+	Hash            string     `bson:"_id"`
+	CustomerId      string     `bson:"customer_id"`
+	FamilyId        string     `bson:"family_id"`
+	AccessTokenId   string     `bson:"access_token_id,omitempty"`
+	AccessExpiresAt time.Time  `bson:"access_expires_at,omitempty"`
+	ExpiresAt       time.Time  `bson:"expires_at"`
+	CreatedAt       time.Time  `bson:"created_at"`
+	RevokedAt       *time.Time `bson:"revoked_at,omitempty"`
+}
+
+// LoginAttempt counts the failed logins of one account ("account:<email>")
+// or one client IP ("ip:<address>").
+type LoginAttempt struct {
+	Key         string     `bson:"_id"`
+	Failures    int        `bson:"failures"`
+	LockedUntil *time.Time `bson:"locked_until,omitempty"`
+	UpdatedAt   time.Time  `bson:"updated_at"`
+}
+
+// PasswordReset is a single-use password reset token. Only its hash is
+// stored; UsedAt is set when the token is redeemed.
+type PasswordReset struct {
+	Hash       string     `bson:"_id"`
+	CustomerId string     `bson:"customer_id"`
+	ExpiresAt  time.Time  `bson:"expires_at"`
+	CreatedAt  time.Time  `bson:"created_at"`
+	UsedAt     *time.Time `bson:"used_at,omitempty"`
+}
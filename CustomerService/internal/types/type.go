@@ -21,26 +21,23 @@ type UpdateCustomerRequestModel struct {
 	Phone     []Phone   `json:"phone,omitempty" validate:"omitempty,dive"`
 	Address   []Address `json:"address,omitempty" validate:"omitempty,dive"`
 	Password  string    `json:"password,omitempty" validate:"omitempty"`
-	IsActive  bool      `json:"is_active,omitempty"`
 	Role      Role      `json:"role,omitempty" validate:"omitempty"`
+	Version   *int      `json:"version,omitempty"`
 }
 
 type CustomerResponseModel struct {
-	ID        string    `json:"id"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Email     string    `json:"email"`
-	Phone     []Phone   `json:"phone"`
-	Address   []Address `json:"address"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Role      Role      `json:"system"`
-}
-
-type Pagination struct {
-	Page  int
-	Limit int
+	ID            string    `json:"id"`
+	FirstName     string    `json:"first_name"`
+	LastName      string    `json:"last_name"`
+	Email         string    `json:"email"`
+	Phone         []Phone   `json:"phone"`
+	Address       []Address `json:"address"`
+	IsActive      bool      `json:"is_active"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Role          Role      `json:"system"`
+	Version       int       `json:"version"`
 }
 
 type LoginRequestModel struct {
@@ -49,9 +46,41 @@ type LoginRequestModel struct {
 }
 
 type LoginResponse struct {
-	Token   string                 `json:"token"`
-	User    *CustomerResponseModel `json:"user"`
-	Message string                 `json:"message"`
+	Token        string                 `json:"token"`
+	RefreshToken string                 `json:"refresh_token"`
+	User         *CustomerResponseModel `json:"user"`
+	Message      string                 `json:"message"`
+}
+
+type ForgotPasswordRequestModel struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequestModel struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type ChangePasswordRequestModel struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type RefreshRequestModel struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutRequestModel names the session to end. Without a refresh token every
+// session of the caller is ended.
+type LogoutRequestModel struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// TokenResponse is an access token with the refresh token that replaces the
+// one used to obtain it.
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 type VerifiedUser struct {
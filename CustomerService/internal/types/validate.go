@@ -55,3 +55,45 @@ func (c LoginRequestModel) LoginValidate() *customError.AppError {
 	return nil
 
 }
+
+func (c RefreshRequestModel) RefreshValidate() *customError.AppError {
+
+	if !validators.IsEmpty(c.RefreshToken) {
+		return customError.NewUnauthorized(customError.InvalidRefreshToken)
+	}
+
+	return nil
+}
+
+func (c ForgotPasswordRequestModel) ForgotValidate() *customError.AppError {
+
+	if !validators.IsValidEmail(c.Email) {
+		return customError.NewUnprocessableEntity(customError.InvalidEmailFormat, nil)
+	}
+
+	return nil
+}
+
+func (c ResetPasswordRequestModel) ResetValidate() *customError.AppError {
+
+	if !validators.IsEmpty(c.Token) {
+		return customError.NewBadRequest(customError.InvalidPasswordResetToken)
+	}
+	if !validators.IsValidPassword(c.NewPassword) {
+		return customError.NewUnprocessableEntity(customError.InvalidPasswordFormat, nil)
+	}
+
+	return nil
+}
+
+func (c ChangePasswordRequestModel) ChangeValidate() *customError.AppError {
+
+	if !validators.IsEmpty(c.CurrentPassword) {
+		return customError.NewUnauthorized(customError.InvalidCurrentPassword)
+	}
+	if !validators.IsValidPassword(c.NewPassword) {
+		return customError.NewUnprocessableEntity(customError.InvalidPasswordFormat, nil)
+	}
+
+	return nil
+}
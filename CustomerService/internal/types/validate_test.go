@@ -0,0 +1,37 @@
+package types
+
+import (
+	"tesodev-korpes/pkg/customError"
+	"testing"
+)
+
+func TestPasswordRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     *customError.AppError
+		wantKey customError.ErrorKey
+	}{
+		{"forgot", ForgotPasswordRequestModel{Email: "jane@example.com"}.ForgotValidate(), ""},
+		{"forgot with a bad email", ForgotPasswordRequestModel{Email: "jane"}.ForgotValidate(), customError.InvalidEmailFormat},
+		{"reset", ResetPasswordRequestModel{Token: "token", NewPassword: "new-password"}.ResetValidate(), ""},
+		{"reset without a token", ResetPasswordRequestModel{Token: " ", NewPassword: "new-password"}.ResetValidate(), customError.InvalidPasswordResetToken},
+		{"reset to a short password", ResetPasswordRequestModel{Token: "token", NewPassword: "short"}.ResetValidate(), customError.InvalidPasswordFormat},
+		{"change", ChangePasswordRequestModel{CurrentPassword: "old", NewPassword: "new-password"}.ChangeValidate(), ""},
+		{"change without the current password", ChangePasswordRequestModel{NewPassword: "new-password"}.ChangeValidate(), customError.InvalidCurrentPassword},
+		{"change to a short password", ChangePasswordRequestModel{CurrentPassword: "old", NewPassword: "short"}.ChangeValidate(), customError.InvalidPasswordFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantKey == "" {
+				if tt.err != nil {
+					t.Fatalf("validate = %v, want nil", tt.err)
+				}
+				return
+			}
+			if tt.err == nil || tt.err.Code != customError.ErrorDefinitions[tt.wantKey].TypeCode {
+				t.Fatalf("validate = %v, want %s", tt.err, tt.wantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"tesodev-korpes/CustomerService/config"
+	"tesodev-korpes/CustomerService/internal/types"
+	"tesodev-korpes/pkg/auth"
+	"tesodev-korpes/pkg/customError"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ResendVerification sends the customer a new verification link. Links are
+// sent at most once per config.EmailVerification.ResendInterval.
+func (s *Service) ResendVerification(ctx context.Context, customerID, correlationID string) error {
+	customer, err := s.repo.GetByID(ctx, customerID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return customError.NewNotFound(customError.CustomerNotFound)
+	}
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	if !customer.VerificationPending {
+		return customError.NewConflict(customError.EmailAlreadyVerified)
+	}
+
+	now := time.Now()
+	sent, err := s.repo.MarkVerificationSent(ctx, customer.Id, now, config.EmailVerification.ResendInterval)
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	if !sent {
+		wait := config.EmailVerification.ResendInterval
+		if customer.VerificationSentAt != nil {
+			wait = customer.VerificationSentAt.Add(wait).Sub(now)
+		}
+		return customError.NewTooManyRequests(customError.VerificationResendThrottled, int(math.Ceil(max(wait, time.Second).Seconds())))
+	}
+
+	if err := s.sendVerification(ctx, customer, now); err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	return nil
+}
+
+// VerifyEmail ends the pending verification of the customer the token was
+// issued for. Tokens issued for an address the customer no longer has are
+// refused.
+func (s *Service) VerifyEmail(ctx context.Context, token, correlationID string) error {
+	claims, err := auth.VerifyEmailVerificationToken(token)
+	if err != nil {
+		return customError.NewBadRequest(customError.InvalidVerificationToken)
+	}
+
+	err = s.repo.MarkEmailVerified(ctx, claims.Subject, claims.Email, time.Now())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return customError.NewBadRequest(customError.InvalidVerificationToken)
+	}
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.CustomerServiceError, err)
+	}
+	s.statuses.Invalidate(claims.Subject)
+	return nil
+}
+
+// sendVerification signs a verification token for the customer's current
+// address and sends the link to it.
+func (s *Service) sendVerification(ctx context.Context, customer *types.Customer, now time.Time) error {
+	token, err := auth.GenerateEmailVerificationToken(customer.Id, customer.Email, config.EmailVerification.TokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link, err := url.Parse(s.verificationURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return s.notifier.Notify(ctx, Notification{
+		CustomerId: customer.Id,
+		To:         customer.Email,
+		Subject:    "Verify your email address",
+		Body: fmt.Sprintf("Open this link within %d hours to verify your email address:\n%s",
+			int(config.EmailVerification.TokenTTL.Hours()), link.String()),
+		CreatedAt: now,
+	})
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"net/http"
+	"tesodev-korpes/pkg/customError"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ConfirmVerification godoc
+// @Summary Verify the email address
+// @Description Verify the customer's email address with the token from the verification link. Verifying twice is not an error.
+// @Tags authentication
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} errorPackage.AppError "Invalid or expired token"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /customer/verification/confirm [get]
+func (h *Handler) ConfirmVerification(c echo.Context) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+
+	token := c.QueryParam("token")
+	if token == "" {
+		return customError.NewBadRequest(customError.InvalidVerificationToken)
+	}
+
+	if err := h.service.VerifyEmail(c.Request().Context(), token, correlationID); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "Email address verified",
+	})
+}
+
+// ResendVerification godoc
+// @Summary Resend the verification email
+// @Description Send the caller a new email verification link. Links can be requested at most once a minute.
+// @Tags authentication
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 202 "Accepted"
+// @Failure 401 {object} errorPackage.AppError "Unauthorized"
+// @Failure 409 {object} errorPackage.AppError "Email address already verified"
+// @Failure 429 {object} errorPackage.AppError "A verification email was sent recently"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /customer/verification/resend [post]
+func (h *Handler) ResendVerification(c echo.Context) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+	userID, _ := c.Get("userId").(string)
+	if userID == "" {
+		return customError.NewUnauthorized(customError.MissingAuthToken)
+	}
+
+	if err := h.service.ResendVerification(c.Request().Context(), userID, correlationID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"tesodev-korpes/CustomerService/internal/types"
+	"tesodev-korpes/pkg/auth"
+	"testing"
+	"time"
+)
+
+type recordingNotifier struct {
+	sent []Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification Notification) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func TestSendVerification(t *testing.T) {
+	notifier := &recordingNotifier{}
+	s := &Service{notifier: notifier, verificationURL: "https://shop.example.com/verify?lang=tr"}
+	customer := &types.Customer{Id: "customer-1", Email: "jane@example.com"}
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := s.sendVerification(context.Background(), customer, now); err != nil {
+		t.Fatalf("sendVerification error = %v", err)
+	}
+	if len(notifier.sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(notifier.sent))
+	}
+	sent := notifier.sent[0]
+	if sent.CustomerId != "customer-1" || sent.To != "jane@example.com" || !sent.CreatedAt.Equal(now) {
+		t.Fatalf("notification = %+v, want one to jane@example.com at %s", sent, now)
+	}
+
+	// The link is the last line of the body and keeps the query of the
+	// configured URL.
+	lines := strings.Split(sent.Body, "\n")
+	link, err := url.Parse(lines[len(lines)-1])
+	if err != nil {
+		t.Fatalf("body ends in %q, want a link", lines[len(lines)-1])
+	}
+	if link.Host != "shop.example.com" || link.Path != "/verify" || link.Query().Get("lang") != "tr" {
+		t.Fatalf("link = %s, want the configured verification URL", link)
+	}
+	claims, err := auth.VerifyEmailVerificationToken(link.Query().Get("token"))
+	if err != nil {
+		t.Fatalf("link token does not verify: %v", err)
+	}
+	if claims.Subject != "customer-1" || claims.Email != "jane@example.com" {
+		t.Fatalf("token claims = %+v, want customer-1 at jane@example.com", claims)
+	}
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
+	"log"
 	config3 "tesodev-korpes/OrderService/config"
 	"tesodev-korpes/OrderService/internal"
 	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/auth"
 	"tesodev-korpes/pkg/client"
 	"tesodev-korpes/pkg/middleware"
 	"tesodev-korpes/shared/config"
@@ -21,10 +24,70 @@ func BootOrderService(clientMongo *mongo.Client, e *echo.Echo) {
 		panic(err)
 	}
 
+	cartCol, err := pkg.GetMongoCollection(clientMongo, cfg.DbConfig.DBName, cfg.DbConfig.CartColName)
+	if err != nil {
+		panic(err)
+	}
+
+	couponCol, err := pkg.GetMongoCollection(clientMongo, cfg.DbConfig.DBName, cfg.DbConfig.CouponColName)
+	if err != nil {
+		panic(err)
+	}
+
+	redemptionCol, err := pkg.GetMongoCollection(clientMongo, cfg.DbConfig.DBName, cfg.DbConfig.CouponRedemptionColName)
+	if err != nil {
+		panic(err)
+	}
+
+	rateCol, err := pkg.GetMongoCollection(clientMongo, cfg.DbConfig.DBName, cfg.DbConfig.ExchangeRateColName)
+	if err != nil {
+		panic(err)
+	}
+
+	taxCol, err := pkg.GetMongoCollection(clientMongo, cfg.DbConfig.DBName, cfg.DbConfig.TaxRuleColName)
+	if err != nil {
+		panic(err)
+	}
+
+	shippingCol, err := pkg.GetMongoCollection(clientMongo, cfg.DbConfig.DBName, cfg.DbConfig.ShippingMethodColName)
+	if err != nil {
+		panic(err)
+	}
+
+	customerCol, err := pkg.GetMongoCollection(clientMongo, cfg.DbConfig.DBName, cfg.DbConfig.CustomerColName)
+	if err != nil {
+		panic(err)
+	}
+
+	revokedTokenCol, err := pkg.GetMongoCollection(clientMongo, cfg.DbConfig.DBName, cfg.DbConfig.RevokedTokenColName)
+	if err != nil {
+		panic(err)
+	}
+
 	repo := internal.NewRepository(orderCol)
+	if err := repo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("order indexes could not be created: %v", err)
+	}
+	go func() {
+		migrated, err := repo.MigrateMoney(context.Background())
+		if err != nil {
+			log.Printf("money migration stopped after %d orders: %v", migrated, err)
+			return
+		}
+		log.Printf("money migration: %d orders rewritten", migrated)
+	}()
+	cartRepo := internal.NewCartRepository(cartCol)
+	couponRepo := internal.NewCouponRepository(couponCol, redemptionCol)
+	rateRepo := internal.NewExchangeRateRepository(rateCol)
+	taxRepo := internal.NewTaxRepository(taxCol)
+	shippingRepo := internal.NewShippingRepository(shippingCol)
 	cc := client.New("http://localhost:8001", 5*time.Second)
-	service := internal.NewService(repo, cc)
-	handler := internal.NewHandler(e, service, clientMongo)
+	pc := client.New("http://localhost:8003", 5*time.Second)
+	service := internal.NewService(repo, cartRepo, couponRepo, rateRepo, taxRepo, shippingRepo, cc, pc, internal.NewFakeGateway())
+	authConfig := config.GetAuthConfig()
+	denylist := auth.NewDenylist(revokedTokenCol, authConfig.RevocationCacheTTL)
+	statuses := auth.NewUserStatusCache(customerCol, authConfig.UserStatusCacheTTL)
+	handler := internal.NewHandler(e, service, denylist, statuses)
 
 	internalHandlers := map[string]echo.HandlerFunc{
 		"/internal/price/premium/:id":     handler.GetPremiumOrderPrice,
@@ -36,12 +99,12 @@ func BootOrderService(clientMongo *mongo.Client, e *echo.Echo) {
 			e.Router().Find(c.Request().Method, c.Path(), c)
 			return c.Handler()(c)
 		},
-		middleware.Authentication(clientMongo, nil),
-		middleware.AuthorizationMiddleware(&config.Cfg),
-		middleware.RoleRouting(config.Cfg, internalHandlers),
+		middleware.Ownership("id", service.OrderOwner),
+		middleware.RoleRouting(&config.Cfg, internalHandlers),
 	)
 	// e.GET("/internal/price/premium/:id", handler.GetPremiumOrderPrice)
 	// e.GET("/internal/price/non-premium/:id", handler.GetNonPremiumOrderPrice)
 
+	middleware.MustCheckRoutePolicies(e, &config.Cfg, "order")
 	e.Logger.Fatal(e.Start(cfg.Port))
 }
@@ -3,52 +3,210 @@ package config
 type OrderConfig struct {
 	Port     string
 	DbConfig struct {
-		DBName  string
-		ColName string
+		DBName                  string
+		ColName                 string
+		CartColName             string
+		CouponColName           string
+		CouponRedemptionColName string
+		ExchangeRateColName     string
+		TaxRuleColName          string
+		ShippingMethodColName   string
+		CustomerColName         string
+		RevokedTokenColName     string
 	}
 }
 
 var OrderStatus = struct {
-	Ordered   string
-	Shipped   string
-	Delivered string
-	Canceled  string
+	Ordered        string
+	PaymentPending string
+	Paid           string
+	Shipped        string
+	Delivered      string
+	Canceled       string
+	Returned       string
+	Refunded       string
 }{
-	Ordered:   "ORDERED",
-	Shipped:   "SHIPPED",
-	Delivered: "DELIVERED",
-	Canceled:  "CANCELED",
+	Ordered:        "ORDERED",
+	PaymentPending: "PAYMENT_PENDING",
+	Paid:           "PAID",
+	Shipped:        "SHIPPED",
+	Delivered:      "DELIVERED",
+	Canceled:       "CANCELED",
+	Returned:       "RETURNED",
+	Refunded:       "REFUNDED",
+}
+
+var PaymentStatus = struct {
+	Authorizing       string
+	Declined          string
+	Authorized        string
+	Capturing         string
+	Captured          string
+	Voided            string
+	PartiallyRefunded string
+	Refunded          string
+}{
+	Authorizing:       "AUTHORIZING",
+	Declined:          "DECLINED",
+	Authorized:        "AUTHORIZED",
+	Capturing:         "CAPTURING",
+	Captured:          "CAPTURED",
+	Voided:            "VOIDED",
+	PartiallyRefunded: "PARTIALLY_REFUNDED",
+	Refunded:          "REFUNDED",
+}
+
+var PaymentTransactionType = struct {
+	Authorize string
+	Capture   string
+	Refund    string
+	Void      string
+}{
+	Authorize: "AUTHORIZE",
+	Capture:   "CAPTURE",
+	Refund:    "REFUND",
+	Void:      "VOID",
+}
+
+// ReturnStatus.Approving marks a return whose refund is being issued; it is
+// claimed before the gateway is called so an approval can only refund once.
+var ReturnStatus = struct {
+	Requested string
+	Approving string
+	Approved  string
+	Rejected  string
+}{
+	Requested: "REQUESTED",
+	Approving: "APPROVING",
+	Approved:  "APPROVED",
+	Rejected:  "REJECTED",
+}
+
+var DiscountType = struct {
+	Percentage  string
+	FixedAmount string
+}{
+	Percentage:  "percentage",
+	FixedAmount: "fixed-amount",
+}
+
+var ShippingRateType = struct {
+	Flat               string
+	Weight             string
+	FreeAboveThreshold string
+}{
+	Flat:               "flat",
+	Weight:             "weight",
+	FreeAboveThreshold: "free-above-threshold",
+}
+
+func IsOrderStatus(status string) bool {
+	switch status {
+	case OrderStatus.Ordered, OrderStatus.PaymentPending, OrderStatus.Paid, OrderStatus.Shipped,
+		OrderStatus.Delivered, OrderStatus.Canceled, OrderStatus.Returned, OrderStatus.Refunded:
+		return true
+	}
+	return false
+}
+
+// OrderSortFields maps the fields GET /order/list can sort by to the order
+// document fields. Every one of them is indexed at boot.
+var OrderSortFields = map[string]string{
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+	"total_price": "total_price.amount",
+	"status":      "status",
+	"customer_id": "customer_id",
+}
+
+// OrderTransitions lists, for every status, the statuses an order may move to.
+// Statuses without an entry are terminal.
+var OrderTransitions = map[string][]string{
+	OrderStatus.Ordered:        {OrderStatus.PaymentPending, OrderStatus.Canceled},
+	OrderStatus.PaymentPending: {OrderStatus.Paid, OrderStatus.Canceled},
+	OrderStatus.Paid:           {OrderStatus.Shipped, OrderStatus.Canceled},
+	OrderStatus.Shipped:        {OrderStatus.Delivered},
+	OrderStatus.Delivered:      {OrderStatus.Returned},
+	OrderStatus.Returned:       {OrderStatus.Refunded},
 }
 
 var cfgs = map[string]OrderConfig{
 	"prod": {
 		Port: ":8002",
 		DbConfig: struct {
-			DBName  string
-			ColName string
+			DBName                  string
+			ColName                 string
+			CartColName             string
+			CouponColName           string
+			CouponRedemptionColName string
+			ExchangeRateColName     string
+			TaxRuleColName          string
+			ShippingMethodColName   string
+			CustomerColName         string
+			RevokedTokenColName     string
 		}{
-			DBName:  "tesodev",
-			ColName: "order",
+			DBName:                  "tesodev",
+			ColName:                 "order",
+			CartColName:             "cart",
+			CouponColName:           "coupon",
+			CouponRedemptionColName: "coupon_redemption",
+			ExchangeRateColName:     "exchange_rate",
+			TaxRuleColName:          "tax_rule",
+			ShippingMethodColName:   "shipping_method",
+			CustomerColName:         "customer",
+			RevokedTokenColName:     "revoked_token",
 		},
 	},
 	"qa": {
 		Port: ":8002",
 		DbConfig: struct {
-			DBName  string
-			ColName string
+			DBName                  string
+			ColName                 string
+			CartColName             string
+			CouponColName           string
+			CouponRedemptionColName string
+			ExchangeRateColName     string
+			TaxRuleColName          string
+			ShippingMethodColName   string
+			CustomerColName         string
+			RevokedTokenColName     string
 		}{
-			DBName:  "tesodev",
-			ColName: "order",
+			DBName:                  "tesodev",
+			ColName:                 "order",
+			CartColName:             "cart",
+			CouponColName:           "coupon",
+			CouponRedemptionColName: "coupon_redemption",
+			ExchangeRateColName:     "exchange_rate",
+			TaxRuleColName:          "tax_rule",
+			ShippingMethodColName:   "shipping_method",
+			CustomerColName:         "customer",
+			RevokedTokenColName:     "revoked_token",
 		},
 	},
 	"dev": {
 		Port: ":8002",
 		DbConfig: struct {
-			DBName  string
-			ColName string
+			DBName                  string
+			ColName                 string
+			CartColName             string
+			CouponColName           string
+			CouponRedemptionColName string
+			ExchangeRateColName     string
+			TaxRuleColName          string
+			ShippingMethodColName   string
+			CustomerColName         string
+			RevokedTokenColName     string
 		}{
-			DBName:  "tesodev",
-			ColName: "order",
+			DBName:                  "tesodev",
+			ColName:                 "order",
+			CartColName:             "cart",
+			CouponColName:           "coupon",
+			CouponRedemptionColName: "coupon_redemption",
+			ExchangeRateColName:     "exchange_rate",
+			TaxRuleColName:          "tax_rule",
+			ShippingMethodColName:   "shipping_method",
+			CustomerColName:         "customer",
+			RevokedTokenColName:     "revoked_token",
 		},
 	},
 }
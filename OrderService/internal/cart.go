@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"time"
+)
+
+// GetCart prices the cart with the current catalog and coupons. A coupon that
+// no longer applies does not hide the cart; the cart is priced without
+// discounts and the reason is returned next to it.
+func (s *Service) GetCart(ctx context.Context, customerID string, membership string, token string) (*types.CartResponseModel, error) {
+	cart, err := s.cartRepo.Get(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := cartToOrderItems(cart)
+	if len(items) > 0 {
+		if err := s.resolveItems(items, token); err != nil {
+			return nil, err
+		}
+	}
+	total := calculateTotalPrice(items)
+
+	var discountError string
+	discounts, err := s.resolveCoupons(ctx, cart.DiscountCodes, customerID, membership, total)
+	if err != nil {
+		var appErr *customError.AppError
+		if !errors.As(err, &appErr) {
+			return nil, err
+		}
+		discounts, discountError = nil, appErr.Message
+	}
+
+	price := s.calculatePriceFromRepoResult(&types.OrderPriceInfo{
+		TotalPrice: total,
+		Discounts:  discounts,
+	})
+
+	response := ToCartResponse(cart, items, discounts, price)
+	response.DiscountError = discountError
+	return response, nil
+}
+
+// AddCartItem puts a product in the cart after checking that it can be ordered.
+// Stock is not reserved here; that only happens when the cart is checked out.
+func (s *Service) AddCartItem(ctx context.Context, customerID string, productID string, quantity int, token string) error {
+	if err := s.resolveItems([]types.OrderItem{{ProductId: productID, Quantity: quantity}}, token); err != nil {
+		return err
+	}
+	return s.cartRepo.AddItem(ctx, customerID, productID, quantity)
+}
+
+func (s *Service) UpdateCartItem(ctx context.Context, customerID string, productID string, quantity int) error {
+	return s.cartRepo.SetQuantity(ctx, customerID, productID, quantity)
+}
+
+func (s *Service) RemoveCartItem(ctx context.Context, customerID string, productID string) error {
+	return s.cartRepo.RemoveItem(ctx, customerID, productID)
+}
+
+// ApplyCartDiscounts replaces the discount codes of the cart after checking
+// that they apply to its current contents.
+func (s *Service) ApplyCartDiscounts(ctx context.Context, customerID string, membership string, codes []string, token string) error {
+	cart, err := s.cartRepo.Get(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	items := cartToOrderItems(cart)
+	if len(items) > 0 {
+		if err := s.resolveItems(items, token); err != nil {
+			return err
+		}
+	}
+
+	codes = normalizeCouponCodes(codes)
+	if _, err := s.resolveCoupons(ctx, codes, customerID, membership, calculateTotalPrice(items)); err != nil {
+		return err
+	}
+	return s.cartRepo.SetDiscountCodes(ctx, customerID, codes)
+}
+
+func (s *Service) RemoveCartDiscounts(ctx context.Context, customerID string) error {
+	return s.cartRepo.SetDiscountCodes(ctx, customerID, nil)
+}
+
+// Checkout turns the cart into an order through the same path as a direct
+// order creation. The cart is claimed first so that a repeated or concurrent
+// checkout cannot place the same cart twice; it is given back if the order
+// cannot be created and removed once the order is stored.
+func (s *Service) Checkout(ctx context.Context, customerID string, req *types.CheckoutRequestModel, token string, audit types.AuditInfo) (string, error) {
+	cart, err := s.cartRepo.Get(ctx, customerID)
+	if err != nil {
+		return "", err
+	}
+	if len(cart.Items) == 0 {
+		return "", customError.NewUnprocessableEntity(customError.EmptyCart, nil)
+	}
+
+	cart, err = s.cartRepo.Claim(ctx, customerID, time.Now())
+	if err != nil {
+		return "", err
+	}
+	claimedAt := *cart.CheckoutAt
+
+	id, err := s.checkoutClaimed(ctx, cart, req, token, audit)
+	if err != nil {
+		if unclaimErr := s.cartRepo.Unclaim(ctx, customerID, claimedAt); unclaimErr != nil {
+			customError.LogErrorWithCorrelation(unclaimErr, audit.CorrelationId)
+		}
+		return "", err
+	}
+
+	// The order is stored at this point, so failing the request would only
+	// make the customer retry it. The claim stays on the cart and keeps it
+	// from being ordered again until it times out.
+	if err := s.cartRepo.Clear(ctx, customerID, claimedAt); err != nil {
+		customError.LogErrorWithCorrelation(err, audit.CorrelationId)
+	}
+	return id, nil
+}
+
+func (s *Service) checkoutClaimed(ctx context.Context, cart *types.Cart, req *types.CheckoutRequestModel, token string, audit types.AuditInfo) (string, error) {
+	if len(cart.Items) == 0 {
+		return "", customError.NewUnprocessableEntity(customError.EmptyCart, nil)
+	}
+
+	orderReq := FromCart(cart, req)
+	if err := orderReq.CreateValidate(); err != nil {
+		return "", err
+	}
+
+	return s.Create(ctx, FromCreateOrderRequest(orderReq), token, audit)
+}
@@ -0,0 +1,205 @@
+package internal
+
+import (
+	"net/http"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/customError"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetCart godoc
+// @Summary Get the cart of the current customer
+// @Description Returns the cart items with current catalog prices and the totals after the matching discount
+// @Tags cart
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} types.CartResponseModel
+// @Failure 404 {object} errorPackage.AppError "A product in the cart no longer exists"
+// @Failure 422 {object} errorPackage.AppError "A product in the cart is unavailable"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /cart [get]
+func (h *Handler) GetCart(c echo.Context) error {
+	userID, _ := c.Get("userId").(string)
+	membership, _ := c.Get("userMembership").(string)
+	token := c.Request().Header.Get("Authorization")
+
+	cart, err := h.service.GetCart(c.Request().Context(), userID, membership, token)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, cart)
+}
+
+// AddCartItem godoc
+// @Summary Add a product to the cart
+// @Description Adds the quantity to the product's line in the cart, creating the line if needed
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param item body types.AddCartItemRequestModel true "Product and quantity"
+// @Success 200 {object} types.CartResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 404 {object} errorPackage.AppError "Product not found"
+// @Failure 422 {object} errorPackage.AppError "Product unavailable"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /cart/items [post]
+func (h *Handler) AddCartItem(c echo.Context) error {
+	var req types.AddCartItemRequestModel
+	if err := c.Bind(&req); err != nil || !pkg.IsValidUUID(req.ProductId) || req.Quantity <= 0 {
+		return customError.NewBadRequest(customError.InvalidCartBody)
+	}
+
+	userID, _ := c.Get("userId").(string)
+	token := c.Request().Header.Get("Authorization")
+	if err := h.service.AddCartItem(c.Request().Context(), userID, req.ProductId, req.Quantity, token); err != nil {
+		return toOrderError(c, err)
+	}
+
+	return h.GetCart(c)
+}
+
+// UpdateCartItem godoc
+// @Summary Change the quantity of a product in the cart
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param productId path string true "Product ID (UUID)"
+// @Param item body types.UpdateCartItemRequestModel true "New quantity"
+// @Success 200 {object} types.CartResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format or request body"
+// @Failure 404 {object} errorPackage.AppError "Product is not in the cart"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /cart/items/{productId} [put]
+func (h *Handler) UpdateCartItem(c echo.Context) error {
+	productID := c.Param("productId")
+	if !pkg.IsValidUUID(productID) {
+		return customError.NewBadRequest(customError.InvalidProductID)
+	}
+
+	var req types.UpdateCartItemRequestModel
+	if err := c.Bind(&req); err != nil || req.Quantity <= 0 {
+		return customError.NewBadRequest(customError.InvalidCartBody)
+	}
+
+	userID, _ := c.Get("userId").(string)
+	if err := h.service.UpdateCartItem(c.Request().Context(), userID, productID, req.Quantity); err != nil {
+		return toOrderError(c, err)
+	}
+
+	return h.GetCart(c)
+}
+
+// RemoveCartItem godoc
+// @Summary Remove a product from the cart
+// @Tags cart
+// @Produce json
+// @Security ApiKeyAuth
+// @Param productId path string true "Product ID (UUID)"
+// @Success 200 {object} types.CartResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Product is not in the cart"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /cart/items/{productId} [delete]
+func (h *Handler) RemoveCartItem(c echo.Context) error {
+	productID := c.Param("productId")
+	if !pkg.IsValidUUID(productID) {
+		return customError.NewBadRequest(customError.InvalidProductID)
+	}
+
+	userID, _ := c.Get("userId").(string)
+	if err := h.service.RemoveCartItem(c.Request().Context(), userID, productID); err != nil {
+		return toOrderError(c, err)
+	}
+
+	return h.GetCart(c)
+}
+
+// ApplyCartDiscount godoc
+// @Summary Apply discount codes to the cart
+// @Description Replaces the discount codes of the cart after checking them against the cart; they are carried over to the order at checkout
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param discount body types.ApplyCartDiscountRequestModel true "Discount codes"
+// @Success 200 {object} types.CartResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 404 {object} errorPackage.AppError "Discount code not found"
+// @Failure 422 {object} errorPackage.AppError "Discount code cannot be applied"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /cart/discount [put]
+func (h *Handler) ApplyCartDiscount(c echo.Context) error {
+	var req types.ApplyCartDiscountRequestModel
+	if err := c.Bind(&req); err != nil || len(req.Codes) == 0 {
+		return customError.NewBadRequest(customError.InvalidCartBody)
+	}
+
+	userID, _ := c.Get("userId").(string)
+	membership, _ := c.Get("userMembership").(string)
+	token := c.Request().Header.Get("Authorization")
+	if err := h.service.ApplyCartDiscounts(c.Request().Context(), userID, membership, req.Codes, token); err != nil {
+		return toOrderError(c, err)
+	}
+
+	return h.GetCart(c)
+}
+
+// RemoveCartDiscount godoc
+// @Summary Remove the discount codes from the cart
+// @Tags cart
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} types.CartResponseModel
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /cart/discount [delete]
+func (h *Handler) RemoveCartDiscount(c echo.Context) error {
+	userID, _ := c.Get("userId").(string)
+	if err := h.service.RemoveCartDiscounts(c.Request().Context(), userID); err != nil {
+		return toOrderError(c, err)
+	}
+
+	return h.GetCart(c)
+}
+
+// Checkout godoc
+// @Summary Check out the cart
+// @Description Creates an order from the cart items and discounts and empties the cart
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param checkout body types.CheckoutRequestModel true "Shipping and billing address"
+// @Success 201 {object} types.OrderWithCustomerResponse
+// @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 403 {object} errorPackage.AppError "Email address not verified"
+// @Failure 404 {object} errorPackage.AppError "Customer or product not found"
+// @Failure 409 {object} errorPackage.AppError "Insufficient stock"
+// @Failure 422 {object} errorPackage.AppError "Empty cart, invalid address or unavailable product"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /cart/checkout [post]
+func (h *Handler) Checkout(c echo.Context) error {
+	var req types.CheckoutRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidCartBody)
+	}
+
+	userID, _ := c.Get("userId").(string)
+	token := c.Request().Header.Get("Authorization")
+
+	id, err := h.service.Checkout(c.Request().Context(), userID, &req, token, newAuditInfo(c, "checkout"))
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	order, err := h.service.GetByID(c.Request().Context(), id, token)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, order)
+}
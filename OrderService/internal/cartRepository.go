@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// checkoutClaimTimeout is how long a checkout holds the cart. A claim older
+// than this belongs to a checkout that died half way and may be taken over.
+const checkoutClaimTimeout = 5 * time.Minute
+
+type CartRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCartRepository(collection *mongo.Collection) *CartRepository {
+	return &CartRepository{collection: collection}
+}
+
+// Get returns the cart of a customer. A customer who never added anything gets
+// an empty cart rather than an error.
+func (r *CartRepository) Get(ctx context.Context, customerID string) (*types.Cart, error) {
+	var cart types.Cart
+	err := r.collection.FindOne(ctx, bson.M{"_id": customerID}).Decode(&cart)
+	if err == mongo.ErrNoDocuments {
+		return &types.Cart{CustomerId: customerID, Items: []types.CartItem{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// AddItem increases the quantity of a product already in the cart, or appends
+// it as a new line. The cart document is created on the first add.
+func (r *CartRepository) AddItem(ctx context.Context, customerID string, productID string, quantity int) error {
+	for {
+		now := time.Now()
+
+		res, err := r.collection.UpdateOne(ctx,
+			bson.M{"_id": customerID, "items.product_id": productID},
+			bson.M{
+				"$inc": bson.M{"items.$.quantity": quantity},
+				"$set": bson.M{"updated_at": now},
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount > 0 {
+			return nil
+		}
+
+		_, err = r.collection.UpdateOne(ctx,
+			bson.M{"_id": customerID, "items.product_id": bson.M{"$ne": productID}},
+			bson.M{
+				"$push":        bson.M{"items": types.CartItem{ProductId: productID, Quantity: quantity, AddedAt: now}},
+				"$set":         bson.M{"updated_at": now},
+				"$setOnInsert": bson.M{"created_at": now},
+			},
+			options.Update().SetUpsert(true),
+		)
+		if mongo.IsDuplicateKeyError(err) {
+			// The product was added by a concurrent request between the two
+			// updates, so the upsert collided with the existing cart. Retry
+			// the increment.
+			continue
+		}
+		return err
+	}
+}
+
+func (r *CartRepository) SetQuantity(ctx context.Context, customerID string, productID string, quantity int) error {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": customerID, "items.product_id": productID},
+		bson.M{"$set": bson.M{
+			"items.$.quantity": quantity,
+			"updated_at":       time.Now(),
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return customError.NewNotFound(customError.CartItemNotFound)
+	}
+	return nil
+}
+
+func (r *CartRepository) RemoveItem(ctx context.Context, customerID string, productID string) error {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": customerID, "items.product_id": productID},
+		bson.M{
+			"$pull": bson.M{"items": bson.M{"product_id": productID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return customError.NewNotFound(customError.CartItemNotFound)
+	}
+	return nil
+}
+
+func (r *CartRepository) SetDiscountCodes(ctx context.Context, customerID string, codes []string) error {
+	now := time.Now()
+	update := bson.M{
+		"$set":         bson.M{"discount_codes": codes, "updated_at": now},
+		"$setOnInsert": bson.M{"items": []types.CartItem{}, "created_at": now},
+	}
+	if len(codes) == 0 {
+		update = bson.M{
+			"$unset":       bson.M{"discount_codes": ""},
+			"$set":         bson.M{"updated_at": now},
+			"$setOnInsert": bson.M{"items": []types.CartItem{}, "created_at": now},
+		}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": customerID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// Claim marks the cart as being checked out and returns it as claimed, so two
+// concurrent checkouts of the same cart cannot both turn it into an order.
+// The claim is identified by its time and is ended by Clear or Unclaim.
+func (r *CartRepository) Claim(ctx context.Context, customerID string, now time.Time) (*types.Cart, error) {
+	filter := bson.M{
+		"_id": customerID,
+		"$or": bson.A{
+			bson.M{"checkout_at": bson.M{"$exists": false}},
+			bson.M{"checkout_at": bson.M{"$lt": now.Add(-checkoutClaimTimeout)}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"checkout_at": now}}
+
+	var cart types.Cart
+	err := r.collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&cart)
+	if err == mongo.ErrNoDocuments {
+		return nil, customError.NewConflict(customError.CartCheckoutInProgress)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// Unclaim gives the cart back to the customer after a failed checkout.
+func (r *CartRepository) Unclaim(ctx context.Context, customerID string, claimedAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": customerID, "checkout_at": claimedAt},
+		bson.M{"$unset": bson.M{"checkout_at": ""}},
+	)
+	return err
+}
+
+// Clear removes the cart checked out under the given claim.
+func (r *CartRepository) Clear(ctx context.Context, customerID string, claimedAt time.Time) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": customerID, "checkout_at": claimedAt})
+	return err
+}
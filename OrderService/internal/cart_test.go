@@ -0,0 +1,169 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/client"
+	"tesodev-korpes/pkg/customError"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const checkoutProductID = "0b6f4c7e-2f4a-4d7e-9a57-3c1d2e4f5a6b"
+
+// checkoutServices stands in for the customer and product services. reserve,
+// when set, answers the stock reservation instead of the default 200.
+type checkoutServices struct {
+	reserve  func(w http.ResponseWriter)
+	reserved int
+}
+
+func (f *checkoutServices) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/customer/customer-1":
+		_ = json.NewEncoder(w).Encode(types.CustomerResponseModel{Id: "customer-1"})
+	case "/product/lookup":
+		_ = json.NewEncoder(w).Encode(types.ProductLookupResponse{Data: []types.ProductResponseModel{
+			{Id: checkoutProductID, Name: "Kettle", Price: 25, IsActive: true},
+		}})
+	case "/product/stock/reserve":
+		f.reserved++
+		if f.reserve != nil {
+			f.reserve(w)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	case "/product/stock/release":
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func checkoutTestCart(checkoutAt *time.Time) *types.Cart {
+	return &types.Cart{
+		CustomerId: "customer-1",
+		Items:      []types.CartItem{{ProductId: checkoutProductID, Quantity: 2}},
+		CheckoutAt: checkoutAt,
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	address := types.Address{City: "Istanbul", State: "IST", ZipCode: "34000"}
+	valid := &types.CheckoutRequestModel{ShippingAddress: address, BillingAddress: address}
+	held := time.Now().Add(-time.Minute)
+	stale := time.Now().Add(-2 * checkoutClaimTimeout)
+
+	tests := []struct {
+		name        string
+		cart        *types.Cart
+		req         *types.CheckoutRequestModel
+		reserve     func(w http.ResponseWriter)
+		wantErr     customError.ErrorKey
+		wantOrder   bool
+		wantCart    bool
+		wantClaimed *time.Time
+	}{
+		{"order placed clears the cart", checkoutTestCart(nil), valid, nil, "", true, false, nil},
+		{"stale claim is taken over", checkoutTestCart(&stale), valid, nil, "", true, false, nil},
+		{"invalid request gives the cart back", checkoutTestCart(nil), &types.CheckoutRequestModel{}, nil,
+			customError.InvalidAddressFormat, false, true, nil},
+		{"failed order gives the cart back", checkoutTestCart(nil), valid, func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusConflict)
+		}, customError.InsufficientStock, false, true, nil},
+		{"cart held by another checkout", checkoutTestCart(&held), valid, nil,
+			customError.CartCheckoutInProgress, false, true, &held},
+		{"empty cart", nil, valid, nil, customError.EmptyCart, false, false, nil},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, "tesodev.tax_rule", mtest.FirstBatch))
+			services := &checkoutServices{reserve: tt.reserve}
+			server := httptest.NewServer(services)
+			defer server.Close()
+
+			var carts *memoryCarts
+			if tt.cart != nil {
+				carts = newMemoryCarts(tt.cart)
+			} else {
+				carts = newMemoryCarts()
+			}
+			orders := newMemoryOrders()
+			s := &Service{
+				repo:          orders,
+				cartRepo:      carts,
+				taxRepo:       NewTaxRepository(mt.Coll),
+				client:        client.New(server.URL, time.Second),
+				productClient: client.New(server.URL, time.Second),
+			}
+
+			id, err := s.Checkout(context.Background(), "customer-1", tt.req, "Bearer token", types.AuditInfo{ActorId: "customer-1"})
+			expectError(mt.T, err, tt.wantErr)
+
+			if tt.wantOrder {
+				if _, ok := orders.orders[id]; !ok || len(orders.orders) != 1 {
+					mt.Fatalf("%d orders stored, want order %q alone", len(orders.orders), id)
+				}
+			} else if len(orders.orders) != 0 {
+				mt.Fatalf("%d orders stored, want none", len(orders.orders))
+			}
+			cart := carts.cart("customer-1")
+			if (cart != nil) != tt.wantCart {
+				mt.Fatalf("cart = %+v, want kept = %v", cart, tt.wantCart)
+			}
+			if cart != nil {
+				if len(cart.Items) != 1 {
+					mt.Fatalf("cart items = %+v, want them kept", cart.Items)
+				}
+				if claimed := cart.CheckoutAt; (claimed == nil) != (tt.wantClaimed == nil) || (claimed != nil && !claimed.Equal(*tt.wantClaimed)) {
+					mt.Fatalf("cart claimed at %v, want %v", claimed, tt.wantClaimed)
+				}
+			}
+		})
+	}
+
+	mt.Run("no second checkout while the order is placed", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "tesodev.tax_rule", mtest.FirstBatch))
+		services := &checkoutServices{}
+		server := httptest.NewServer(services)
+		defer server.Close()
+
+		carts := newMemoryCarts(checkoutTestCart(nil))
+		orders := newMemoryOrders()
+		s := &Service{
+			repo:          orders,
+			cartRepo:      carts,
+			taxRepo:       NewTaxRepository(mt.Coll),
+			client:        client.New(server.URL, time.Second),
+			productClient: client.New(server.URL, time.Second),
+		}
+		// The handler runs on the server's goroutine, so it reports with
+		// Errorf rather than failing the test from there.
+		services.reserve = func(w http.ResponseWriter) {
+			_, err := s.Checkout(context.Background(), "customer-1", valid, "Bearer token", types.AuditInfo{})
+			var appErr *customError.AppError
+			if !errors.As(err, &appErr) || appErr.Code != customError.ErrorDefinitions[customError.CartCheckoutInProgress].TypeCode {
+				mt.Errorf("second Checkout error = %v, want CartCheckoutInProgress", err)
+			}
+			_, _ = w.Write([]byte(`{}`))
+		}
+
+		if _, err := s.Checkout(context.Background(), "customer-1", valid, "Bearer token", types.AuditInfo{}); err != nil {
+			mt.Fatalf("Checkout error = %v", err)
+		}
+		if len(orders.orders) != 1 || services.reserved != 1 {
+			mt.Fatalf("%d orders placed and %d reservations made, want 1 and 1", len(orders.orders), services.reserved)
+		}
+		if cart := carts.cart("customer-1"); cart != nil {
+			mt.Fatalf("cart = %+v, want it cleared", cart)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/money"
+	"tesodev-korpes/pkg/pagination"
+	"time"
+)
+
+func (s *Service) CreateCoupon(ctx context.Context, req *types.CreateCouponRequestModel) (*types.CouponResponseModel, error) {
+	coupon := FromCreateCouponRequest(req)
+	if err := coupon.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.couponRepo.Create(ctx, coupon); err != nil {
+		return nil, err
+	}
+	return ToCouponResponse(coupon), nil
+}
+
+func (s *Service) GetCoupon(ctx context.Context, code string) (*types.CouponResponseModel, error) {
+	coupon, err := s.couponRepo.GetByCode(ctx, normalizeCouponCode(code))
+	if err != nil {
+		return nil, err
+	}
+	return ToCouponResponse(coupon), nil
+}
+
+func (s *Service) UpdateCoupon(ctx context.Context, code string, req *types.UpdateCouponRequestModel) (*types.CouponResponseModel, error) {
+	coupon, err := s.couponRepo.GetByCode(ctx, normalizeCouponCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	FromUpdateCouponRequest(req, coupon)
+	if err := coupon.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.couponRepo.Update(ctx, coupon); err != nil {
+		return nil, err
+	}
+	return s.GetCoupon(ctx, coupon.Code)
+}
+
+func (s *Service) DeleteCoupon(ctx context.Context, code string) error {
+	return s.couponRepo.Delete(ctx, normalizeCouponCode(code))
+}
+
+func (s *Service) GetCoupons(ctx context.Context, params pagination.Params) (*pagination.Page[*types.CouponResponseModel], error) {
+	coupons, err := s.couponRepo.Get(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	coupons, next := pagination.Trim(coupons, params, couponListField, couponListDesc, func(coupon types.Coupon) (interface{}, string) {
+		return coupon.CreatedAt, coupon.Code
+	})
+
+	page := &pagination.Page[*types.CouponResponseModel]{
+		Data:       make([]*types.CouponResponseModel, 0, len(coupons)),
+		NextCursor: next,
+	}
+	for i := range coupons {
+		page.Data = append(page.Data, ToCouponResponse(&coupons[i]))
+	}
+
+	if params.WithTotal {
+		total, err := s.couponRepo.Count(ctx)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+// resolveCoupons checks the requested codes against the coupon collection for
+// the given customer and basket, and returns the discounts to snapshot on the
+// order. Usage is only checked here; it is counted by redeemCoupons.
+func (s *Service) resolveCoupons(ctx context.Context, codes []string, customerID string, membership string, total money.Money) ([]*types.Discount, error) {
+	codes = normalizeCouponCodes(codes)
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	coupons, err := s.couponRepo.GetByCodes(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string]*types.Coupon, len(coupons))
+	for i := range coupons {
+		byCode[coupons[i].Code] = &coupons[i]
+	}
+
+	now := time.Now()
+	discounts := make([]*types.Discount, 0, len(codes))
+	for _, code := range codes {
+		coupon, ok := byCode[code]
+		if !ok {
+			return nil, customError.NewNotFound(customError.CouponNotFound)
+		}
+		if err := checkCoupon(coupon, membership, total, len(codes) > 1, now); err != nil {
+			return nil, err
+		}
+		if coupon.PerCustomerLimit > 0 {
+			used, err := s.couponRepo.CustomerRedemptions(ctx, code, customerID)
+			if err != nil {
+				return nil, err
+			}
+			if used >= coupon.PerCustomerLimit {
+				return nil, customError.NewUnprocessableEntity(customError.CouponCustomerLimitReached, nil)
+			}
+		}
+
+		discounts = append(discounts, ToDiscountSnapshot(coupon))
+	}
+	return discounts, nil
+}
+
+// checkCoupon checks everything about a coupon that does not depend on the
+// customer's earlier orders: its dates, membership, currency, minimum basket,
+// stacking and overall usage limit.
+func checkCoupon(coupon *types.Coupon, membership string, total money.Money, stacked bool, now time.Time) error {
+	if !coupon.IsActive || now.Before(coupon.StartDate) || now.After(coupon.EndDate) {
+		return customError.NewUnprocessableEntity(customError.CouponNotApplicable, nil)
+	}
+	if coupon.Role != "" && coupon.Role != membership {
+		return customError.NewUnprocessableEntity(customError.CouponNotApplicable, nil)
+	}
+	if coupon.Type == config.DiscountType.FixedAmount && coupon.Amount.Currency != total.Currency {
+		return customError.NewUnprocessableEntity(customError.CouponNotApplicable, nil)
+	}
+	if !coupon.MinBasket.IsZero() {
+		if coupon.MinBasket.Currency != total.Currency {
+			return customError.NewUnprocessableEntity(customError.CouponNotApplicable, nil)
+		}
+		if total.LessThan(coupon.MinBasket) {
+			return customError.NewUnprocessableEntity(customError.CouponBelowMinBasket, nil)
+		}
+	}
+	if stacked && !coupon.Stackable {
+		return customError.NewUnprocessableEntity(customError.CouponNotStackable, nil)
+	}
+	if coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit {
+		return customError.NewUnprocessableEntity(customError.CouponUsageLimitReached, nil)
+	}
+	return nil
+}
+
+// redeemCoupons counts the use of every discount on the order. If one of them
+// cannot be redeemed, the ones already taken are given back.
+func (s *Service) redeemCoupons(ctx context.Context, order *types.Order) error {
+	for i, d := range order.Discounts {
+		coupon, err := s.couponRepo.GetByCode(ctx, d.DiscountCode)
+		if err == nil {
+			err = s.couponRepo.Redeem(ctx, coupon, order.CustomerId, order.Id)
+		}
+		if err != nil {
+			for _, taken := range order.Discounts[:i] {
+				_ = s.couponRepo.Release(ctx, taken.DiscountCode, order.CustomerId, order.Id)
+			}
+			var appErr *customError.AppError
+			if errors.As(err, &appErr) {
+				return err
+			}
+			return customError.NewUnprocessableEntity(customError.CouponNotApplicable, nil)
+		}
+	}
+	return nil
+}
+
+func (s *Service) releaseCoupons(ctx context.Context, order *types.Order) error {
+	for _, d := range order.Discounts {
+		if d == nil || d.DiscountCode == "" {
+			continue
+		}
+		if err := s.couponRepo.Release(ctx, d.DiscountCode, order.CustomerId, order.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// normalizeCouponCodes upper-cases the codes and drops duplicates, keeping the
+// order in which they were given since discounts are applied in that order.
+func normalizeCouponCodes(codes []string) []string {
+	seen := make(map[string]bool, len(codes))
+	result := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = normalizeCouponCode(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		result = append(result, code)
+	}
+	return result
+}
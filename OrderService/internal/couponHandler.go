@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/pagination"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreateCoupon godoc
+// @Summary Create a discount code
+// @Description Define a discount code with its value, validity period, usage limits, minimum basket and stacking rule
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param coupon body types.CreateCouponRequestModel true "Discount code to create"
+// @Success 201 {object} types.CouponResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid request body or code"
+// @Failure 409 {object} errorPackage.AppError "Code already exists"
+// @Failure 422 {object} errorPackage.AppError "Invalid discount definition"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /coupon [post]
+func (h *Handler) CreateCoupon(c echo.Context) error {
+	var req types.CreateCouponRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidCouponBody)
+	}
+
+	if err := req.CreateValidate(); err != nil {
+		return err
+	}
+
+	coupon, err := h.service.CreateCoupon(c.Request().Context(), &req)
+	if err != nil {
+		return toCouponError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, coupon)
+}
+
+// GetCoupon godoc
+// @Summary Get a discount code
+// @Tags coupons
+// @Produce json
+// @Security ApiKeyAuth
+// @Param code path string true "Discount code"
+// @Success 200 {object} types.CouponResponseModel
+// @Failure 404 {object} errorPackage.AppError "Discount code not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /coupon/{code} [get]
+func (h *Handler) GetCoupon(c echo.Context) error {
+	coupon, err := h.service.GetCoupon(c.Request().Context(), c.Param("code"))
+	if err != nil {
+		return toCouponError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, coupon)
+}
+
+// UpdateCoupon godoc
+// @Summary Update a discount code
+// @Description Change the terms of a discount code. Orders already placed keep the terms they were placed with.
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param code path string true "Discount code"
+// @Param coupon body types.UpdateCouponRequestModel true "Fields to update"
+// @Success 200 {object} types.CouponResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 404 {object} errorPackage.AppError "Discount code not found"
+// @Failure 422 {object} errorPackage.AppError "Invalid discount definition"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /coupon/{code} [put]
+func (h *Handler) UpdateCoupon(c echo.Context) error {
+	var req types.UpdateCouponRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidCouponBody)
+	}
+
+	coupon, err := h.service.UpdateCoupon(c.Request().Context(), c.Param("code"), &req)
+	if err != nil {
+		return toCouponError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, coupon)
+}
+
+// DeleteCoupon godoc
+// @Summary Delete a discount code
+// @Tags coupons
+// @Security ApiKeyAuth
+// @Param code path string true "Discount code"
+// @Success 204 "No Content"
+// @Failure 404 {object} errorPackage.AppError "Discount code not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /coupon/{code} [delete]
+func (h *Handler) DeleteCoupon(c echo.Context) error {
+	if err := h.service.DeleteCoupon(c.Request().Context(), c.Param("code")); err != nil {
+		return toCouponError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetCoupons godoc
+// @Summary List discount codes with pagination
+// @Tags coupons
+// @Produce json
+// @Security ApiKeyAuth
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Number of items per page (at most 100)" default(10)
+// @Param total query bool false "Also count all discount codes"
+// @Success 200 {object} map[string]interface{} "Returns data, next_cursor and, if asked for, total"
+// @Failure 400 {object} errorPackage.AppError "Invalid cursor"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /coupon/list [get]
+func (h *Handler) GetCoupons(c echo.Context) error {
+	params, err := pagination.ParseParams(c.QueryParam("limit"), c.QueryParam("cursor"), c.QueryParam("total"))
+	if err != nil {
+		return err
+	}
+
+	page, err := h.service.GetCoupons(c.Request().Context(), params)
+	if err != nil {
+		return toCouponError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+func toCouponError(c echo.Context, err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return customError.NewNotFound(customError.CouponNotFound)
+	}
+	return toOrderError(c, err)
+}
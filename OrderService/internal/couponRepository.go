@@ -0,0 +1,248 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/pagination"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CouponRepository struct {
+	collection           *mongo.Collection
+	redemptionCollection *mongo.Collection
+}
+
+// Coupons are listed newest first, then by _id.
+const (
+	couponListField = "created_at"
+	couponListDesc  = true
+)
+
+func NewCouponRepository(collection *mongo.Collection, redemptionCollection *mongo.Collection) *CouponRepository {
+	return &CouponRepository{
+		collection:           collection,
+		redemptionCollection: redemptionCollection,
+	}
+}
+
+func (r *CouponRepository) Create(ctx context.Context, coupon *types.Coupon) error {
+	_, err := r.collection.InsertOne(ctx, coupon)
+	if mongo.IsDuplicateKeyError(err) {
+		return customError.NewConflict(customError.CouponAlreadyExists)
+	}
+	return err
+}
+
+func (r *CouponRepository) GetByCode(ctx context.Context, code string) (*types.Coupon, error) {
+	var coupon types.Coupon
+	err := r.collection.FindOne(ctx, bson.M{"_id": code}).Decode(&coupon)
+	if err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+func (r *CouponRepository) GetByCodes(ctx context.Context, codes []string) ([]types.Coupon, error) {
+	var coupons []types.Coupon
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": codes}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &coupons); err != nil {
+		return nil, err
+	}
+
+	return coupons, nil
+}
+
+// Update replaces the definition of a coupon. The usage counter is left alone
+// since it is only ever changed by Redeem and Release.
+func (r *CouponRepository) Update(ctx context.Context, coupon *types.Coupon) error {
+	filter := bson.M{"_id": coupon.Code}
+	update := bson.M{
+		"$set": bson.M{
+			"description":        coupon.Description,
+			"type":               coupon.Type,
+			"value":              coupon.Value,
+			"amount":             coupon.Amount,
+			"role":               coupon.Role,
+			"start_date":         coupon.StartDate,
+			"end_date":           coupon.EndDate,
+			"min_basket":         coupon.MinBasket,
+			"usage_limit":        coupon.UsageLimit,
+			"per_customer_limit": coupon.PerCustomerLimit,
+			"stackable":          coupon.Stackable,
+			"is_active":          coupon.IsActive,
+			"updated_at":         time.Now(),
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *CouponRepository) Delete(ctx context.Context, code string) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": code})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Get returns a page of coupons, newest first, plus the first item of the next
+// page if there is one.
+func (r *CouponRepository) Get(ctx context.Context, params pagination.Params) ([]types.Coupon, error) {
+	var coupons []types.Coupon
+
+	filter, err := pagination.After(bson.M{}, couponListField, couponListDesc, params.After)
+	if err != nil {
+		return nil, err
+	}
+	findOptions := options.Find().
+		SetSort(pagination.Sort(couponListField, couponListDesc)).
+		SetLimit(int64(params.Limit + 1))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &coupons); err != nil {
+		return nil, err
+	}
+
+	return coupons, nil
+}
+
+func (r *CouponRepository) Count(ctx context.Context) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{})
+}
+
+// CustomerRedemptions returns how many orders of the customer used the coupon.
+func (r *CouponRepository) CustomerRedemptions(ctx context.Context, code string, customerID string) (int, error) {
+	var redemption types.CouponRedemption
+	err := r.redemptionCollection.FindOne(ctx, bson.M{"_id": redemptionID(code, customerID)}).Decode(&redemption)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return redemption.Count, nil
+}
+
+// Redeem counts one use of the coupon by the order. Both the global usage limit
+// and the per-customer limit are enforced by the update filters, so concurrent
+// orders cannot go over either of them. Redeeming again for an order that
+// already holds a use of the coupon does nothing, so a retry succeeds.
+func (r *CouponRepository) Redeem(ctx context.Context, coupon *types.Coupon, customerID string, orderID string) error {
+	redeemed, err := r.redeemedBy(ctx, coupon.Code, customerID, orderID)
+	if err != nil || redeemed {
+		return err
+	}
+
+	filter := bson.M{
+		"_id":       coupon.Code,
+		"is_active": true,
+		"$or": bson.A{
+			bson.M{"usage_limit": bson.M{"$lte": 0}},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$used_count", "$usage_limit"}}},
+		},
+	}
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"used_count": 1}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return customError.NewUnprocessableEntity(customError.CouponUsageLimitReached, nil)
+	}
+
+	redemptionFilter := bson.M{
+		"_id":       redemptionID(coupon.Code, customerID),
+		"order_ids": bson.M{"$ne": orderID},
+	}
+	if coupon.PerCustomerLimit > 0 {
+		redemptionFilter["count"] = bson.M{"$lt": coupon.PerCustomerLimit}
+	}
+	_, err = r.redemptionCollection.UpdateOne(ctx, redemptionFilter,
+		bson.M{
+			"$inc":         bson.M{"count": 1},
+			"$push":        bson.M{"order_ids": orderID},
+			"$setOnInsert": bson.M{"code": coupon.Code, "customer_id": customerID},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err == nil {
+		return nil
+	}
+
+	_, _ = r.collection.UpdateOne(ctx, bson.M{"_id": coupon.Code}, bson.M{"$inc": bson.M{"used_count": -1}})
+	if mongo.IsDuplicateKeyError(err) {
+		// The redemption exists but did not match the filter: either a
+		// concurrent retry redeemed it for this order in the meantime, or the
+		// customer is already at the limit.
+		if redeemed, _ := r.redeemedBy(ctx, coupon.Code, customerID, orderID); redeemed {
+			return nil
+		}
+		return customError.NewUnprocessableEntity(customError.CouponCustomerLimitReached, nil)
+	}
+	return err
+}
+
+// redeemedBy reports whether the order already holds a use of the coupon.
+func (r *CouponRepository) redeemedBy(ctx context.Context, code string, customerID string, orderID string) (bool, error) {
+	err := r.redemptionCollection.FindOne(ctx,
+		bson.M{"_id": redemptionID(code, customerID), "order_ids": orderID},
+		options.FindOne().SetProjection(bson.M{"_id": 1}),
+	).Err()
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Release gives back the use of the coupon taken by the order. It does nothing
+// if the order never redeemed the coupon or already released it.
+func (r *CouponRepository) Release(ctx context.Context, code string, customerID string, orderID string) error {
+	res, err := r.redemptionCollection.UpdateOne(ctx,
+		bson.M{"_id": redemptionID(code, customerID), "order_ids": orderID},
+		bson.M{
+			"$inc":  bson.M{"count": -1},
+			"$pull": bson.M{"order_ids": orderID},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return nil
+	}
+
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"_id": code, "used_count": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"used_count": -1}},
+	)
+	return err
+}
+
+func redemptionID(code string, customerID string) string {
+	return code + ":" + customerID
+}
@@ -0,0 +1,225 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/money"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// expectError fails the test unless err is the AppError defined for key, or
+// nil when key is empty.
+func expectError(t *testing.T, err error, key customError.ErrorKey) {
+	t.Helper()
+	if key == "" {
+		if err != nil {
+			t.Fatalf("error = %v, want nil", err)
+		}
+		return
+	}
+
+	var appErr *customError.AppError
+	if !errors.As(err, &appErr) || appErr.Code != customError.ErrorDefinitions[key].TypeCode {
+		t.Fatalf("error = %v, want %s", err, key)
+	}
+}
+
+func TestCheckCoupon(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	basket := money.New(10000, "TRY")
+
+	valid := func() *types.Coupon {
+		return &types.Coupon{
+			Code:      "SPRING",
+			Type:      config.DiscountType.Percentage,
+			Value:     10,
+			StartDate: now.Add(-time.Hour),
+			EndDate:   now.Add(time.Hour),
+			IsActive:  true,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		edit       func(c *types.Coupon)
+		membership string
+		stacked    bool
+		want       customError.ErrorKey
+	}{
+		{"applies", func(c *types.Coupon) {}, "", false, ""},
+		{"inactive", func(c *types.Coupon) { c.IsActive = false }, "", false, customError.CouponNotApplicable},
+		{"not started", func(c *types.Coupon) { c.StartDate = now.Add(time.Minute) }, "", false, customError.CouponNotApplicable},
+		{"expired", func(c *types.Coupon) { c.EndDate = now.Add(-time.Minute) }, "", false, customError.CouponNotApplicable},
+		{"membership matches", func(c *types.Coupon) { c.Role = "premium" }, "premium", false, ""},
+		{"other membership", func(c *types.Coupon) { c.Role = "premium" }, "non-premium", false, customError.CouponNotApplicable},
+		{"fixed amount in basket currency", func(c *types.Coupon) {
+			c.Type, c.Amount = config.DiscountType.FixedAmount, money.New(500, "TRY")
+		}, "", false, ""},
+		{"fixed amount in other currency", func(c *types.Coupon) {
+			c.Type, c.Amount = config.DiscountType.FixedAmount, money.New(500, "USD")
+		}, "", false, customError.CouponNotApplicable},
+		{"basket reaches minimum", func(c *types.Coupon) { c.MinBasket = money.New(10000, "TRY") }, "", false, ""},
+		{"basket below minimum", func(c *types.Coupon) { c.MinBasket = money.New(10001, "TRY") }, "", false, customError.CouponBelowMinBasket},
+		{"minimum in other currency", func(c *types.Coupon) { c.MinBasket = money.New(100, "USD") }, "", false, customError.CouponNotApplicable},
+		{"stacked and stackable", func(c *types.Coupon) { c.Stackable = true }, "", true, ""},
+		{"stacked but not stackable", func(c *types.Coupon) {}, "", true, customError.CouponNotStackable},
+		{"usage left", func(c *types.Coupon) { c.UsageLimit, c.UsedCount = 3, 2 }, "", false, ""},
+		{"usage limit reached", func(c *types.Coupon) { c.UsageLimit, c.UsedCount = 3, 3 }, "", false, customError.CouponUsageLimitReached},
+		{"no usage limit", func(c *types.Coupon) { c.UsedCount = 1000 }, "", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coupon := valid()
+			tt.edit(coupon)
+			expectError(t, checkCoupon(coupon, tt.membership, basket, tt.stacked, now), tt.want)
+		})
+	}
+}
+
+func TestNormalizeCouponCodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		codes []string
+		want  []string
+	}{
+		{"none", nil, []string{}},
+		{"upper-cased and trimmed", []string{" spring10 "}, []string{"SPRING10"}},
+		{"duplicates dropped in order", []string{"b", "A", "B", "a"}, []string{"B", "A"}},
+		{"blank codes dropped", []string{"", "  ", "x"}, []string{"X"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeCouponCodes(tt.codes); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("normalizeCouponCodes(%q) = %q, want %q", tt.codes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedeemCoupons(t *testing.T) {
+	coupons := func() *memoryCoupons {
+		return newMemoryCoupons(
+			&types.Coupon{Code: "SPRING", UsageLimit: 10, IsActive: true},
+			&types.Coupon{Code: "WELCOME", UsageLimit: 1, PerCustomerLimit: 1, IsActive: true},
+		)
+	}
+	order := &types.Order{
+		Id:         "order-1",
+		CustomerId: "customer-1",
+		Discounts:  []*types.Discount{{DiscountCode: "SPRING"}, {DiscountCode: "WELCOME"}},
+	}
+
+	tests := []struct {
+		name        string
+		setup       func(m *memoryCoupons)
+		wantErr     customError.ErrorKey
+		wantSpring  int
+		wantWelcome int
+	}{
+		{"both redeemed", func(*memoryCoupons) {}, "", 1, 1},
+		{"second used up gives back the first", func(m *memoryCoupons) {
+			m.coupons["WELCOME"].UsedCount = 1
+		}, customError.CouponUsageLimitReached, 0, 1},
+		{"second at the customer limit gives back the first", func(m *memoryCoupons) {
+			m.coupons["WELCOME"].UsageLimit = 0
+			m.redemptions["WELCOME:customer-1"] = []string{"order-0"}
+		}, customError.CouponCustomerLimitReached, 0, 0},
+		{"unknown coupon gives back the first", func(m *memoryCoupons) {
+			delete(m.coupons, "WELCOME")
+		}, customError.CouponNotApplicable, 0, 0},
+		{"retry for the same order counts once", func(m *memoryCoupons) {
+			m.coupons["SPRING"].UsedCount = 1
+			m.coupons["WELCOME"].UsedCount = 1
+			m.redemptions["SPRING:customer-1"] = []string{"order-1"}
+			m.redemptions["WELCOME:customer-1"] = []string{"order-1"}
+		}, "", 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := coupons()
+			tt.setup(store)
+			s := &Service{couponRepo: store}
+
+			expectError(t, s.redeemCoupons(context.Background(), order), tt.wantErr)
+
+			if got := store.coupons["SPRING"].UsedCount; got != tt.wantSpring {
+				t.Fatalf("SPRING used %d times, want %d", got, tt.wantSpring)
+			}
+			if welcome, ok := store.coupons["WELCOME"]; ok && welcome.UsedCount != tt.wantWelcome {
+				t.Fatalf("WELCOME used %d times, want %d", welcome.UsedCount, tt.wantWelcome)
+			}
+			if tt.wantErr != "" && slices.Contains(store.redemptions["SPRING:customer-1"], "order-1") {
+				t.Fatalf("SPRING still redeemed by the order after the failure")
+			}
+		})
+	}
+}
+
+func TestReleaseCoupons(t *testing.T) {
+	store := newMemoryCoupons(&types.Coupon{Code: "SPRING", UsedCount: 2, IsActive: true})
+	store.redemptions["SPRING:customer-1"] = []string{"order-0", "order-1"}
+	s := &Service{couponRepo: store}
+	order := &types.Order{Id: "order-1", CustomerId: "customer-1", Discounts: []*types.Discount{{DiscountCode: "SPRING"}, nil}}
+
+	for i := 0; i < 2; i++ {
+		if err := s.releaseCoupons(context.Background(), order); err != nil {
+			t.Fatalf("releaseCoupons error = %v", err)
+		}
+	}
+	if got := store.coupons["SPRING"].UsedCount; got != 1 {
+		t.Fatalf("SPRING used %d times after releasing twice, want 1", got)
+	}
+	if got := store.redemptions["SPRING:customer-1"]; !reflect.DeepEqual(got, []string{"order-0"}) {
+		t.Fatalf("redemptions = %v, want [order-0]", got)
+	}
+}
+
+func TestCouponRepositoryRedeem(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	coupon := &types.Coupon{Code: "SPRING", PerCustomerLimit: 1, IsActive: true}
+	held := mtest.CreateCursorResponse(0, "tesodev.coupon_redemption", mtest.FirstBatch, bson.D{{Key: "_id", Value: "SPRING:customer-1"}})
+	notHeld := mtest.CreateCursorResponse(0, "tesodev.coupon_redemption", mtest.FirstBatch)
+	updated := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1})
+	duplicate := mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "duplicate key"})
+
+	tests := []struct {
+		name      string
+		responses []bson.D
+		wantErr   customError.ErrorKey
+		wantCmds  []string
+	}{
+		{"first redemption", []bson.D{notHeld, updated, updated}, "", []string{"find", "update", "update"}},
+		{"retry for the same order", []bson.D{held}, "", []string{"find"}},
+		{"retry racing the first attempt", []bson.D{notHeld, updated, duplicate, updated, held}, "", []string{"find", "update", "update", "update", "find"}},
+		{"customer at the limit", []bson.D{notHeld, updated, duplicate, updated, notHeld}, customError.CouponCustomerLimitReached, []string{"find", "update", "update", "update", "find"}},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.responses...)
+			repo := NewCouponRepository(mt.Coll, mt.Coll)
+
+			expectError(mt.T, repo.Redeem(context.Background(), coupon, "customer-1", "order-1"), tt.wantErr)
+
+			var cmds []string
+			for _, event := range mt.GetAllStartedEvents() {
+				cmds = append(cmds, event.CommandName)
+			}
+			if !reflect.DeepEqual(cmds, tt.wantCmds) {
+				mt.Fatalf("commands = %v, want %v", cmds, tt.wantCmds)
+			}
+		})
+	}
+}
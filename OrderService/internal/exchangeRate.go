@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/money"
+	"tesodev-korpes/pkg/pagination"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func (s *Service) CreateExchangeRate(ctx context.Context, req *types.CreateExchangeRateRequestModel, audit types.AuditInfo) (*types.ExchangeRateResponseModel, error) {
+	rate := FromCreateExchangeRateRequest(req, audit.ActorId)
+	if err := s.rateRepo.Create(ctx, rate); err != nil {
+		return nil, err
+	}
+	return ToExchangeRateResponse(rate), nil
+}
+
+// GetExchangeRates lists rates newest first, optionally for one currency pair.
+func (s *Service) GetExchangeRates(ctx context.Context, from string, to string, params pagination.Params) (*pagination.Page[*types.ExchangeRateResponseModel], error) {
+	filter := bson.M{}
+	if from != "" {
+		filter["from"] = from
+	}
+	if to != "" {
+		filter["to"] = to
+	}
+
+	rates, err := s.rateRepo.Get(ctx, filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, next := pagination.Trim(rates, params, exchangeRateListField, exchangeRateListDesc, func(rate types.ExchangeRate) (interface{}, string) {
+		return rate.EffectiveFrom, rate.Id
+	})
+
+	page := &pagination.Page[*types.ExchangeRateResponseModel]{
+		Data:       make([]*types.ExchangeRateResponseModel, 0, len(rates)),
+		NextCursor: next,
+	}
+	for i := range rates {
+		page.Data = append(page.Data, ToExchangeRateResponse(&rates[i]))
+	}
+
+	if params.WithTotal {
+		total, err := s.rateRepo.Count(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+func (s *Service) DeleteExchangeRate(ctx context.Context, id string) error {
+	return s.rateRepo.Delete(ctx, id)
+}
+
+// convertPrice converts a price breakdown at the rate in effect at the given
+// time. The original and final prices are converted and the discount is their
+// difference, so the breakdown still adds up after rounding.
+func (s *Service) convertPrice(ctx context.Context, price *types.FinalPriceResult, currency string, at time.Time) (*types.FinalPriceResult, error) {
+	from := price.FinalPrice.Currency
+	if currency == "" || currency == from {
+		return price, nil
+	}
+
+	rate, conversion, err := s.effectiveRate(ctx, from, currency, at)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := *price
+	converted.OriginalPrice = price.OriginalPrice.Convert(rate, currency)
+	converted.FinalPrice = price.FinalPrice.Convert(rate, currency)
+	converted.TaxLines = make([]types.TaxLine, len(price.TaxLines))
+	for i, line := range price.TaxLines {
+		line.TaxableAmount = line.TaxableAmount.Convert(rate, currency)
+		line.Amount = line.Amount.Convert(rate, currency)
+		converted.TaxLines[i] = line
+	}
+	converted.TaxTotal = sumTaxes(converted.TaxLines, currency)
+	converted.ShippingCost = price.ShippingCost.Convert(rate, currency)
+	converted.DiscountApplied = converted.OriginalPrice.Add(converted.TaxTotal).Add(converted.ShippingCost).Sub(converted.FinalPrice)
+	converted.Conversion = conversion
+	return &converted, nil
+}
+
+// effectiveRate finds the rate between two currencies at the given time. A
+// rate defined only in the other direction is inverted. A time before the
+// pair's first rate gets that first rate, whichever direction it was defined
+// in, so orders placed before a currency was priced can still be converted.
+// No rate can take effect in the past, so the rate picked never changes.
+func (s *Service) effectiveRate(ctx context.Context, from string, to string, at time.Time) (*big.Rat, *types.PriceConversion, error) {
+	stored, inverse, err := s.rateAt(ctx, from, to, at)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		stored, inverse, err = s.firstRate(ctx, from, to)
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil, customError.NewNotFound(customError.ExchangeRateNotFound)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rate, ok := money.ParseRate(stored.Rate)
+	if !ok {
+		return nil, nil, customError.NewNotFound(customError.ExchangeRateNotFound)
+	}
+	if inverse {
+		rate.Inv(rate)
+	}
+
+	return rate, &types.PriceConversion{
+		From:          from,
+		To:            to,
+		Rate:          rate.FloatString(10),
+		EffectiveFrom: stored.EffectiveFrom,
+	}, nil
+}
+
+// rateAt returns the rate in effect at the given time, and whether it was
+// found in the other direction.
+func (s *Service) rateAt(ctx context.Context, from string, to string, at time.Time) (*types.ExchangeRate, bool, error) {
+	stored, err := s.rateRepo.GetEffective(ctx, from, to, at)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		stored, err = s.rateRepo.GetEffective(ctx, to, from, at)
+		return stored, true, err
+	}
+	return stored, false, err
+}
+
+// firstRate returns the earlier of the first rates in either direction, and
+// whether it is the one in the other direction.
+func (s *Service) firstRate(ctx context.Context, from string, to string) (*types.ExchangeRate, bool, error) {
+	now := time.Now()
+	direct, err := s.rateRepo.GetFirst(ctx, from, to, now)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, false, err
+	}
+	inverse, err := s.rateRepo.GetFirst(ctx, to, from, now)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, false, err
+	}
+
+	switch {
+	case direct == nil && inverse == nil:
+		return nil, false, mongo.ErrNoDocuments
+	case inverse == nil || (direct != nil && !inverse.EffectiveFrom.Before(direct.EffectiveFrom)):
+		return direct, false, nil
+	}
+	return inverse, true, nil
+}
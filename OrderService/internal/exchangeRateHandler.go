@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/money"
+	"tesodev-korpes/pkg/pagination"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreateExchangeRate godoc
+// @Summary Add an exchange rate
+// @Description Add the rate of one currency in another, effective from the given time (now by default, never in the past). Rates are never edited; a new rate replaces the previous one from its effective time on.
+// @Tags exchange-rates
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param rate body types.CreateExchangeRateRequestModel true "Exchange rate"
+// @Success 201 {object} types.ExchangeRateResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 422 {object} errorPackage.AppError "Invalid currency, rate or effective time"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /exchange-rate [post]
+func (h *Handler) CreateExchangeRate(c echo.Context) error {
+	var req types.CreateExchangeRateRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidExchangeRateBody)
+	}
+
+	if err := req.CreateValidate(); err != nil {
+		return err
+	}
+
+	rate, err := h.service.CreateExchangeRate(c.Request().Context(), &req, newAuditInfo(c, ""))
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, rate)
+}
+
+// GetExchangeRates godoc
+// @Summary List exchange rates
+// @Description List exchange rates newest first, optionally for one currency pair
+// @Tags exchange-rates
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string false "Source currency"
+// @Param to query string false "Target currency"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Number of items per page (at most 100)" default(10)
+// @Param total query bool false "Also count all matching exchange rates"
+// @Success 200 {object} map[string]interface{} "Returns data, next_cursor and, if asked for, total"
+// @Failure 400 {object} errorPackage.AppError "Invalid currency or cursor"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /exchange-rate/list [get]
+func (h *Handler) GetExchangeRates(c echo.Context) error {
+	params, err := pagination.ParseParams(c.QueryParam("limit"), c.QueryParam("cursor"), c.QueryParam("total"))
+	if err != nil {
+		return err
+	}
+
+	from, ok := money.ParseCurrency(c.QueryParam("from"))
+	if c.QueryParam("from") != "" && !ok {
+		return customError.NewBadRequest(customError.InvalidCurrency)
+	}
+	to, ok := money.ParseCurrency(c.QueryParam("to"))
+	if c.QueryParam("to") != "" && !ok {
+		return customError.NewBadRequest(customError.InvalidCurrency)
+	}
+
+	page, err := h.service.GetExchangeRates(c.Request().Context(), from, to, params)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// DeleteExchangeRate godoc
+// @Summary Delete a scheduled exchange rate
+// @Description Delete a rate that has not taken effect yet. Rates already in effect may have priced orders and cannot be deleted.
+// @Tags exchange-rates
+// @Security ApiKeyAuth
+// @Param id path string true "Exchange rate ID (UUID)"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "No scheduled exchange rate with this ID"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /exchange-rate/{id} [delete]
+func (h *Handler) DeleteExchangeRate(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidExchangeRateID)
+	}
+
+	if err := h.service.DeleteExchangeRate(c.Request().Context(), id); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return customError.NewNotFound(customError.ExchangeRateNotFound)
+		}
+		return toOrderError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
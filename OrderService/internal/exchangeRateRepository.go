@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/pagination"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ExchangeRateRepository struct {
+	collection *mongo.Collection
+}
+
+// Exchange rates are listed latest effective first, then by _id.
+const (
+	exchangeRateListField = "effective_from"
+	exchangeRateListDesc  = true
+)
+
+func NewExchangeRateRepository(collection *mongo.Collection) *ExchangeRateRepository {
+	return &ExchangeRateRepository{collection: collection}
+}
+
+func (r *ExchangeRateRepository) Create(ctx context.Context, rate *types.ExchangeRate) error {
+	_, err := r.collection.InsertOne(ctx, rate)
+	return err
+}
+
+// GetEffective returns the rate from one currency to another that was in
+// effect at the given time, i.e. the latest one that took effect before it.
+func (r *ExchangeRateRepository) GetEffective(ctx context.Context, from string, to string, at time.Time) (*types.ExchangeRate, error) {
+	filter := bson.M{
+		"from":           from,
+		"to":             to,
+		"effective_from": bson.M{"$lte": at},
+	}
+	opts := options.FindOne().SetSort(bson.D{
+		{Key: "effective_from", Value: -1},
+		{Key: "created_at", Value: -1},
+	})
+
+	var rate types.ExchangeRate
+	if err := r.collection.FindOne(ctx, filter, opts).Decode(&rate); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// GetFirst returns the first rate from one currency to another that had
+// taken effect by the given time: of the rates that took effect earliest, the
+// one GetEffective would return at that moment.
+func (r *ExchangeRateRepository) GetFirst(ctx context.Context, from string, to string, by time.Time) (*types.ExchangeRate, error) {
+	filter := bson.M{
+		"from":           from,
+		"to":             to,
+		"effective_from": bson.M{"$lte": by},
+	}
+	opts := options.FindOne().SetSort(bson.D{
+		{Key: "effective_from", Value: 1},
+		{Key: "created_at", Value: -1},
+	})
+
+	var rate types.ExchangeRate
+	if err := r.collection.FindOne(ctx, filter, opts).Decode(&rate); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// Get returns a page of the rates matching filter, latest effective first, plus the first item of the next
+// page if there is one.
+func (r *ExchangeRateRepository) Get(ctx context.Context, filter bson.M, params pagination.Params) ([]types.ExchangeRate, error) {
+	var rates []types.ExchangeRate
+
+	filter, err := pagination.After(filter, exchangeRateListField, exchangeRateListDesc, params.After)
+	if err != nil {
+		return nil, err
+	}
+	findOptions := options.Find().
+		SetSort(pagination.Sort(exchangeRateListField, exchangeRateListDesc)).
+		SetLimit(int64(params.Limit + 1))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &rates); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *ExchangeRateRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+// Delete removes a rate that has not taken effect yet. Rates already in effect
+// may have been used to price orders and are kept.
+func (r *ExchangeRateRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{
+		"_id":            id,
+		"effective_from": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestFromCreateExchangeRateRequestEffectiveFrom(t *testing.T) {
+	future := time.Now().Add(time.Hour).Truncate(time.Second)
+	justPassed := time.Now().Add(-time.Millisecond)
+
+	tests := []struct {
+		name          string
+		effectiveFrom *time.Time
+		scheduled     bool
+	}{
+		{"not given", nil, false},
+		{"in the future", &future, true},
+		{"passed since it was validated", &justPassed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &types.CreateExchangeRateRequestModel{From: "usd", To: "try", Rate: " 32.45 ", EffectiveFrom: tt.effectiveFrom}
+			rate := FromCreateExchangeRateRequest(req, "admin-1")
+
+			if rate.From != "USD" || rate.To != "TRY" || rate.Rate != "32.45" {
+				t.Fatalf("rate = %s/%s %q, want USD/TRY \"32.45\"", rate.From, rate.To, rate.Rate)
+			}
+			if tt.scheduled && !rate.EffectiveFrom.Equal(future) {
+				t.Fatalf("EffectiveFrom = %v, want %v", rate.EffectiveFrom, future)
+			}
+			if !tt.scheduled && !rate.EffectiveFrom.Equal(rate.CreatedAt) {
+				t.Fatalf("EffectiveFrom = %v, want the creation time %v", rate.EffectiveFrom, rate.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestEffectiveRate(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ordered := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first := ordered.AddDate(0, 1, 0)
+
+	found := func(from, to, rate string, effectiveFrom time.Time) bson.D {
+		return mtest.CreateCursorResponse(0, "tesodev.exchange_rate", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: from + to + rate},
+			{Key: "from", Value: from},
+			{Key: "to", Value: to},
+			{Key: "rate", Value: rate},
+			{Key: "effective_from", Value: effectiveFrom},
+		})
+	}
+	none := mtest.CreateCursorResponse(0, "tesodev.exchange_rate", mtest.FirstBatch)
+
+	// Lookups in order: in effect TRY->USD, in effect USD->TRY, first
+	// TRY->USD, first USD->TRY.
+	tests := []struct {
+		name      string
+		responses []bson.D
+		wantRate  string
+		wantFrom  time.Time
+		wantErr   customError.ErrorKey
+	}{
+		{"in effect", []bson.D{found("TRY", "USD", "0.03125", ordered.AddDate(0, -1, 0))}, "0.0312500000", ordered.AddDate(0, -1, 0), ""},
+		{"in effect the other way", []bson.D{none, found("USD", "TRY", "32", ordered.AddDate(0, -1, 0))}, "0.0312500000", ordered.AddDate(0, -1, 0), ""},
+		{"order before the first rate", []bson.D{none, none, none, found("USD", "TRY", "32", first)}, "0.0312500000", first, ""},
+		{"earlier of the first rates", []bson.D{none, none, found("TRY", "USD", "0.025", first.AddDate(0, 1, 0)), found("USD", "TRY", "32", first)}, "0.0312500000", first, ""},
+		{"no rate at all", []bson.D{none, none, none, none}, "", time.Time{}, customError.ExchangeRateNotFound},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.responses...)
+			s := &Service{rateRepo: NewExchangeRateRepository(mt.Coll)}
+
+			rate, conversion, err := s.effectiveRate(context.Background(), "TRY", "USD", ordered)
+			expectError(mt.T, err, tt.wantErr)
+			if tt.wantErr != "" {
+				return
+			}
+			if got := rate.FloatString(10); got != tt.wantRate || !conversion.EffectiveFrom.Equal(tt.wantFrom) {
+				mt.Fatalf("effectiveRate = %s from %v, want %s from %v", got, conversion.EffectiveFrom, tt.wantRate, tt.wantFrom)
+			}
+		})
+	}
+}
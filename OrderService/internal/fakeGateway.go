@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"tesodev-korpes/pkg/money"
+)
+
+// Payment tokens understood by FakeGateway. Any other non-empty token is approved.
+const (
+	FakeTokenDecline      = "tok_decline"
+	FakeTokenCaptureFails = "tok_capture_fails"
+)
+
+// FakeGateway is an in-process PaymentGateway for local development and tests.
+// It is deterministic: the same calls always produce the same references and
+// outcomes, and it keeps just enough state to reject captures and refunds that
+// exceed what was authorized or captured.
+type FakeGateway struct {
+	mu             sync.Mutex
+	authorizations map[string]*fakeAuthorization
+	captures       map[string]*fakeCapture
+}
+
+type fakeAuthorization struct {
+	amount   money.Money
+	token    string
+	captured bool
+	voided   bool
+}
+
+type fakeCapture struct {
+	amount   money.Money
+	refunded money.Money
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		authorizations: map[string]*fakeAuthorization{},
+		captures:       map[string]*fakeCapture{},
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) Authorize(_ context.Context, req AuthorizeRequest) (*GatewayResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ref := fakeReference("auth", req.OrderId, req.PaymentToken, req.Amount)
+	if req.PaymentToken == FakeTokenDecline {
+		return &GatewayResult{Approved: false, Reference: ref, Message: "card declined"}, nil
+	}
+	if !req.Amount.IsPositive() {
+		return &GatewayResult{Approved: false, Reference: ref, Message: "invalid amount"}, nil
+	}
+
+	g.authorizations[ref] = &fakeAuthorization{amount: req.Amount, token: req.PaymentToken}
+	return &GatewayResult{Approved: true, Reference: ref, Message: "authorized"}, nil
+}
+
+func (g *FakeGateway) Capture(_ context.Context, authorizationID string, amount money.Money) (*GatewayResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ref := fakeReference("capture", authorizationID, "", amount)
+	auth, ok := g.authorizations[authorizationID]
+	switch {
+	case !ok:
+		return &GatewayResult{Approved: false, Reference: ref, Message: "unknown authorization"}, nil
+	case auth.voided:
+		return &GatewayResult{Approved: false, Reference: ref, Message: "authorization voided"}, nil
+	case auth.captured:
+		return &GatewayResult{Approved: false, Reference: ref, Message: "authorization already captured"}, nil
+	case amount.Currency != auth.amount.Currency:
+		return &GatewayResult{Approved: false, Reference: ref, Message: "currency does not match authorization"}, nil
+	case auth.amount.LessThan(amount):
+		return &GatewayResult{Approved: false, Reference: ref, Message: "amount exceeds authorization"}, nil
+	case auth.token == FakeTokenCaptureFails:
+		return &GatewayResult{Approved: false, Reference: ref, Message: "capture rejected"}, nil
+	}
+
+	auth.captured = true
+	g.captures[ref] = &fakeCapture{amount: amount}
+	return &GatewayResult{Approved: true, Reference: ref, Message: "captured"}, nil
+}
+
+func (g *FakeGateway) Refund(_ context.Context, captureID string, amount money.Money) (*GatewayResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	capture, ok := g.captures[captureID]
+	ref := fakeReference("refund", captureID, "", amount)
+	if ok {
+		// Several partial refunds of the same amount must get distinct references.
+		ref = fakeReference("refund", captureID, capture.refunded.String(), amount)
+	}
+	switch {
+	case !ok:
+		return &GatewayResult{Approved: false, Reference: ref, Message: "unknown capture"}, nil
+	case amount.Currency != capture.amount.Currency:
+		return &GatewayResult{Approved: false, Reference: ref, Message: "currency does not match capture"}, nil
+	case !amount.IsPositive() || capture.amount.LessThan(capture.refunded.Add(amount)):
+		return &GatewayResult{Approved: false, Reference: ref, Message: "amount exceeds captured amount"}, nil
+	}
+
+	capture.refunded = capture.refunded.Add(amount)
+	return &GatewayResult{Approved: true, Reference: ref, Message: "refunded"}, nil
+}
+
+func (g *FakeGateway) Void(_ context.Context, authorizationID string) (*GatewayResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ref := fakeReference("void", authorizationID, "", money.Money{})
+	auth, ok := g.authorizations[authorizationID]
+	switch {
+	case !ok:
+		return &GatewayResult{Approved: false, Reference: ref, Message: "unknown authorization"}, nil
+	case auth.captured:
+		return &GatewayResult{Approved: false, Reference: ref, Message: "authorization already captured"}, nil
+	}
+
+	auth.voided = true
+	return &GatewayResult{Approved: true, Reference: ref, Message: "voided"}, nil
+}
+
+func fakeReference(kind, subject, extra string, amount money.Money) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s", kind, subject, extra, amount)))
+	return "fake_" + kind + "_" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/money"
+	"testing"
+)
+
+func TestFakeGatewayCapture(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		amount   money.Money
+		void     bool
+		captures []money.Money
+		want     []bool
+	}{
+		{"full amount", "tok_ok", money.New(1000, "TRY"), false, []money.Money{money.New(1000, "TRY")}, []bool{true}},
+		{"less than authorized", "tok_ok", money.New(1000, "TRY"), false, []money.Money{money.New(600, "TRY")}, []bool{true}},
+		{"more than authorized", "tok_ok", money.New(1000, "TRY"), false, []money.Money{money.New(1001, "TRY")}, []bool{false}},
+		{"other currency", "tok_ok", money.New(1000, "TRY"), false, []money.Money{money.New(1000, "USD")}, []bool{false}},
+		{"only once", "tok_ok", money.New(1000, "TRY"), false, []money.Money{money.New(500, "TRY"), money.New(500, "TRY")}, []bool{true, false}},
+		{"after void", "tok_ok", money.New(1000, "TRY"), true, []money.Money{money.New(1000, "TRY")}, []bool{false}},
+		{"rejected by the card", FakeTokenCaptureFails, money.New(1000, "TRY"), false, []money.Money{money.New(1000, "TRY")}, []bool{false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			gateway := NewFakeGateway()
+
+			auth, err := gateway.Authorize(ctx, AuthorizeRequest{OrderId: "order-1", Amount: tt.amount, PaymentToken: tt.token})
+			if err != nil || !auth.Approved {
+				t.Fatalf("Authorize = %+v, %v, want approved", auth, err)
+			}
+			if tt.void {
+				if res, _ := gateway.Void(ctx, auth.Reference); !res.Approved {
+					t.Fatalf("Void = %+v, want approved", res)
+				}
+			}
+
+			for i, amount := range tt.captures {
+				res, err := gateway.Capture(ctx, auth.Reference, amount)
+				if err != nil {
+					t.Fatalf("Capture(%s) error = %v", amount, err)
+				}
+				if res.Approved != tt.want[i] {
+					t.Fatalf("Capture(%s) approved = %v (%s), want %v", amount, res.Approved, res.Message, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFakeGatewayAuthorize(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		amount money.Money
+		want   bool
+	}{
+		{"approved", "tok_ok", money.New(1000, "TRY"), true},
+		{"declined card", FakeTokenDecline, money.New(1000, "TRY"), false},
+		{"zero amount", "tok_ok", money.New(0, "TRY"), false},
+		{"negative amount", "tok_ok", money.New(-1, "TRY"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := NewFakeGateway().Authorize(context.Background(), AuthorizeRequest{OrderId: "order-1", Amount: tt.amount, PaymentToken: tt.token})
+			if err != nil {
+				t.Fatalf("Authorize error = %v", err)
+			}
+			if res.Approved != tt.want {
+				t.Fatalf("Authorize approved = %v (%s), want %v", res.Approved, res.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestFakeGatewayRefund(t *testing.T) {
+	tests := []struct {
+		name    string
+		refunds []money.Money
+		want    []bool
+	}{
+		{"full refund", []money.Money{money.New(1000, "TRY")}, []bool{true}},
+		{"partial refunds up to the capture", []money.Money{money.New(400, "TRY"), money.New(400, "TRY"), money.New(200, "TRY")}, []bool{true, true, true}},
+		{"more than captured", []money.Money{money.New(1001, "TRY")}, []bool{false}},
+		{"more than what is left", []money.Money{money.New(700, "TRY"), money.New(400, "TRY")}, []bool{true, false}},
+		{"zero amount", []money.Money{money.New(0, "TRY")}, []bool{false}},
+		{"other currency", []money.Money{money.New(100, "USD")}, []bool{false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			gateway := NewFakeGateway()
+			auth, _ := gateway.Authorize(ctx, AuthorizeRequest{OrderId: "order-1", Amount: money.New(1000, "TRY"), PaymentToken: "tok_ok"})
+			capture, _ := gateway.Capture(ctx, auth.Reference, money.New(1000, "TRY"))
+			if !capture.Approved {
+				t.Fatalf("Capture = %+v, want approved", capture)
+			}
+
+			refs := map[string]bool{}
+			for i, amount := range tt.refunds {
+				res, err := gateway.Refund(ctx, capture.Reference, amount)
+				if err != nil {
+					t.Fatalf("Refund(%s) error = %v", amount, err)
+				}
+				if res.Approved != tt.want[i] {
+					t.Fatalf("Refund(%s) approved = %v (%s), want %v", amount, res.Approved, res.Message, tt.want[i])
+				}
+				if res.Approved {
+					if refs[res.Reference] {
+						t.Fatalf("Refund(%s) reused reference %s", amount, res.Reference)
+					}
+					refs[res.Reference] = true
+				}
+			}
+		})
+	}
+}
+
+func TestFakeGatewayVoidAfterCapture(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway()
+	auth, _ := gateway.Authorize(ctx, AuthorizeRequest{OrderId: "order-1", Amount: money.New(1000, "TRY"), PaymentToken: "tok_ok"})
+	gateway.Capture(ctx, auth.Reference, money.New(1000, "TRY"))
+
+	if res, _ := gateway.Void(ctx, auth.Reference); res.Approved {
+		t.Fatalf("Void after capture approved, want rejected")
+	}
+}
+
+func TestPayableAmount(t *testing.T) {
+	tests := []struct {
+		name  string
+		order *types.Order
+		want  money.Money
+	}{
+		{
+			name:  "stored price",
+			order: &types.Order{TotalPrice: money.New(1000, "TRY"), Price: &types.FinalPriceResult{FinalPrice: money.New(850, "TRY")}},
+			want:  money.New(850, "TRY"),
+		},
+		{
+			name:  "order from before prices were stored",
+			order: &types.Order{TotalPrice: money.New(1000, "TRY")},
+			want:  money.New(1000, "TRY"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := payableAmount(tt.order); got != tt.want {
+				t.Fatalf("payableAmount = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
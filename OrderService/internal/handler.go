@@ -5,10 +5,17 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"tesodev-korpes/pkg/customError"
+	"time"
 
 	"tesodev-korpes/OrderService/internal/types"
 	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/auth"
+	"tesodev-korpes/pkg/middleware"
+	"tesodev-korpes/pkg/money"
+	"tesodev-korpes/pkg/pagination"
+	"tesodev-korpes/shared/config"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -27,7 +34,7 @@ type Handler struct {
 	validate *validator.Validate
 }
 
-func NewHandler(e *echo.Echo, service *Service, clientMongo *mongo.Client) *Handler {
+func NewHandler(e *echo.Echo, service *Service, denylist *auth.Denylist, statuses *auth.UserStatusCache) *Handler {
 	validate := validator.New()
 
 	handler := &Handler{
@@ -35,26 +42,78 @@ func NewHandler(e *echo.Echo, service *Service, clientMongo *mongo.Client) *Hand
 		validate: validate,
 	}
 
-	g := e.Group("/order")
-	g.POST("", handler.Create)
+	e.Use(middleware.Authentication(denylist, statuses, middleware.PublicRoute(&config.Cfg)))
+	e.Use(middleware.AuthorizationMiddleware(&config.Cfg))
+
+	g := e.Group("/order", middleware.Ownership("id", service.OrderOwner))
+	g.POST("", handler.Create, middleware.RequireVerifiedEmail(statuses))
 	g.GET("/:id", handler.GetByID)
+	g.GET("/:id/history", handler.GetStatusHistory)
+	g.POST("/:id/reprice", handler.RepriceOrder)
+	g.GET("/:id/payment", handler.GetPayment)
+	g.POST("/:id/payment/authorize", handler.AuthorizePayment)
+	g.POST("/:id/payment/capture", handler.CapturePayment)
+	g.GET("/:id/returns", handler.ListReturns)
+	g.POST("/:id/returns", handler.OpenReturn)
+	g.PATCH("/:id/returns/:returnId/approve", handler.ApproveReturn)
+	g.PATCH("/:id/returns/:returnId/reject", handler.RejectReturn)
 	g.PATCH("/:id/ship", handler.ShipOrder)
 	g.PATCH("/:id/deliver", handler.DeliverOrder)
 	g.DELETE("/cancel/:id", handler.CancelOrder)
 	g.GET("/list", handler.GetAllOrders)
+	g.GET("/mine", handler.GetMyOrders)
+
+	cart := e.Group("/cart")
+	cart.GET("", handler.GetCart)
+	cart.POST("/items", handler.AddCartItem)
+	cart.PUT("/items/:productId", handler.UpdateCartItem)
+	cart.DELETE("/items/:productId", handler.RemoveCartItem)
+	cart.PUT("/discount", handler.ApplyCartDiscount)
+	cart.DELETE("/discount", handler.RemoveCartDiscount)
+	cart.POST("/checkout", handler.Checkout, middleware.RequireVerifiedEmail(statuses))
+
+	coupon := e.Group("/coupon")
+	coupon.POST("", handler.CreateCoupon)
+	coupon.GET("/list", handler.GetCoupons)
+	coupon.GET("/:code", handler.GetCoupon)
+	coupon.PUT("/:code", handler.UpdateCoupon)
+	coupon.DELETE("/:code", handler.DeleteCoupon)
+
+	rates := e.Group("/exchange-rate")
+	rates.POST("", handler.CreateExchangeRate)
+	rates.GET("/list", handler.GetExchangeRates)
+	rates.DELETE("/:id", handler.DeleteExchangeRate)
+
+	shipping := e.Group("/shipping-method")
+	shipping.POST("", handler.CreateShippingMethod)
+	shipping.GET("/list", handler.GetShippingMethods)
+	shipping.GET("/:code", handler.GetShippingMethod)
+	shipping.PUT("/:code", handler.UpdateShippingMethod)
+	shipping.DELETE("/:code", handler.DeleteShippingMethod)
+
+	taxes := e.Group("/tax-rule")
+	taxes.POST("", handler.CreateTaxRule)
+	taxes.GET("/list", handler.GetTaxRules)
+	taxes.GET("/:id", handler.GetTaxRule)
+	taxes.PUT("/:id", handler.UpdateTaxRule)
+	taxes.DELETE("/:id", handler.DeleteTaxRule)
 
 	return handler
 }
 
 // Create godoc
 // @Summary Create a new order
-// @Description Create a new order with the given data
+// @Description Create a new order with the given data. Customers always order for themselves; customer_id is only honoured for staff.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param order body types.CreateOrderRequestModel true "Order to create"
 // @Success 201 {object} types.OrderResponseModel "Returns created order details"
 // @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 403 {object} errorPackage.AppError "Email address not verified"
+// @Failure 404 {object} errorPackage.AppError "Customer or product not found"
+// @Failure 409 {object} errorPackage.AppError "Insufficient stock"
+// @Failure 422 {object} errorPackage.AppError "Invalid order item or unavailable product"
 // @Failure 500 {object} errorPackage.AppError "Internal server error"
 // @Router /orders [post]
 func (h *Handler) Create(c echo.Context) error {
@@ -62,16 +121,30 @@ func (h *Handler) Create(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return customError.NewBadRequest(customError.InvalidOrderBody)
 	}
+	// Customers can only order for themselves; staff may order for anyone.
+	if !middleware.IsStaff(c) {
+		req.CustomerId, _ = c.Get("userId").(string)
+	}
+
+	if err := req.CreateValidate(); err != nil {
+		return err
+	}
 
 	token := c.Request().Header.Get("Authorization") // ← kullanıcının JWT’si
 
 	order := FromCreateOrderRequest(&req)
 
-	createdID, err := h.service.Create(c.Request().Context(), order, token) // ← token eklendi
+	createdID, err := h.service.Create(c.Request().Context(), order, token, newAuditInfo(c, "")) // ← token eklendi
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return customError.NewNotFound(customError.CustomerNotFound)
 		}
+
+		var appErr *customError.AppError
+		if errors.As(err, &appErr) {
+			return err
+		}
+
 		return customError.NewInternal(customError.OrderServiceError, err)
 	}
 	fmt.Println(createdID)
@@ -124,12 +197,16 @@ func (h *Handler) GetByID(c echo.Context) error {
 
 // ShipOrder godoc
 // @Summary Ship an order
-// @Description Mark an order as shipped by its ID
+// @Description Mark an order as shipped by its ID and store the carrier and tracking number. Shipping a shipped order again only retries committing its stock.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param id path string true "Order ID"
+// @Param shipment body types.ShipOrderRequestModel true "Carrier, tracking number and an optional reason recorded in the status history"
 // @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format or request body"
+// @Failure 422 {object} errorPackage.AppError "Carrier or tracking number missing"
+// @Failure 409 {object} errorPackage.AppError "Payment not captured or invalid order state"
 // @Failure 500 {object} errorPackage.AppError "Internal server error"
 // @Router /orders/{id}/ship [put]
 func (h *Handler) ShipOrder(c echo.Context) error {
@@ -137,7 +214,16 @@ func (h *Handler) ShipOrder(c echo.Context) error {
 	if !pkg.IsValidUUID(id) {
 		return customError.NewBadRequest(customError.InvalidOrderID)
 	}
-	err := h.service.ShipOrder(c.Request().Context(), id)
+
+	var req types.ShipOrderRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidOrderBody)
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	err := h.service.ShipOrder(c.Request().Context(), id, &req, newAuditInfo(c, req.Reason))
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return customError.NewNotFound(customError.OrderNotFound)
@@ -145,10 +231,7 @@ func (h *Handler) ShipOrder(c echo.Context) error {
 
 		var appErr *customError.AppError
 		if errors.As(err, &appErr) {
-			if appErr.Code == customError.ErrorDefinitions[customError.OrderStatusConflict].TypeCode {
-				return err
-			}
-
+			return err
 		}
 
 		return customError.NewInternal(customError.OrderServiceError, err)
@@ -164,6 +247,7 @@ func (h *Handler) ShipOrder(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Order ID"
+// @Param reason body types.StatusChangeRequestModel false "Optional reason recorded in the status history"
 // @Success 200 {object} map[string]string "Order delivered successfully"
 // @Failure 400 {object} errorPackage.AppError "Invalid ID format"
 // @Failure 404 {object} errorPackage.AppError "Order not found"
@@ -177,7 +261,12 @@ func (h *Handler) DeliverOrder(c echo.Context) error {
 		return customError.NewBadRequest(customError.InvalidOrderID)
 	}
 
-	err := h.service.DeliverOrder(c.Request().Context(), id)
+	reason, err := bindReason(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.service.DeliverOrder(c.Request().Context(), id, newAuditInfo(c, reason))
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return customError.NewNotFound(customError.OrderNotFound)
@@ -185,10 +274,7 @@ func (h *Handler) DeliverOrder(c echo.Context) error {
 
 		var appErr *customError.AppError
 		if errors.As(err, &appErr) {
-			if appErr.Code == customError.ErrorDefinitions[customError.OrderStatusConflict].TypeCode {
-				return err
-			}
-
+			return err
 		}
 
 		return customError.NewInternal(customError.OrderServiceError, err)
@@ -199,11 +285,12 @@ func (h *Handler) DeliverOrder(c echo.Context) error {
 
 // CancelOrder godoc
 // @Summary Cancel an order
-// @Description Cancel an order by its ID.
+// @Description Cancel an order by its ID and release its stock, coupons and payment. Canceling a canceled order again retries the releases that failed.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param id path string true "Order ID (UUID)"
+// @Param reason body types.StatusChangeRequestModel false "Optional reason recorded in the status history"
 // @Success 200 {object} map[string]string "Success message"
 // @Failure 400 {object} errorPackage.AppError "Invalid ID format"
 // @Failure 404 {object} errorPackage.AppError "Order not found"
@@ -216,7 +303,12 @@ func (h *Handler) CancelOrder(c echo.Context) error {
 		return customError.NewBadRequest(customError.InvalidOrderID)
 	}
 
-	err := h.service.CancelOrder(c.Request().Context(), id)
+	reason, err := bindReason(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.service.CancelOrder(c.Request().Context(), id, newAuditInfo(c, reason))
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return customError.NewNotFound(customError.OrderNotFound)
@@ -224,10 +316,7 @@ func (h *Handler) CancelOrder(c echo.Context) error {
 
 		var appErr *customError.AppError
 		if errors.As(err, &appErr) {
-			if appErr.Code == customError.ErrorDefinitions[customError.OrderStatusConflict].TypeCode {
-				return err
-			}
-
+			return err
 		}
 
 		customError.LogErrorWithCorrelation(err, correlationID)
@@ -237,6 +326,38 @@ func (h *Handler) CancelOrder(c echo.Context) error {
 	return c.JSON(http.StatusOK, echo.Map{"message": "Order cancelled successfully. "})
 }
 
+// GetStatusHistory godoc
+// @Summary Get the status history of an order
+// @Description Returns the append-only audit trail of status changes with actor, time, correlation id and reason
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} types.OrderHistoryResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Order not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /order/{id}/history [get]
+func (h *Handler) GetStatusHistory(c echo.Context) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidOrderID)
+	}
+
+	history, err := h.service.GetStatusHistory(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return customError.NewNotFound(customError.OrderNotFound)
+		}
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.OrderServiceError, err)
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
+
 // DeleteOrder godoc
 // @Summary Soft delete an order by ID
 // @Description Marks the order as deleted without removing it permanently.
@@ -279,44 +400,88 @@ func (h *Handler) DeleteOrder(c echo.Context) error {
 
 // GetAllOrders godoc
 // @Summary List all orders with pagination
-// @Description Retrieve a paginated list of all orders.
+// @Description Retrieve a paginated list of orders, filtered and sorted by the query parameters.
 // @Tags orders
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Number of items per page" default(10)
-// @Success 200 {object} map[string]interface{} "Returns list of orders with pagination"
+// @Param customer_id query string false "Only orders of this customer"
+// @Param status query string false "Comma-separated order statuses"
+// @Param product_id query string false "Only orders containing this product"
+// @Param q query string false "Case-insensitive search in product names"
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created at or before (RFC 3339)"
+// @Param min_total query number false "Minimum total price"
+// @Param max_total query number false "Maximum total price"
+// @Param sort query string false "Sort field (created_at, updated_at, total_price, status, customer_id), prefixed with - for descending" default(-created_at)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Number of items per page (at most 100)" default(10)
+// @Param total query bool false "Also count all matching orders"
+// @Success 200 {object} map[string]interface{} "Returns data, next_cursor and, if asked for, total"
+// @Failure 400 {object} errorPackage.AppError "Invalid filter, sort or cursor"
 // @Failure 404 {object} errorPackage.AppError "No orders found"
 // @Failure 500 {object} errorPackage.AppError "Internal server error"
 // @Router /orders [get]
 func (h *Handler) GetAllOrders(c echo.Context) error {
-	params := types.Pagination{
-		Page:  1,
-		Limit: 10,
+	params, err := pagination.ParseParams(c.QueryParam("limit"), c.QueryParam("cursor"), c.QueryParam("total"))
+	if err != nil {
+		return err
 	}
 
-	if p := c.QueryParam("page"); p != "" {
-		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
-			params.Page = pageInt
-		}
+	query, err := parseOrderListQuery(c)
+	if err != nil {
+		return err
 	}
 
-	if l := c.QueryParam("limit"); l != "" {
-		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 {
-			params.Limit = limitInt
-		}
+	page, err := h.service.GetAllOrders(c.Request().Context(), query, params)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// GetMyOrders godoc
+// @Summary List the caller's orders
+// @Description List the orders of the logged-in customer, with the filters, sort and pagination of /order/list. Admins and managers may pass customer_id to list the orders of any customer; for other users customer_id is always their own id.
+// @Tags orders
+// @Produce json
+// @Security ApiKeyAuth
+// @Param customer_id query string false "Customer whose orders to list (admins and managers only)"
+// @Param status query string false "Comma-separated order statuses"
+// @Param sort query string false "Sort field, prefixed with - for descending" default(-created_at)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Number of items per page (at most 100)" default(10)
+// @Param total query bool false "Also count all matching orders"
+// @Success 200 {object} map[string]interface{} "Returns data, next_cursor and, if asked for, total"
+// @Failure 400 {object} errorPackage.AppError "Invalid filter, sort or cursor"
+// @Failure 401 {object} errorPackage.AppError "Missing or invalid token"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /order/mine [get]
+func (h *Handler) GetMyOrders(c echo.Context) error {
+	userID, _ := c.Get("userId").(string)
+	if userID == "" {
+		return customError.NewUnauthorized(customError.MissingAuthToken)
 	}
 
-	orders, err := h.service.GetAllOrders(c.Request().Context(), params)
+	params, err := pagination.ParseParams(c.QueryParam("limit"), c.QueryParam("cursor"), c.QueryParam("total"))
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return customError.NewNotFound(customError.OrderNotFound)
-		}
-		return customError.NewInternal(customError.OrderServiceError, err)
+		return err
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": orders})
+	query, err := parseOrderListQuery(c)
+	if err != nil {
+		return err
+	}
+	if query.CustomerId == "" || !middleware.IsStaff(c) {
+		query.CustomerId = userID
+	}
+
+	page, err := h.service.GetAllOrders(c.Request().Context(), query, params)
+	if err != nil {
+		return toOrderError(c, err)
+	}
 
+	return c.JSON(http.StatusOK, page)
 }
 
 func (h *Handler) GetPremiumOrderPrice(c echo.Context) error {
@@ -329,17 +494,14 @@ func (h *Handler) GetPremiumOrderPrice(c echo.Context) error {
 		return customError.NewBadRequest(customError.InvalidOrderID)
 	}
 
-	result, err := h.service.CalculatePremiumFinalPrice(c.Request().Context(), orderID)
+	currency, err := parseCurrencyParam(c)
 	if err != nil {
+		return err
+	}
 
-		var appErr *customError.AppError
-		if errors.As(err, &appErr) {
-			if appErr.Code == customError.ErrorDefinitions[customError.OrderNotFound].TypeCode {
-				return err
-			}
-
-			return customError.NewInternal(customError.OrderServiceError, err)
-		}
+	result, err := h.service.CalculatePremiumFinalPrice(c.Request().Context(), orderID, currency)
+	if err != nil {
+		return toOrderError(c, err)
 	}
 	return c.JSON(http.StatusOK, result)
 }
@@ -354,18 +516,135 @@ func (h *Handler) GetNonPremiumOrderPrice(c echo.Context) error {
 		return customError.NewBadRequest(customError.InvalidOrderID)
 	}
 
-	result, err := h.service.CalculateNonPremiumFinalPrice(c.Request().Context(), orderID)
+	currency, err := parseCurrencyParam(c)
 	if err != nil {
+		return err
+	}
 
-		var appErr *customError.AppError
-		if errors.As(err, &appErr) {
-			if appErr.Code == customError.ErrorDefinitions[customError.OrderNotFound].TypeCode {
-				return err
+	result, err := h.service.CalculateNonPremiumFinalPrice(c.Request().Context(), orderID, currency)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// RepriceOrder godoc
+// @Summary Recalculate the stored price of an order
+// @Description Recalculates the price breakdown stored on the order. Before payment, item prices are refreshed from the catalog; orders placed before prices were stored get their price filled in.
+// @Tags orders
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} types.FinalPriceResult
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Order not found"
+// @Failure 409 {object} errorPackage.AppError "Order already paid or modified concurrently"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /order/{id}/reprice [post]
+func (h *Handler) RepriceOrder(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidOrderID)
+	}
+
+	token := c.Request().Header.Get("Authorization")
+	price, err := h.service.RepriceOrder(c.Request().Context(), id, token)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, price)
+}
+
+// parseCurrencyParam reads the optional ?currency= query parameter.
+func parseCurrencyParam(c echo.Context) (string, error) {
+	param := c.QueryParam("currency")
+	if param == "" {
+		return "", nil
+	}
+	currency, ok := money.ParseCurrency(param)
+	if !ok {
+		return "", customError.NewBadRequest(customError.InvalidCurrency)
+	}
+	return currency, nil
+}
+
+// parseOrderListQuery reads the filters and the sort of GET /order/list and
+// GET /order/mine.
+// Unparsable values are rejected rather than ignored, so a typo never turns
+// into an unfiltered list.
+func parseOrderListQuery(c echo.Context) (*types.OrderListQuery, error) {
+	query := &types.OrderListQuery{
+		CustomerId: strings.TrimSpace(c.QueryParam("customer_id")),
+		ProductId:  strings.TrimSpace(c.QueryParam("product_id")),
+		Search:     strings.TrimSpace(c.QueryParam("q")),
+		SortBy:     "created_at",
+		SortDesc:   true,
+	}
+
+	if statuses := c.QueryParam("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			query.Statuses = append(query.Statuses, strings.ToUpper(strings.TrimSpace(status)))
+		}
+	}
+
+	for param, target := range map[string]**time.Time{
+		"created_from": &query.CreatedFrom,
+		"created_to":   &query.CreatedTo,
+	} {
+		if value := c.QueryParam(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, customError.NewBadRequest(customError.InvalidOrderQuery)
 			}
+			*target = &t
+		}
+	}
 
-			return customError.NewInternal(customError.OrderServiceError, err)
+	for param, target := range map[string]**money.Money{
+		"min_total": &query.MinTotal,
+		"max_total": &query.MaxTotal,
+	} {
+		if value := c.QueryParam(param); value != "" {
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, customError.NewBadRequest(customError.InvalidOrderQuery)
+			}
+			m := money.FromMajor(amount, money.DefaultCurrency)
+			*target = &m
 		}
 	}
 
-	return c.JSON(http.StatusOK, result)
+	if sort := strings.TrimSpace(c.QueryParam("sort")); sort != "" {
+		query.SortDesc = strings.HasPrefix(sort, "-")
+		query.SortBy = strings.TrimPrefix(sort, "-")
+	}
+
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// bindReason reads the optional reason of a status change from the request body.
+func bindReason(c echo.Context) (string, error) {
+	var req types.StatusChangeRequestModel
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return "", customError.NewBadRequest(customError.InvalidOrderBody)
+		}
+	}
+	return req.Reason, nil
+}
+
+func newAuditInfo(c echo.Context, reason string) types.AuditInfo {
+	userID, _ := c.Get("userId").(string)
+	correlationID, _ := c.Get("CorrelationID").(string)
+
+	return types.AuditInfo{
+		ActorId:       userID,
+		CorrelationId: correlationID,
+		Reason:        reason,
+	}
 }
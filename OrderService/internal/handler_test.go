@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/money"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestParseOrderListQuery(t *testing.T) {
+	customerID := "0b5f9d3e-8c1a-4f7e-9a2b-3c4d5e6f7a8b"
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   string
+		check   func(t *testing.T, q *types.OrderListQuery)
+		wantErr bool
+	}{
+		{"defaults", "", func(t *testing.T, q *types.OrderListQuery) {
+			if q.SortBy != "created_at" || !q.SortDesc || q.Statuses != nil {
+				t.Fatalf("query = %+v, want newest first without filters", q)
+			}
+		}, false},
+		{"statuses normalized", "status=paid,%20Shipped", func(t *testing.T, q *types.OrderListQuery) {
+			if len(q.Statuses) != 2 || q.Statuses[0] != "PAID" || q.Statuses[1] != "SHIPPED" {
+				t.Fatalf("Statuses = %v, want [PAID SHIPPED]", q.Statuses)
+			}
+		}, false},
+		{"customer and search", "customer_id=" + customerID + "&q=%20lamp%20", func(t *testing.T, q *types.OrderListQuery) {
+			if q.CustomerId != customerID || q.Search != "lamp" {
+				t.Fatalf("query = %+v", q)
+			}
+		}, false},
+		{"date range", "created_from=2026-01-01T00:00:00Z&created_to=2026-02-01T00:00:00Z", func(t *testing.T, q *types.OrderListQuery) {
+			if q.CreatedFrom == nil || !q.CreatedFrom.Equal(from) || q.CreatedTo == nil {
+				t.Fatalf("CreatedFrom = %v, CreatedTo = %v", q.CreatedFrom, q.CreatedTo)
+			}
+		}, false},
+		{"totals in major units", "min_total=12.5&max_total=100", func(t *testing.T, q *types.OrderListQuery) {
+			if *q.MinTotal != money.New(1250, "TRY") || *q.MaxTotal != money.New(10000, "TRY") {
+				t.Fatalf("MinTotal = %s, MaxTotal = %s", q.MinTotal, q.MaxTotal)
+			}
+		}, false},
+		{"ascending sort", "sort=total_price", func(t *testing.T, q *types.OrderListQuery) {
+			if q.SortBy != "total_price" || q.SortDesc {
+				t.Fatalf("sort = %s desc %v, want total_price ascending", q.SortBy, q.SortDesc)
+			}
+		}, false},
+		{"descending sort", "sort=-status", func(t *testing.T, q *types.OrderListQuery) {
+			if q.SortBy != "status" || !q.SortDesc {
+				t.Fatalf("sort = %s desc %v, want status descending", q.SortBy, q.SortDesc)
+			}
+		}, false},
+		{"unknown status", "status=LOST", nil, true},
+		{"customer id is not a uuid", "customer_id=42", nil, true},
+		{"unparsable date", "created_from=yesterday", nil, true},
+		{"dates the wrong way round", "created_from=2026-02-01T00:00:00Z&created_to=2026-01-01T00:00:00Z", nil, true},
+		{"unparsable total", "min_total=ten", nil, true},
+		{"negative total", "min_total=-1", nil, true},
+		{"totals the wrong way round", "min_total=100&max_total=10", nil, true},
+		{"unknown sort field", "sort=password", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/order/list?"+tt.query, nil)
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			q, err := parseOrderListQuery(c)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseOrderListQuery = %+v, want an error", q)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseOrderListQuery error = %v", err)
+			}
+			tt.check(t, q)
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package internal
 
 import (
+	"strings"
 	"tesodev-korpes/OrderService/config"
 	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/money"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,37 +18,25 @@ func FromCreateOrderRequest(req *types.CreateOrderRequestModel) *types.Order {
 	items := make([]types.OrderItem, len(req.Items))
 	for i, item := range req.Items {
 		items[i] = types.OrderItem{
-			ProductId:   item.ProductId,
-			ProductName: item.ProductName,
-			Quantity:    item.Quantity,
-			UnitPrice:   item.UnitPrice,
+			ProductId: item.ProductId,
+			Quantity:  item.Quantity,
 		}
 	}
-	discounts := make([]*types.Discount, len(req.Discounts))
-	for i, d := range req.Discounts {
-		if d != nil {
-			discounts[i] = &types.Discount{
-				Id:           d.Id,
-				Role:         d.Role,
-				DiscountCode: d.DiscountCode,
-				Type:         d.Type,
-				Value:        d.Value,
-				StartDate:    d.StartDate,
-				EndDate:      d.EndDate}
-		}
+	var shipping *types.Shipment
+	if code := normalizeShippingMethodCode(req.ShippingMethod); code != "" {
+		shipping = &types.Shipment{Method: code}
 	}
-
 	return &types.Order{
 		Id:              uuid.NewString(),
 		CustomerId:      req.CustomerId,
 		Items:           items,
 		ShippingAddress: req.ShippingAddress,
 		BillingAddress:  req.BillingAddress,
-		TotalPrice:      calculateTotalPrice(items),
+		Shipping:        shipping,
 		Status:          config.OrderStatus.Ordered,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
-		Discounts:       discounts,
+		DiscountCodes:   normalizeCouponCodes(req.DiscountCodes),
 	}
 }
 
@@ -59,7 +49,7 @@ func ToOrderResponse(order *types.Order) *types.OrderResponseModel {
 	for i, d := range order.Discounts {
 		if d != nil {
 			responseDiscounts[i] = &types.Discount{
-				Id:           uuid.NewString(),
+				Id:           d.Id,
 				Role:         d.Role,
 				DiscountCode: d.DiscountCode,
 				Type:         d.Type,
@@ -77,13 +67,34 @@ func ToOrderResponse(order *types.Order) *types.OrderResponseModel {
 		ShippingAddress: order.ShippingAddress,
 		BillingAddress:  order.BillingAddress,
 		TotalPrice:      order.TotalPrice,
+		Price:           order.Price,
+		Shipping:        order.Shipping,
 		Status:          order.Status,
+		Payment:         order.Payment,
+		Version:         order.Version,
 		Discounts:       responseDiscounts,
 		CreatedAt:       order.CreatedAt,
 		UpdatedAt:       order.UpdatedAt,
 	}
 }
 
+func ToOrderHistoryResponse(order *types.Order) *types.OrderHistoryResponseModel {
+	if order == nil {
+		return nil
+	}
+
+	history := order.StatusHistory
+	if history == nil {
+		history = []types.StatusChange{}
+	}
+
+	return &types.OrderHistoryResponseModel{
+		OrderId: order.Id,
+		Status:  order.Status,
+		History: history,
+	}
+}
+
 func ToOrderWithCustomerResponse(order *types.OrderResponseModel, customer *types.CustomerResponseModel) *types.OrderWithCustomerResponse {
 	if order == nil || customer == nil {
 		return nil
@@ -94,3 +105,305 @@ func ToOrderWithCustomerResponse(order *types.OrderResponseModel, customer *type
 		Customer:           *customer,
 	}
 }
+
+func FromCart(cart *types.Cart, req *types.CheckoutRequestModel) *types.CreateOrderRequestModel {
+	if cart == nil || req == nil {
+		return nil
+	}
+
+	items := make([]types.OrderItemRequestModel, len(cart.Items))
+	for i, item := range cart.Items {
+		items[i] = types.OrderItemRequestModel{
+			ProductId: item.ProductId,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	return &types.CreateOrderRequestModel{
+		CustomerId:      cart.CustomerId,
+		Items:           items,
+		DiscountCodes:   cart.DiscountCodes,
+		ShippingMethod:  req.ShippingMethod,
+		ShippingAddress: req.ShippingAddress,
+		BillingAddress:  req.BillingAddress,
+	}
+}
+
+func cartToOrderItems(cart *types.Cart) []types.OrderItem {
+	items := make([]types.OrderItem, len(cart.Items))
+	for i, item := range cart.Items {
+		items[i] = types.OrderItem{
+			ProductId: item.ProductId,
+			Quantity:  item.Quantity,
+		}
+	}
+	return items
+}
+
+func ToCartResponse(cart *types.Cart, items []types.OrderItem, discounts []*types.Discount, price *types.FinalPriceResult) *types.CartResponseModel {
+	if cart == nil {
+		return nil
+	}
+
+	return &types.CartResponseModel{
+		CustomerId:    cart.CustomerId,
+		Items:         items,
+		DiscountCodes: cart.DiscountCodes,
+		Discounts:     discounts,
+		Price:         *price,
+		UpdatedAt:     cart.UpdatedAt,
+	}
+}
+
+func FromCreateCouponRequest(req *types.CreateCouponRequestModel) *types.Coupon {
+	if req == nil {
+		return nil
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	now := time.Now()
+	return &types.Coupon{
+		Code:             normalizeCouponCode(req.Code),
+		Description:      req.Description,
+		Type:             req.Type,
+		Value:            req.Value,
+		Amount:           valueOrZero(req.Amount),
+		Role:             req.Role,
+		StartDate:        req.StartDate,
+		EndDate:          req.EndDate,
+		MinBasket:        valueOrZero(req.MinBasket),
+		UsageLimit:       req.UsageLimit,
+		PerCustomerLimit: req.PerCustomerLimit,
+		Stackable:        req.Stackable,
+		IsActive:         isActive,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+func FromUpdateCouponRequest(req *types.UpdateCouponRequestModel, coupon *types.Coupon) {
+	if req == nil || coupon == nil {
+		return
+	}
+
+	if req.Description != nil {
+		coupon.Description = *req.Description
+	}
+	if req.Type != nil {
+		coupon.Type = *req.Type
+	}
+	if req.Value != nil {
+		coupon.Value = *req.Value
+	}
+	if req.Amount != nil {
+		coupon.Amount = *req.Amount
+	}
+	if req.Role != nil {
+		coupon.Role = *req.Role
+	}
+	if req.StartDate != nil {
+		coupon.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		coupon.EndDate = *req.EndDate
+	}
+	if req.MinBasket != nil {
+		coupon.MinBasket = *req.MinBasket
+	}
+	if req.UsageLimit != nil {
+		coupon.UsageLimit = *req.UsageLimit
+	}
+	if req.PerCustomerLimit != nil {
+		coupon.PerCustomerLimit = *req.PerCustomerLimit
+	}
+	if req.Stackable != nil {
+		coupon.Stackable = *req.Stackable
+	}
+	if req.IsActive != nil {
+		coupon.IsActive = *req.IsActive
+	}
+}
+
+func ToCouponResponse(coupon *types.Coupon) *types.CouponResponseModel {
+	if coupon == nil {
+		return nil
+	}
+
+	return &types.CouponResponseModel{
+		Code:             coupon.Code,
+		Description:      coupon.Description,
+		Type:             coupon.Type,
+		Value:            coupon.Value,
+		Amount:           valueOrNil(coupon.Amount),
+		Role:             coupon.Role,
+		StartDate:        coupon.StartDate,
+		EndDate:          coupon.EndDate,
+		MinBasket:        valueOrNil(coupon.MinBasket),
+		UsageLimit:       coupon.UsageLimit,
+		PerCustomerLimit: coupon.PerCustomerLimit,
+		UsedCount:        coupon.UsedCount,
+		Stackable:        coupon.Stackable,
+		IsActive:         coupon.IsActive,
+		CreatedAt:        coupon.CreatedAt,
+		UpdatedAt:        coupon.UpdatedAt,
+	}
+}
+
+// ToDiscountSnapshot copies the part of a coupon that prices an order, so the
+// order keeps the terms it was placed with.
+func ToDiscountSnapshot(coupon *types.Coupon) *types.Discount {
+	return &types.Discount{
+		Id:           coupon.Code,
+		Role:         coupon.Role,
+		StartDate:    coupon.StartDate,
+		EndDate:      coupon.EndDate,
+		DiscountCode: coupon.Code,
+		Type:         coupon.Type,
+		Value:        coupon.Value,
+		Amount:       coupon.Amount,
+	}
+}
+
+func valueOrZero(m *money.Money) money.Money {
+	if m == nil {
+		return money.Money{}
+	}
+	return *m
+}
+
+func valueOrNil(m money.Money) *money.Money {
+	if m.IsZero() {
+		return nil
+	}
+	return &m
+}
+
+func FromCreateExchangeRateRequest(req *types.CreateExchangeRateRequestModel, createdBy string) *types.ExchangeRate {
+	from, _ := money.ParseCurrency(req.From)
+	to, _ := money.ParseCurrency(req.To)
+
+	now := time.Now()
+	effectiveFrom := now
+	if req.EffectiveFrom != nil && req.EffectiveFrom.After(now) {
+		effectiveFrom = *req.EffectiveFrom
+	}
+
+	return &types.ExchangeRate{
+		Id:            uuid.NewString(),
+		From:          from,
+		To:            to,
+		Rate:          strings.TrimSpace(req.Rate),
+		EffectiveFrom: effectiveFrom,
+		CreatedBy:     createdBy,
+		CreatedAt:     now,
+	}
+}
+
+func ToExchangeRateResponse(rate *types.ExchangeRate) *types.ExchangeRateResponseModel {
+	if rate == nil {
+		return nil
+	}
+
+	return &types.ExchangeRateResponseModel{
+		Id:            rate.Id,
+		From:          rate.From,
+		To:            rate.To,
+		Rate:          rate.Rate,
+		EffectiveFrom: rate.EffectiveFrom,
+		CreatedBy:     rate.CreatedBy,
+		CreatedAt:     rate.CreatedAt,
+	}
+}
+
+func FromTaxRuleRequest(req *types.TaxRuleRequestModel) *types.TaxRule {
+	if req == nil {
+		return nil
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	exempt := make([]string, 0, len(req.ExemptCategories))
+	for _, category := range req.ExemptCategories {
+		exempt = append(exempt, normalizeCategory(category))
+	}
+
+	now := time.Now()
+	return &types.TaxRule{
+		Id:               uuid.NewString(),
+		Name:             strings.TrimSpace(req.Name),
+		State:            normalizeState(req.State),
+		ZipPrefix:        strings.TrimSpace(req.ZipPrefix),
+		Rate:             req.Rate,
+		ExemptCategories: exempt,
+		IsActive:         isActive,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+func ToTaxRuleResponse(rule *types.TaxRule) *types.TaxRuleResponseModel {
+	if rule == nil {
+		return nil
+	}
+
+	return &types.TaxRuleResponseModel{
+		Id:               rule.Id,
+		Name:             rule.Name,
+		State:            rule.State,
+		ZipPrefix:        rule.ZipPrefix,
+		Rate:             rule.Rate,
+		ExemptCategories: rule.ExemptCategories,
+		IsActive:         rule.IsActive,
+		CreatedAt:        rule.CreatedAt,
+		UpdatedAt:        rule.UpdatedAt,
+	}
+}
+
+func FromShippingMethodRequest(req *types.ShippingMethodRequestModel, code string) *types.ShippingMethod {
+	if req == nil {
+		return nil
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	now := time.Now()
+	return &types.ShippingMethod{
+		Code:      code,
+		Name:      strings.TrimSpace(req.Name),
+		Type:      req.Type,
+		Cost:      valueOrZero(req.Cost),
+		PerKg:     valueOrZero(req.PerKg),
+		FreeAbove: valueOrZero(req.FreeAbove),
+		IsActive:  isActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func ToShippingMethodResponse(method *types.ShippingMethod) *types.ShippingMethodResponseModel {
+	if method == nil {
+		return nil
+	}
+
+	return &types.ShippingMethodResponseModel{
+		Code:      method.Code,
+		Name:      method.Name,
+		Type:      method.Type,
+		Cost:      method.Cost,
+		PerKg:     valueOrNil(method.PerKg),
+		FreeAbove: valueOrNil(method.FreeAbove),
+		IsActive:  method.IsActive,
+		CreatedAt: method.CreatedAt,
+		UpdatedAt: method.UpdatedAt,
+	}
+}
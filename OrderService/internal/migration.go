@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/money"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MigrateMoney rewrites orders stored before amounts carried a currency, when
+// prices were plain numbers in major units. Such orders can be read without
+// migrating, since money.Money decodes the old numbers as DefaultCurrency; the
+// migration only makes the stored documents match what is read.
+//
+// It is safe to run repeatedly and alongside live traffic: every order is
+// written under its version guard, and an order that changed in the meantime
+// is left for the next run.
+func (r *Repository) MigrateMoney(ctx context.Context) (int, error) {
+	number := bson.M{"$type": "number"}
+	filter := bson.M{"$or": bson.A{
+		bson.M{"total_price": number},
+		bson.M{"items.unit_price": number},
+		bson.M{"price.final_price": number},
+		bson.M{"payment.amount": number},
+		bson.M{"returns.refund_amount": number},
+		bson.M{"discount": bson.M{"$elemMatch": bson.M{
+			"type":   config.DiscountType.FixedAmount,
+			"amount": bson.M{"$exists": false},
+		}}},
+	}}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var order types.Order
+		if err := cursor.Decode(&order); err != nil {
+			return migrated, err
+		}
+
+		set := bson.M{
+			"items":       order.Items,
+			"total_price": order.TotalPrice,
+		}
+		for _, d := range order.Discounts {
+			if d != nil && d.Type == config.DiscountType.FixedAmount && d.Amount.IsZero() {
+				d.Amount = money.FromMajor(d.Value, order.TotalPrice.Currency)
+				d.Value = 0
+			}
+		}
+		if order.Discounts != nil {
+			set["discount"] = order.Discounts
+		}
+		if order.Price != nil {
+			set["price"] = order.Price
+		}
+		if order.Payment != nil {
+			set["payment"] = order.Payment
+		}
+		if order.Returns != nil {
+			set["returns"] = order.Returns
+		}
+
+		err := r.updateVersioned(ctx, order.Id, order.Version, set)
+		var appErr *customError.AppError
+		if errors.As(err, &appErr) {
+			continue
+		}
+		if err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, cursor.Err()
+}
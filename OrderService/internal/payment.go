@@ -0,0 +1,225 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/money"
+	"time"
+)
+
+// AuthorizePayment reserves the order amount on the customer's payment method
+// and moves the order to PAYMENT_PENDING. A declined authorization is recorded
+// on the order, which stays ORDERED so the customer can try again.
+//
+// The payment is claimed as AUTHORIZING under the order's version before the
+// gateway is called, so of two concurrent or retried authorizations only one
+// reaches the gateway. A failed call puts the payment back as it was; a
+// payment left AUTHORIZING was sent to the gateway but its outcome could not
+// be saved.
+func (s *Service) AuthorizePayment(ctx context.Context, id string, paymentToken string, audit types.AuditInfo) (*types.Payment, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPaymentSettled(order.Payment); err != nil {
+		return nil, err
+	}
+	if err := checkTransition(order.Status, config.OrderStatus.PaymentPending); err != nil {
+		return nil, err
+	}
+
+	amount := payableAmount(order)
+	payment := &types.Payment{
+		Gateway:   s.gateway.Name(),
+		Status:    config.PaymentStatus.Authorizing,
+		Amount:    amount,
+		CreatedAt: time.Now(),
+	}
+	if order.Payment != nil {
+		payment.Transactions = order.Payment.Transactions
+		payment.CreatedAt = order.Payment.CreatedAt
+	}
+	if err := s.repo.UpdatePayment(ctx, id, order.Version, payment, nil); err != nil {
+		return nil, err
+	}
+	version := order.Version + 1
+
+	result, err := s.gateway.Authorize(ctx, AuthorizeRequest{
+		OrderId:      order.Id,
+		CustomerId:   order.CustomerId,
+		Amount:       amount,
+		PaymentToken: paymentToken,
+	})
+	if err != nil {
+		// If this fails too the payment stays AUTHORIZING and needs a
+		// look; the gateway error is what counts.
+		_ = s.repo.UpdatePayment(ctx, id, version, order.Payment, nil)
+		return nil, customError.NewInternal(customError.OrderServiceError, err)
+	}
+	appendTransaction(payment, config.PaymentTransactionType.Authorize, amount, result)
+
+	if !result.Approved {
+		payment.Status = config.PaymentStatus.Declined
+		if err := s.repo.UpdatePayment(ctx, id, version, payment, nil); err != nil {
+			return nil, err
+		}
+		return nil, customError.NewPaymentRequired(customError.PaymentDeclined, nil)
+	}
+
+	payment.Status = config.PaymentStatus.Authorized
+	payment.AuthorizationId = result.Reference
+	change := newStatusChange(order.Status, config.OrderStatus.PaymentPending, audit)
+	if err := s.repo.UpdatePayment(ctx, id, version, payment, &change); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// CapturePayment collects the authorized amount and marks the order PAID. Like
+// AuthorizePayment it claims the payment, as CAPTURING, before calling the
+// gateway; a failed or declined capture leaves it AUTHORIZED.
+func (s *Service) CapturePayment(ctx context.Context, id string, audit types.AuditInfo) (*types.Payment, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	payment := order.Payment
+	if payment == nil {
+		return nil, customError.NewNotFound(customError.PaymentNotFound)
+	}
+	if payment.Status != config.PaymentStatus.Authorized {
+		return nil, customError.NewConflict(customError.PaymentStatusConflict, "capture", payment.Status)
+	}
+	if err := checkTransition(order.Status, config.OrderStatus.Paid); err != nil {
+		return nil, err
+	}
+
+	payment.Status = config.PaymentStatus.Capturing
+	if err := s.repo.UpdatePayment(ctx, id, order.Version, payment, nil); err != nil {
+		return nil, err
+	}
+	version := order.Version + 1
+	payment.Status = config.PaymentStatus.Authorized
+
+	result, err := s.gateway.Capture(ctx, payment.AuthorizationId, payment.Amount)
+	if err != nil {
+		// If this fails too the payment stays CAPTURING and needs a look.
+		_ = s.repo.UpdatePayment(ctx, id, version, payment, nil)
+		return nil, customError.NewInternal(customError.OrderServiceError, err)
+	}
+	appendTransaction(payment, config.PaymentTransactionType.Capture, payment.Amount, result)
+
+	if !result.Approved {
+		if err := s.repo.UpdatePayment(ctx, id, version, payment, nil); err != nil {
+			return nil, err
+		}
+		return nil, customError.NewPaymentRequired(customError.PaymentDeclined, nil)
+	}
+
+	payment.Status = config.PaymentStatus.Captured
+	payment.CaptureId = result.Reference
+	payment.CapturedAmount = payment.Amount
+	change := newStatusChange(order.Status, config.OrderStatus.Paid, audit)
+	if err := s.repo.UpdatePayment(ctx, id, version, payment, &change); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (s *Service) GetPayment(ctx context.Context, id string) (*types.Payment, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Payment == nil {
+		return nil, customError.NewNotFound(customError.PaymentNotFound)
+	}
+	return order.Payment, nil
+}
+
+// settlePaymentOnCancel gives the money back when an order is canceled: an open
+// authorization is voided, a captured payment is refunded in full. It returns
+// nil when there is nothing to settle.
+func (s *Service) settlePaymentOnCancel(ctx context.Context, order *types.Order) (*types.Payment, error) {
+	payment := order.Payment
+	if payment == nil {
+		return nil, nil
+	}
+
+	switch payment.Status {
+	case config.PaymentStatus.Authorized:
+		result, err := s.gateway.Void(ctx, payment.AuthorizationId)
+		if err != nil {
+			return nil, customError.NewInternal(customError.OrderServiceError, err)
+		}
+		appendTransaction(payment, config.PaymentTransactionType.Void, money.Zero(payment.Amount.Currency), result)
+		if !result.Approved {
+			return nil, customError.NewConflict(customError.PaymentStatusConflict, "void", payment.Status)
+		}
+		payment.Status = config.PaymentStatus.Voided
+		return payment, nil
+
+	case config.PaymentStatus.Captured:
+		return s.refundPayment(ctx, payment, payment.CapturedAmount.Sub(payment.RefundedAmount))
+	}
+
+	return nil, nil
+}
+
+// refundPayment refunds amount of a captured payment and updates its totals.
+func (s *Service) refundPayment(ctx context.Context, payment *types.Payment, amount money.Money) (*types.Payment, error) {
+	result, err := s.gateway.Refund(ctx, payment.CaptureId, amount)
+	if err != nil {
+		return nil, customError.NewInternal(customError.OrderServiceError, err)
+	}
+	appendTransaction(payment, config.PaymentTransactionType.Refund, amount, result)
+	if !result.Approved {
+		return nil, customError.NewConflict(customError.PaymentStatusConflict, "refund", payment.Status)
+	}
+
+	payment.RefundedAmount = payment.RefundedAmount.Add(amount)
+	if !payment.RefundedAmount.LessThan(payment.CapturedAmount) {
+		payment.Status = config.PaymentStatus.Refunded
+	} else {
+		payment.Status = config.PaymentStatus.PartiallyRefunded
+	}
+	return payment, nil
+}
+
+// checkPaymentSettled refuses to change an order while its payment is waiting
+// on the gateway, whose answer is saved under the version the claim took.
+func checkPaymentSettled(payment *types.Payment) error {
+	if payment == nil {
+		return nil
+	}
+	switch payment.Status {
+	case config.PaymentStatus.Authorizing, config.PaymentStatus.Capturing:
+		return customError.NewConflict(customError.PaymentInProgress)
+	}
+	return nil
+}
+
+func appendTransaction(payment *types.Payment, txType string, amount money.Money, result *GatewayResult) {
+	now := time.Now()
+	payment.Transactions = append(payment.Transactions, types.PaymentTransaction{
+		Type:      txType,
+		Amount:    amount,
+		Reference: result.Reference,
+		Approved:  result.Approved,
+		Message:   result.Message,
+		CreatedAt: now,
+	})
+	payment.UpdatedAt = now
+}
+
+// payableAmount is what the customer is charged for an order: the price stored
+// when it was placed, or the plain total for orders from before prices were
+// stored.
+func payableAmount(order *types.Order) money.Money {
+	if order.Price != nil {
+		return order.Price.FinalPrice
+	}
+	return order.TotalPrice
+}
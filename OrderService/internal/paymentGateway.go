@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/pkg/money"
+)
+
+// PaymentGateway is the boundary to a payment provider. Declines are reported
+// through GatewayResult.Approved; a returned error means the gateway could not
+// be reached or did not understand the request.
+type PaymentGateway interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*GatewayResult, error)
+	Capture(ctx context.Context, authorizationID string, amount money.Money) (*GatewayResult, error)
+	Refund(ctx context.Context, captureID string, amount money.Money) (*GatewayResult, error)
+	Void(ctx context.Context, authorizationID string) (*GatewayResult, error)
+}
+
+type AuthorizeRequest struct {
+	OrderId      string
+	CustomerId   string
+	Amount       money.Money
+	PaymentToken string
+}
+
+type GatewayResult struct {
+	Approved  bool
+	Reference string
+	Message   string
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/customError"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AuthorizePayment godoc
+// @Summary Authorize the payment of an order
+// @Description Authorizes the order amount on the given payment method and moves the order to PAYMENT_PENDING
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Order ID (UUID)"
+// @Param payment body types.AuthorizePaymentRequestModel true "Payment method token"
+// @Success 200 {object} types.Payment
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format or request body"
+// @Failure 402 {object} errorPackage.AppError "Payment declined"
+// @Failure 404 {object} errorPackage.AppError "Order not found"
+// @Failure 409 {object} errorPackage.AppError "Order is not awaiting payment"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /order/{id}/payment/authorize [post]
+func (h *Handler) AuthorizePayment(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidOrderID)
+	}
+
+	var req types.AuthorizePaymentRequestModel
+	if err := c.Bind(&req); err != nil || req.PaymentToken == "" {
+		return customError.NewBadRequest(customError.InvalidPaymentBody)
+	}
+
+	payment, err := h.service.AuthorizePayment(c.Request().Context(), id, req.PaymentToken, newAuditInfo(c, "payment authorized"))
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, payment)
+}
+
+// CapturePayment godoc
+// @Summary Capture the payment of an order
+// @Description Captures the authorized amount and moves the order to PAID so it can be shipped
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} types.Payment
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 402 {object} errorPackage.AppError "Capture declined"
+// @Failure 404 {object} errorPackage.AppError "Order or payment not found"
+// @Failure 409 {object} errorPackage.AppError "Payment is not authorized"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /order/{id}/payment/capture [post]
+func (h *Handler) CapturePayment(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidOrderID)
+	}
+
+	payment, err := h.service.CapturePayment(c.Request().Context(), id, newAuditInfo(c, "payment captured"))
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, payment)
+}
+
+// GetPayment godoc
+// @Summary Get the payment of an order
+// @Description Returns the payment record of an order with all gateway transactions
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} types.Payment
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Order or payment not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /order/{id}/payment [get]
+func (h *Handler) GetPayment(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidOrderID)
+	}
+
+	payment, err := h.service.GetPayment(c.Request().Context(), id)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, payment)
+}
+
+func toOrderError(c echo.Context, err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return customError.NewNotFound(customError.OrderNotFound)
+	}
+
+	var appErr *customError.AppError
+	if errors.As(err, &appErr) {
+		return err
+	}
+
+	correlationID, _ := c.Get("CorrelationID").(string)
+	customError.LogErrorWithCorrelation(err, correlationID)
+	return customError.NewInternal(customError.OrderServiceError, err)
+}
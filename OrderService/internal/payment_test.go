@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/money"
+	"testing"
+)
+
+// stepGateway wraps FakeGateway to count calls, fail them, or run something
+// while a call is at the gateway.
+type stepGateway struct {
+	*FakeGateway
+	calls  int
+	err    error
+	during func()
+}
+
+func (g *stepGateway) step() error {
+	g.calls++
+	if g.during != nil {
+		g.during()
+	}
+	return g.err
+}
+
+func (g *stepGateway) Authorize(ctx context.Context, req AuthorizeRequest) (*GatewayResult, error) {
+	if err := g.step(); err != nil {
+		return nil, err
+	}
+	return g.FakeGateway.Authorize(ctx, req)
+}
+
+func (g *stepGateway) Capture(ctx context.Context, authorizationID string, amount money.Money) (*GatewayResult, error) {
+	if err := g.step(); err != nil {
+		return nil, err
+	}
+	return g.FakeGateway.Capture(ctx, authorizationID, amount)
+}
+
+func (g *stepGateway) Refund(ctx context.Context, captureID string, amount money.Money) (*GatewayResult, error) {
+	if err := g.step(); err != nil {
+		return nil, err
+	}
+	return g.FakeGateway.Refund(ctx, captureID, amount)
+}
+
+func paymentTestService(orders ...*types.Order) (*Service, *memoryOrders, *stepGateway) {
+	store := newMemoryOrders(orders...)
+	gateway := &stepGateway{FakeGateway: NewFakeGateway()}
+	return &Service{repo: store, gateway: gateway}, store, gateway
+}
+
+func orderedTestOrder() *types.Order {
+	return &types.Order{
+		Id:         "order-1",
+		CustomerId: "customer-1",
+		Status:     config.OrderStatus.Ordered,
+		TotalPrice: money.New(2500, "TRY"),
+		Version:    3,
+	}
+}
+
+func TestAuthorizePayment(t *testing.T) {
+	gatewayDown := errors.New("gateway unreachable")
+
+	tests := []struct {
+		name          string
+		token         string
+		gatewayErr    error
+		wantErr       customError.ErrorKey
+		wantStatus    string
+		wantPayment   string
+		wantTxs       int
+		wantNoPayment bool
+	}{
+		{"approved", "tok_ok", nil, "", config.OrderStatus.PaymentPending, config.PaymentStatus.Authorized, 1, false},
+		{"declined", FakeTokenDecline, nil, customError.PaymentDeclined, config.OrderStatus.Ordered, config.PaymentStatus.Declined, 1, false},
+		{"gateway error puts the payment back", "tok_ok", gatewayDown, customError.OrderServiceError, config.OrderStatus.Ordered, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, gateway := paymentTestService(orderedTestOrder())
+			gateway.err = tt.gatewayErr
+
+			_, err := s.AuthorizePayment(context.Background(), "order-1", tt.token, types.AuditInfo{ActorId: "customer-1"})
+			expectError(t, err, tt.wantErr)
+
+			order := store.order(t, "order-1")
+			if order.Status != tt.wantStatus {
+				t.Fatalf("order status = %s, want %s", order.Status, tt.wantStatus)
+			}
+			if tt.wantNoPayment {
+				if order.Payment != nil {
+					t.Fatalf("payment = %+v, want none", order.Payment)
+				}
+				return
+			}
+			if order.Payment == nil || order.Payment.Status != tt.wantPayment || len(order.Payment.Transactions) != tt.wantTxs {
+				t.Fatalf("payment = %+v, want %s with %d transactions", order.Payment, tt.wantPayment, tt.wantTxs)
+			}
+		})
+	}
+}
+
+func TestAuthorizePaymentClaimsTheOrder(t *testing.T) {
+	t.Run("losing the claim never reaches the gateway", func(t *testing.T) {
+		s, store, gateway := paymentTestService(orderedTestOrder())
+		store.afterGet = func() { store.orders["order-1"].Version++ }
+
+		_, err := s.AuthorizePayment(context.Background(), "order-1", "tok_ok", types.AuditInfo{})
+		expectError(t, err, customError.OrderVersionConflict)
+		if gateway.calls != 0 {
+			t.Fatalf("gateway called %d times, want 0", gateway.calls)
+		}
+	})
+
+	t.Run("no second authorization or cancel while at the gateway", func(t *testing.T) {
+		s, store, gateway := paymentTestService(orderedTestOrder())
+		ctx := context.Background()
+		gateway.during = func() {
+			gateway.during = nil
+			if got := store.order(t, "order-1").Payment; got == nil || got.Status != config.PaymentStatus.Authorizing {
+				t.Errorf("payment at the gateway = %+v, want AUTHORIZING", got)
+			}
+			_, err := s.AuthorizePayment(ctx, "order-1", "tok_ok", types.AuditInfo{})
+			expectError(t, err, customError.PaymentInProgress)
+			expectError(t, s.CancelOrder(ctx, "order-1", types.AuditInfo{}), customError.PaymentInProgress)
+		}
+
+		if _, err := s.AuthorizePayment(ctx, "order-1", "tok_ok", types.AuditInfo{}); err != nil {
+			t.Fatalf("AuthorizePayment error = %v", err)
+		}
+		if gateway.calls != 1 {
+			t.Fatalf("gateway called %d times, want 1", gateway.calls)
+		}
+		if order := store.order(t, "order-1"); order.Status != config.OrderStatus.PaymentPending || order.Version != 5 {
+			t.Fatalf("order = %s at version %d, want PAYMENT_PENDING at 5", order.Status, order.Version)
+		}
+	})
+}
+
+func TestCapturePayment(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		gatewayErr error
+		wantErr    customError.ErrorKey
+		wantStatus string
+		wantPay    string
+	}{
+		{"approved", "tok_ok", nil, "", config.OrderStatus.Paid, config.PaymentStatus.Captured},
+		{"declined", FakeTokenCaptureFails, nil, customError.PaymentDeclined, config.OrderStatus.PaymentPending, config.PaymentStatus.Authorized},
+		{"gateway error", "tok_ok", errors.New("gateway unreachable"), customError.OrderServiceError, config.OrderStatus.PaymentPending, config.PaymentStatus.Authorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, gateway := paymentTestService(orderedTestOrder())
+			ctx := context.Background()
+			if _, err := s.AuthorizePayment(ctx, "order-1", tt.token, types.AuditInfo{}); err != nil {
+				t.Fatalf("AuthorizePayment error = %v", err)
+			}
+			gateway.err = tt.gatewayErr
+			gateway.during = func() {
+				gateway.during = nil
+				_, err := s.CapturePayment(ctx, "order-1", types.AuditInfo{})
+				expectError(t, err, customError.PaymentStatusConflict)
+				expectError(t, s.CancelOrder(ctx, "order-1", types.AuditInfo{}), customError.PaymentInProgress)
+			}
+
+			_, err := s.CapturePayment(ctx, "order-1", types.AuditInfo{})
+			expectError(t, err, tt.wantErr)
+
+			order := store.order(t, "order-1")
+			if order.Status != tt.wantStatus || order.Payment.Status != tt.wantPay {
+				t.Fatalf("order %s with payment %s, want %s with %s", order.Status, order.Payment.Status, tt.wantStatus, tt.wantPay)
+			}
+			if gateway.calls != 2 {
+				t.Fatalf("gateway called %d times, want 2", gateway.calls)
+			}
+		})
+	}
+}
@@ -2,10 +2,13 @@ package internal
 
 import (
 	"context"
-	"errors"
+	"regexp"
 	"tesodev-korpes/OrderService/config"
 	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg"
 	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/money"
+	"tesodev-korpes/pkg/pagination"
 	"time"
 
 	"github.com/google/uuid"
@@ -46,51 +49,99 @@ func (r *Repository) GetByID(ctx context.Context, id string) (*types.Order, erro
 	return &order, nil
 }
 
-func (r *Repository) UpdateStatusByID(ctx context.Context, id string, status string) error {
-	filter := bson.M{"_id": id}
-	update := bson.M{
-		"$set": bson.M{
-			"status":     status,
-			"updated_at": time.Now(),
-		},
-	}
+// UpdateStatusByID moves the order to change.To and appends the change to its
+// status history. The history is never rewritten, only pushed to.
+//
+// The update only applies while the order is still in change.From at the
+// given version, so two requests racing on the same order cannot both win.
+func (r *Repository) UpdateStatusByID(ctx context.Context, id string, version int, change types.StatusChange) error {
+	return r.updateVersioned(ctx, id, version, nil, change)
+}
 
-	res, err := r.collection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return err
+// UpdatePayment stores the payment record of an order, together with the
+// status change it causes if there is one, under the same version guard as
+// UpdateStatusByID.
+func (r *Repository) UpdatePayment(ctx context.Context, id string, version int, payment *types.Payment, change *types.StatusChange) error {
+	if change == nil {
+		return r.updateVersioned(ctx, id, version, bson.M{"payment": payment})
 	}
+	return r.updateVersioned(ctx, id, version, bson.M{"payment": payment}, *change)
+}
 
-	if res.MatchedCount == 0 {
-		return mongo.ErrNoDocuments
+// UpdateReturns stores the return requests and payment of an order. An approved
+// return can move the order through several statuses at once, so changes are
+// applied in order and all of them are appended to the history.
+func (r *Repository) UpdateReturns(ctx context.Context, id string, version int, returns []types.ReturnRequest, payment *types.Payment, changes ...types.StatusChange) error {
+	set := bson.M{"returns": returns}
+	if payment != nil {
+		set["payment"] = payment
 	}
+	return r.updateVersioned(ctx, id, version, set, changes...)
+}
 
-	return nil
+// UpdatePrice stores a recalculated price together with the items and total it
+// was calculated from.
+func (r *Repository) UpdatePrice(ctx context.Context, id string, version int, items []types.OrderItem, total money.Money, price *types.FinalPriceResult) error {
+	return r.updateVersioned(ctx, id, version, bson.M{
+		"items":       items,
+		"total_price": total,
+		"price":       price,
+	})
 }
 
-func (r *Repository) Cancel(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// UpdateShipping stores the shipment of an order together with its status
+// change.
+func (r *Repository) UpdateShipping(ctx context.Context, id string, version int, shipping *types.Shipment, change types.StatusChange) error {
+	return r.updateVersioned(ctx, id, version, bson.M{"shipping": shipping}, change)
+}
+
+func (r *Repository) updateVersioned(ctx context.Context, id string, version int, set bson.M, changes ...types.StatusChange) error {
+	filter := bson.M{
+		"_id":     id,
+		"version": pkg.VersionFilter(version),
+	}
 
+	fields := bson.M{"updated_at": time.Now()}
+	for k, v := range set {
+		fields[k] = v
+	}
 	update := bson.M{
-		"$set": bson.M{
-			"status":     config.OrderStatus.Canceled, //types.OrderCanceled
-			"updated_at": time.Now(),
-		},
+		"$set": fields,
+		"$inc": bson.M{"version": 1},
+	}
+	if len(changes) > 0 {
+		last := changes[len(changes)-1]
+		filter["status"] = changes[0].From
+		fields["status"] = last.To
+		fields["updated_at"] = last.ChangedAt
+		update["$push"] = bson.M{"status_history": bson.M{"$each": changes}}
 	}
 
-	filter := bson.M{"_id": id}
 	res, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
 
 	if res.MatchedCount == 0 {
-		return errors.New("order not found")
+		return r.missOrConflict(ctx, id)
 	}
 
 	return nil
 }
 
+// missOrConflict tells apart a conditional update that matched nothing because
+// the order is gone from one that lost a race against another writer.
+func (r *Repository) missOrConflict(ctx context.Context, id string) error {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return customError.NewConflict(customError.OrderVersionConflict)
+}
+
 func (r *Repository) SoftDeleteByID(ctx context.Context, id string) error {
 
 	filter := bson.M{"_id": id}
@@ -113,10 +164,21 @@ func (r *Repository) SoftDeleteByID(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *Repository) GetAllOrders(ctx context.Context, findOptions *options.FindOptions) ([]types.Order, error) {
+// GetAllOrders returns the orders matching the query that follow the cursor of
+// params, plus one more so the caller can tell whether another page follows.
+func (r *Repository) GetAllOrders(ctx context.Context, query *types.OrderListQuery, params pagination.Params) ([]types.Order, error) {
 	var orders []types.Order
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	field := config.OrderSortFields[query.SortBy]
+	filter, err := pagination.After(orderListFilter(query), field, query.SortDesc, params.After)
+	if err != nil {
+		return nil, err
+	}
+	findOptions := options.Find().
+		SetSort(pagination.Sort(field, query.SortDesc)).
+		SetLimit(int64(params.Limit + 1))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -129,9 +191,75 @@ func (r *Repository) GetAllOrders(ctx context.Context, findOptions *options.Find
 	return orders, nil
 }
 
-func (r *Repository) FindPriceWithMatchingDiscount(ctx context.Context, orderID string, role string) (*types.OrderPriceInfo, error) {
+func (r *Repository) CountOrders(ctx context.Context, query *types.OrderListQuery) (int64, error) {
+	return r.collection.CountDocuments(ctx, orderListFilter(query))
+}
+
+// orderListFilter translates a validated list query into a Mongo filter.
+// Totals are compared in minor units; all orders are in the catalog currency.
+func orderListFilter(query *types.OrderListQuery) bson.M {
+	filter := bson.M{}
+	if query == nil {
+		return filter
+	}
+
+	if query.CustomerId != "" {
+		filter["customer_id"] = query.CustomerId
+	}
+	if len(query.Statuses) > 0 {
+		filter["status"] = bson.M{"$in": query.Statuses}
+	}
+	if query.ProductId != "" {
+		filter["items.product_id"] = query.ProductId
+	}
+	if query.Search != "" {
+		filter["items.product_name"] = bson.M{"$regex": regexp.QuoteMeta(query.Search), "$options": "i"}
+	}
+
+	created := bson.M{}
+	if query.CreatedFrom != nil {
+		created["$gte"] = *query.CreatedFrom
+	}
+	if query.CreatedTo != nil {
+		created["$lte"] = *query.CreatedTo
+	}
+	if len(created) > 0 {
+		filter["created_at"] = created
+	}
+
+	total := bson.M{}
+	if query.MinTotal != nil {
+		total["$gte"] = query.MinTotal.Amount
+	}
+	if query.MaxTotal != nil {
+		total["$lte"] = query.MaxTotal.Amount
+	}
+	if len(total) > 0 {
+		filter["total_price.amount"] = total
+	}
 
-	now := time.Now()
+	return filter
+}
+
+// EnsureIndexes creates the indexes GET /order/list filters and sorts on.
+// Creating an index that already exists is a no-op.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "items.product_id", Value: 1}}},
+	}
+	for _, field := range config.OrderSortFields {
+		models = append(models, mongo.IndexModel{Keys: bson.D{{Key: field, Value: 1}}})
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, models)
+	return err
+}
+
+// FindPriceWithMatchingDiscount returns the total of an order with the
+// discounts that apply to the given membership. The discounts are snapshots
+// checked when the order was placed, so only the membership is matched here;
+// a discount without a role applies to everyone.
+func (r *Repository) FindPriceWithMatchingDiscount(ctx context.Context, orderID string, role string) (*types.OrderPriceInfo, error) {
 
 	pipeline := mongo.Pipeline{
 		{{Key: "$match", Value: bson.D{
@@ -141,13 +269,12 @@ func (r *Repository) FindPriceWithMatchingDiscount(ctx context.Context, orderID
 			{Key: "total_price", Value: 1},
 			{Key: "discount", Value: bson.D{
 				{Key: "$filter", Value: bson.D{
-					{Key: "input", Value: "$discount"},
+					{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$discount", bson.A{}}}}},
 					{Key: "as", Value: "d"},
 					{Key: "cond", Value: bson.D{
-						{Key: "$and", Value: bson.A{
-							bson.D{{Key: "$eq", Value: bson.A{"$$d.role", role}}},
-							bson.D{{Key: "$lte", Value: bson.A{"$$d.start_date", now}}},
-							bson.D{{Key: "$gte", Value: bson.A{"$$d.end_date", now}}},
+						{Key: "$in", Value: bson.A{
+							bson.D{{Key: "$ifNull", Value: bson.A{"$$d.role", ""}}},
+							bson.A{role, ""},
 						}},
 					}},
 				}},
@@ -174,8 +301,8 @@ func (r *Repository) FindPriceWithMatchingDiscount(ctx context.Context, orderID
 		TotalPrice: tempResult.TotalPrice,
 	}
 
-	if len(tempResult.Discount) > 0 {
-		finalResult.Discount = &tempResult.Discount[0]
+	for i := range tempResult.Discount {
+		finalResult.Discounts = append(finalResult.Discounts, &tempResult.Discount[i])
 	}
 
 	return finalResult, nil
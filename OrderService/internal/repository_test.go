@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"reflect"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/money"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestOrderListFilter(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	minTotal, maxTotal := money.New(1250, "TRY"), money.New(10000, "TRY")
+
+	tests := []struct {
+		name  string
+		query *types.OrderListQuery
+		want  bson.M
+	}{
+		{"no query", nil, bson.M{}},
+		{"no filters", &types.OrderListQuery{SortBy: "created_at"}, bson.M{}},
+		{"customer and statuses", &types.OrderListQuery{CustomerId: "c1", Statuses: []string{"PAID", "SHIPPED"}}, bson.M{
+			"customer_id": "c1",
+			"status":      bson.M{"$in": []string{"PAID", "SHIPPED"}},
+		}},
+		{"product", &types.OrderListQuery{ProductId: "p1"}, bson.M{"items.product_id": "p1"}},
+		{"search is matched literally", &types.OrderListQuery{Search: "a.b*"}, bson.M{
+			"items.product_name": bson.M{"$regex": `a\.b\*`, "$options": "i"},
+		}},
+		{"date range", &types.OrderListQuery{CreatedFrom: &from, CreatedTo: &to}, bson.M{
+			"created_at": bson.M{"$gte": from, "$lte": to},
+		}},
+		{"open-ended date", &types.OrderListQuery{CreatedFrom: &from}, bson.M{
+			"created_at": bson.M{"$gte": from},
+		}},
+		{"totals in minor units", &types.OrderListQuery{MinTotal: &minTotal, MaxTotal: &maxTotal}, bson.M{
+			"total_price.amount": bson.M{"$gte": int64(1250), "$lte": int64(10000)},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderListFilter(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("orderListFilter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/customError"
+
+	"github.com/labstack/echo/v4"
+)
+
+// OpenReturn godoc
+// @Summary Open a return for a delivered order
+// @Description Request to return some or all items of a delivered order. Staff approve or reject it later.
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Order ID (UUID)"
+// @Param return body types.CreateReturnRequestModel true "Items to return and reason"
+// @Success 201 {object} types.ReturnRequest
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format or request body"
+// @Failure 404 {object} errorPackage.AppError "Order not found"
+// @Failure 409 {object} errorPackage.AppError "Order is not delivered"
+// @Failure 422 {object} errorPackage.AppError "Items not returnable"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /order/{id}/returns [post]
+func (h *Handler) OpenReturn(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidOrderID)
+	}
+
+	var req types.CreateReturnRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidReturnBody)
+	}
+
+	if err := req.CreateValidate(); err != nil {
+		return err
+	}
+
+	ret, err := h.service.OpenReturn(c.Request().Context(), id, &req, newAuditInfo(c, req.Reason))
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, ret)
+}
+
+// ListReturns godoc
+// @Summary List the returns of an order
+// @Description Returns every return request of an order with its review outcome and refund amount
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Returns list of return requests"
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Order not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /order/{id}/returns [get]
+func (h *Handler) ListReturns(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidOrderID)
+	}
+
+	returns, err := h.service.ListReturns(c.Request().Context(), id)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": returns})
+}
+
+// ApproveReturn godoc
+// @Summary Approve a return
+// @Description Approves a pending return and refunds the returned items, including their share of the order discount
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Order ID (UUID)"
+// @Param returnId path string true "Return ID (UUID)"
+// @Param review body types.ReviewReturnRequestModel false "Optional review note"
+// @Success 200 {object} types.ReturnRequest
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Order, return or payment not found"
+// @Failure 409 {object} errorPackage.AppError "Return already reviewed"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /order/{id}/returns/{returnId}/approve [patch]
+func (h *Handler) ApproveReturn(c echo.Context) error {
+	return h.reviewReturn(c, h.service.ApproveReturn)
+}
+
+// RejectReturn godoc
+// @Summary Reject a return
+// @Description Rejects a pending return; nothing is refunded
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Order ID (UUID)"
+// @Param returnId path string true "Return ID (UUID)"
+// @Param review body types.ReviewReturnRequestModel false "Optional review note"
+// @Success 200 {object} types.ReturnRequest
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Order or return not found"
+// @Failure 409 {object} errorPackage.AppError "Return already reviewed"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /order/{id}/returns/{returnId}/reject [patch]
+func (h *Handler) RejectReturn(c echo.Context) error {
+	return h.reviewReturn(c, h.service.RejectReturn)
+}
+
+type reviewReturnFunc func(ctx context.Context, id, returnID string, note string, audit types.AuditInfo) (*types.ReturnRequest, error)
+
+func (h *Handler) reviewReturn(c echo.Context, review reviewReturnFunc) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidOrderID)
+	}
+	returnID := c.Param("returnId")
+	if !pkg.IsValidUUID(returnID) {
+		return customError.NewBadRequest(customError.InvalidReturnID)
+	}
+
+	var req types.ReviewReturnRequestModel
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return customError.NewBadRequest(customError.InvalidReturnBody)
+		}
+	}
+
+	ret, err := review(c.Request().Context(), id, returnID, req.Note, newAuditInfo(c, ""))
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
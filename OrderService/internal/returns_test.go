@@ -0,0 +1,242 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/money"
+	"testing"
+)
+
+func returnTestOrder(returns ...types.ReturnRequest) *types.Order {
+	return &types.Order{
+		Items: []types.OrderItem{
+			{ProductId: "p1", Quantity: 2, UnitPrice: money.New(1000, "TRY")},
+			{ProductId: "p2", Quantity: 1, UnitPrice: money.New(333, "TRY")},
+		},
+		TotalPrice: money.New(2333, "TRY"),
+		Returns:    returns,
+	}
+}
+
+func returnOf(status string, items ...types.ReturnItem) types.ReturnRequest {
+	return types.ReturnRequest{Status: status, Items: items}
+}
+
+func TestReturnableQuantities(t *testing.T) {
+	status := config.ReturnStatus
+
+	tests := []struct {
+		name    string
+		returns []types.ReturnRequest
+		want    map[string]int
+	}{
+		{"nothing returned", nil, map[string]int{"p1": 2, "p2": 1}},
+		{"open return", []types.ReturnRequest{returnOf(status.Requested, types.ReturnItem{ProductId: "p1", Quantity: 1})}, map[string]int{"p1": 1, "p2": 1}},
+		{"return being approved", []types.ReturnRequest{returnOf(status.Approving, types.ReturnItem{ProductId: "p2", Quantity: 1})}, map[string]int{"p1": 2, "p2": 0}},
+		{"approved return", []types.ReturnRequest{returnOf(status.Approved, types.ReturnItem{ProductId: "p1", Quantity: 2})}, map[string]int{"p1": 0, "p2": 1}},
+		{"rejected return frees the items", []types.ReturnRequest{returnOf(status.Rejected, types.ReturnItem{ProductId: "p1", Quantity: 2})}, map[string]int{"p1": 2, "p2": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := returnableQuantities(returnTestOrder(tt.returns...)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("returnableQuantities = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsFullyReturned(t *testing.T) {
+	status := config.ReturnStatus
+	allItems := []types.ReturnItem{{ProductId: "p1", Quantity: 2}, {ProductId: "p2", Quantity: 1}}
+
+	tests := []struct {
+		name    string
+		returns []types.ReturnRequest
+		want    bool
+	}{
+		{"nothing returned", nil, false},
+		{"everything approved at once", []types.ReturnRequest{returnOf(status.Approved, allItems...)}, true},
+		{"everything being approved", []types.ReturnRequest{returnOf(status.Approving, allItems...)}, true},
+		{"approved over several returns", []types.ReturnRequest{
+			returnOf(status.Approved, types.ReturnItem{ProductId: "p1", Quantity: 1}),
+			returnOf(status.Approved, types.ReturnItem{ProductId: "p1", Quantity: 1}, types.ReturnItem{ProductId: "p2", Quantity: 1}),
+		}, true},
+		{"part still kept", []types.ReturnRequest{returnOf(status.Approved, types.ReturnItem{ProductId: "p1", Quantity: 2})}, false},
+		{"rest only requested", []types.ReturnRequest{
+			returnOf(status.Approved, types.ReturnItem{ProductId: "p1", Quantity: 2}),
+			returnOf(status.Requested, types.ReturnItem{ProductId: "p2", Quantity: 1}),
+		}, false},
+		{"rest rejected", []types.ReturnRequest{
+			returnOf(status.Approved, types.ReturnItem{ProductId: "p1", Quantity: 2}),
+			returnOf(status.Rejected, types.ReturnItem{ProductId: "p2", Quantity: 1}),
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFullyReturned(returnTestOrder(tt.returns...)); got != tt.want {
+				t.Fatalf("isFullyReturned = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefundForItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		items   []types.ReturnItem
+		charged money.Money
+		want    money.Money
+	}{
+		{"no discount", []types.ReturnItem{{ProductId: "p1", Quantity: 1}}, money.New(2333, "TRY"), money.New(1000, "TRY")},
+		{"whole order without discount", []types.ReturnItem{{ProductId: "p1", Quantity: 2}, {ProductId: "p2", Quantity: 1}}, money.New(2333, "TRY"), money.New(2333, "TRY")},
+		// 1000 * 2100 / 2333 = 900.128...
+		{"discount shared by price", []types.ReturnItem{{ProductId: "p1", Quantity: 1}}, money.New(2100, "TRY"), money.New(900, "TRY")},
+		// 333 * 2100 / 2333 = 299.74...
+		{"share rounded to the minor unit", []types.ReturnItem{{ProductId: "p2", Quantity: 1}}, money.New(2100, "TRY"), money.New(300, "TRY")},
+		{"nothing charged", []types.ReturnItem{{ProductId: "p1", Quantity: 1}}, money.New(0, "TRY"), money.New(0, "TRY")},
+		{"unknown product", []types.ReturnItem{{ProductId: "p9", Quantity: 1}}, money.New(2333, "TRY"), money.New(0, "TRY")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := refundForItems(returnTestOrder(), tt.items, tt.charged); got != tt.want {
+				t.Fatalf("refundForItems = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefundForItemsWithoutTotal(t *testing.T) {
+	order := &types.Order{TotalPrice: money.New(0, "TRY")}
+	if got := refundForItems(order, []types.ReturnItem{{ProductId: "p1", Quantity: 1}}, money.New(500, "TRY")); got != money.Zero("TRY") {
+		t.Fatalf("refundForItems = %s, want 0 TRY", got)
+	}
+}
+
+// deliveredTestService returns a service holding a delivered order whose
+// payment of 2500 went through the gateway, with one open return per set of
+// items given.
+func deliveredTestService(t *testing.T, returns ...[]types.ReturnItem) (*Service, *memoryOrders, *stepGateway) {
+	t.Helper()
+	order := orderedTestOrder()
+	order.Items = []types.OrderItem{
+		{ProductId: "p1", Quantity: 2, UnitPrice: money.New(1000, "TRY")},
+		{ProductId: "p2", Quantity: 1, UnitPrice: money.New(500, "TRY")},
+	}
+	s, store, gateway := paymentTestService(order)
+
+	ctx := context.Background()
+	if _, err := s.AuthorizePayment(ctx, "order-1", "tok_ok", types.AuditInfo{}); err != nil {
+		t.Fatalf("AuthorizePayment error = %v", err)
+	}
+	if _, err := s.CapturePayment(ctx, "order-1", types.AuditInfo{}); err != nil {
+		t.Fatalf("CapturePayment error = %v", err)
+	}
+
+	stored := store.orders["order-1"]
+	stored.Status = config.OrderStatus.Delivered
+	for i, items := range returns {
+		stored.Returns = append(stored.Returns, types.ReturnRequest{
+			Id:     fmt.Sprintf("return-%d", i+1),
+			Items:  items,
+			Status: config.ReturnStatus.Requested,
+		})
+	}
+	gateway.calls = 0
+	return s, store, gateway
+}
+
+func TestApproveReturn(t *testing.T) {
+	tests := []struct {
+		name         string
+		items        []types.ReturnItem
+		gatewayErr   error
+		wantErr      customError.ErrorKey
+		wantReturn   string
+		wantRefunded int64
+		wantPayment  string
+		wantStatus   string
+	}{
+		{"some items", []types.ReturnItem{{ProductId: "p1", Quantity: 1}}, nil, "",
+			config.ReturnStatus.Approved, 1000, config.PaymentStatus.PartiallyRefunded, config.OrderStatus.Delivered},
+		{"every item", []types.ReturnItem{{ProductId: "p1", Quantity: 2}, {ProductId: "p2", Quantity: 1}}, nil, "",
+			config.ReturnStatus.Approved, 2500, config.PaymentStatus.Refunded, config.OrderStatus.Refunded},
+		{"refund fails", []types.ReturnItem{{ProductId: "p1", Quantity: 1}}, errors.New("gateway unreachable"), customError.OrderServiceError,
+			config.ReturnStatus.Requested, 0, config.PaymentStatus.Captured, config.OrderStatus.Delivered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, gateway := deliveredTestService(t, tt.items)
+			gateway.err = tt.gatewayErr
+
+			_, err := s.ApproveReturn(context.Background(), "order-1", "return-1", "", types.AuditInfo{ActorId: "admin-1"})
+			expectError(t, err, tt.wantErr)
+
+			order := store.order(t, "order-1")
+			ret := order.Returns[0]
+			if ret.Status != tt.wantReturn {
+				t.Fatalf("return status = %s, want %s", ret.Status, tt.wantReturn)
+			}
+			if tt.wantReturn == config.ReturnStatus.Requested && (ret.ReviewedBy != "" || ret.RefundAmount.IsPositive()) {
+				t.Fatalf("reopened return = %+v, want the review undone", ret)
+			}
+			if got := order.Payment.RefundedAmount.Amount; got != tt.wantRefunded || order.Payment.Status != tt.wantPayment {
+				t.Fatalf("payment %s refunded %d, want %s refunded %d", order.Payment.Status, got, tt.wantPayment, tt.wantRefunded)
+			}
+			if order.Status != tt.wantStatus {
+				t.Fatalf("order status = %s, want %s", order.Status, tt.wantStatus)
+			}
+			if gateway.calls != 1 {
+				t.Fatalf("gateway called %d times, want 1", gateway.calls)
+			}
+		})
+	}
+}
+
+func TestApproveReturnClaimsTheReturn(t *testing.T) {
+	items := []types.ReturnItem{{ProductId: "p1", Quantity: 1}}
+
+	t.Run("losing the claim never reaches the gateway", func(t *testing.T) {
+		s, store, gateway := deliveredTestService(t, items)
+		store.afterGet = func() { store.orders["order-1"].Version++ }
+
+		_, err := s.ApproveReturn(context.Background(), "order-1", "return-1", "", types.AuditInfo{})
+		expectError(t, err, customError.OrderVersionConflict)
+		if gateway.calls != 0 {
+			t.Fatalf("gateway called %d times, want 0", gateway.calls)
+		}
+	})
+
+	t.Run("no second approval or rejection while refunding", func(t *testing.T) {
+		s, store, gateway := deliveredTestService(t, items)
+		ctx := context.Background()
+		gateway.during = func() {
+			gateway.during = nil
+			if got := store.order(t, "order-1").Returns[0].Status; got != config.ReturnStatus.Approving {
+				t.Errorf("return at the gateway = %s, want APPROVING", got)
+			}
+			_, err := s.ApproveReturn(ctx, "order-1", "return-1", "", types.AuditInfo{})
+			expectError(t, err, customError.ReturnStatusConflict)
+			_, err = s.RejectReturn(ctx, "order-1", "return-1", "", types.AuditInfo{})
+			expectError(t, err, customError.ReturnStatusConflict)
+		}
+
+		if _, err := s.ApproveReturn(ctx, "order-1", "return-1", "", types.AuditInfo{}); err != nil {
+			t.Fatalf("ApproveReturn error = %v", err)
+		}
+		if gateway.calls != 1 {
+			t.Fatalf("gateway called %d times, want 1", gateway.calls)
+		}
+		if got := store.order(t, "order-1").Payment.RefundedAmount.Amount; got != 1000 {
+			t.Fatalf("refunded %d, want 1000 once", got)
+		}
+	})
+}
@@ -4,30 +4,46 @@ package internal
 import (
 	"context"
 	"errors"
-	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"tesodev-korpes/OrderService/config"
 	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/auth"
 	"tesodev-korpes/pkg/client"      // <- fastHTTP wrapper (baseURL + path)
 	"tesodev-korpes/pkg/customError" // <- daha anlamlı hata mesajları için
-
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"tesodev-korpes/pkg/money"
+	"tesodev-korpes/pkg/pagination"
 )
 
 type Service struct {
-	repo   *Repository
-	client *client.Client
+	repo          orderStore
+	cartRepo      cartStore
+	couponRepo    couponStore
+	rateRepo      *ExchangeRateRepository
+	taxRepo       *TaxRepository
+	shippingRepo  *ShippingRepository
+	client        *client.Client
+	productClient *client.Client
+	gateway       PaymentGateway
 }
 
-func NewService(repo *Repository, client *client.Client) *Service {
+func NewService(repo *Repository, cartRepo *CartRepository, couponRepo *CouponRepository, rateRepo *ExchangeRateRepository, taxRepo *TaxRepository, shippingRepo *ShippingRepository, client *client.Client, productClient *client.Client, gateway PaymentGateway) *Service {
 	return &Service{
-		repo:   repo,
-		client: client,
+		repo:          repo,
+		cartRepo:      cartRepo,
+		couponRepo:    couponRepo,
+		rateRepo:      rateRepo,
+		taxRepo:       taxRepo,
+		shippingRepo:  shippingRepo,
+		client:        client,
+		productClient: productClient,
+		gateway:       gateway,
 	}
 }
 
-func (s *Service) Create(ctx context.Context, order *types.Order, token string) (string, error) {
+func (s *Service) Create(ctx context.Context, order *types.Order, token string, audit types.AuditInfo) (string, error) {
 	if order.CustomerId == "" {
 		return "", errors.New("customerId not found")
 	}
@@ -37,8 +53,33 @@ func (s *Service) Create(ctx context.Context, order *types.Order, token string)
 		return "", err
 	}
 
+	if err := s.resolveItems(order.Items, token); err != nil {
+		return "", err
+	}
+	order.TotalPrice = calculateTotalPrice(order.Items)
+
+	order.Discounts, err = s.resolveCoupons(ctx, order.DiscountCodes, order.CustomerId, customer.Role.Membership, order.TotalPrice)
+	if err != nil {
+		return "", err
+	}
+	order.Price, err = s.priceOrder(ctx, order, order.Discounts)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.reserveStock(order); err != nil {
+		return "", err
+	}
+	if err := s.redeemCoupons(ctx, order); err != nil {
+		_ = s.releaseStock(order.Id)
+		return "", err
+	}
+	order.StatusHistory = []types.StatusChange{newStatusChange("", order.Status, audit)}
+
 	id, err := s.repo.Create(ctx, order)
 	if err != nil {
+		_ = s.releaseStock(order.Id)
+		_ = s.releaseCoupons(ctx, order)
 		return "", err
 	}
 	return id, nil
@@ -61,38 +102,111 @@ func (s *Service) GetByID(ctx context.Context, id string, token string) (*types.
 	}, nil
 }
 
-func (s *Service) ShipOrder(ctx context.Context, id string) error {
+// OrderOwner returns the customer who placed the order; it backs the ownership
+// check of the order routes.
+func (s *Service) OrderOwner(ctx context.Context, id string) (string, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return order.CustomerId, nil
+}
+
+// ShipOrder hands a paid order to the carrier. The carrier and tracking number
+// are stored on the order's shipment together with the status change, and only
+// then is the stock reservation committed, so losing a race against another
+// update leaves the stock alone. Shipping an order that is already SHIPPED
+// keeps its shipment and retries the commit, which does nothing if it went
+// through.
+func (s *Service) ShipOrder(ctx context.Context, id string, req *types.ShipOrderRequestModel, audit types.AuditInfo) error {
 	order, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
-	if order.Status != config.OrderStatus.Ordered {
-		return customError.NewConflict(customError.OrderStatusConflict, order.Status, config.OrderStatus.Ordered)
+	if order.Status == config.OrderStatus.Shipped {
+		return s.commitStock(id)
+	}
+	if order.Payment == nil || order.Payment.Status != config.PaymentStatus.Captured {
+		return customError.NewConflict(customError.PaymentNotCaptured)
+	}
+	if err := checkTransition(order.Status, config.OrderStatus.Shipped); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	shipping := types.Shipment{}
+	if order.Shipping != nil {
+		shipping = *order.Shipping
 	}
-	return s.repo.UpdateStatusByID(ctx, id, config.OrderStatus.Shipped)
+	shipping.Carrier = strings.TrimSpace(req.Carrier)
+	shipping.TrackingNumber = strings.TrimSpace(req.TrackingNumber)
+	shipping.ShippedAt = &now
+
+	if err := s.repo.UpdateShipping(ctx, id, order.Version, &shipping, newStatusChange(order.Status, config.OrderStatus.Shipped, audit)); err != nil {
+		return err
+	}
+	return s.commitStock(id)
 }
 
-func (s *Service) DeliverOrder(ctx context.Context, id string) error {
+func (s *Service) DeliverOrder(ctx context.Context, id string, audit types.AuditInfo) error {
 	order, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
-	if order.Status != config.OrderStatus.Shipped {
-		return customError.NewConflict(customError.OrderStatusConflict, order.Status, config.OrderStatus.Shipped)
+	if err := checkTransition(order.Status, config.OrderStatus.Delivered); err != nil {
+		return err
 	}
-	return s.repo.UpdateStatusByID(ctx, id, config.OrderStatus.Delivered)
+	return s.repo.UpdateStatusByID(ctx, id, order.Version, newStatusChange(order.Status, config.OrderStatus.Delivered, audit))
 }
 
-func (s *Service) CancelOrder(ctx context.Context, id string) error {
+// CancelOrder moves the order to CANCELED and then gives back what it held:
+// its stock reservation, its coupon redemptions and its payment. The status
+// change comes first, so of two racing updates the loser releases nothing.
+// Every release is idempotent; canceling an order that is already CANCELED
+// retries those that failed. An order whose payment is still at the gateway
+// cannot be canceled until the gateway has answered.
+func (s *Service) CancelOrder(ctx context.Context, id string, audit types.AuditInfo) error {
 	order, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
-	switch order.Status {
-	case config.OrderStatus.Ordered, config.OrderStatus.Delivered, config.OrderStatus.Canceled:
-		return customError.NewConflict(customError.OrderStatusConflict, order.Status, config.OrderStatus.Canceled)
+	if err := checkPaymentSettled(order.Payment); err != nil {
+		return err
 	}
-	return s.repo.UpdateStatusByID(ctx, id, config.OrderStatus.Canceled)
+	if order.Status != config.OrderStatus.Canceled {
+		if err := checkTransition(order.Status, config.OrderStatus.Canceled); err != nil {
+			return err
+		}
+		change := newStatusChange(order.Status, config.OrderStatus.Canceled, audit)
+		if err := s.repo.UpdateStatusByID(ctx, id, order.Version, change); err != nil {
+			return err
+		}
+		order.Version++
+	}
+
+	if err := s.releaseStock(id); err != nil {
+		return err
+	}
+	if err := s.releaseCoupons(ctx, order); err != nil {
+		return err
+	}
+
+	payment, err := s.settlePaymentOnCancel(ctx, order)
+	if err != nil {
+		return err
+	}
+	if payment != nil {
+		return s.repo.UpdatePayment(ctx, id, order.Version, payment, nil)
+	}
+	return nil
+}
+
+func (s *Service) GetStatusHistory(ctx context.Context, id string) (*types.OrderHistoryResponseModel, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ToOrderHistoryResponse(order), nil
 }
 
 func (s *Service) DeleteOrder(ctx context.Context, id string) error {
@@ -111,30 +225,50 @@ func (s *Service) DeleteOrder(ctx context.Context, id string) error {
 	return s.repo.SoftDeleteByID(ctx, id)
 }
 
-func (s *Service) GetAllOrders(ctx context.Context, pagination types.Pagination) ([]*types.OrderResponseModel, error) {
-	skip := (pagination.Page - 1) * pagination.Limit
-
-	findOptions := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(pagination.Limit)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
-
-	orders, err := s.repo.GetAllOrders(ctx, findOptions)
+func (s *Service) GetAllOrders(ctx context.Context, query *types.OrderListQuery, params pagination.Params) (*pagination.Page[*types.OrderResponseModel], error) {
+	orders, err := s.repo.GetAllOrders(ctx, query, params)
 	if err != nil {
 		return nil, err
 	}
 
-	var response []*types.OrderResponseModel
-	for _, order := range orders {
-		resp := ToOrderResponse(&order)
-		if resp != nil {
-			response = append(response, resp)
+	orders, next := pagination.Trim(orders, params, config.OrderSortFields[query.SortBy], query.SortDesc, func(order types.Order) (interface{}, string) {
+		return orderSortValue(&order, query.SortBy), order.Id
+	})
+
+	page := &pagination.Page[*types.OrderResponseModel]{
+		Data:       make([]*types.OrderResponseModel, 0, len(orders)),
+		NextCursor: next,
+	}
+	for i := range orders {
+		page.Data = append(page.Data, ToOrderResponse(&orders[i]))
+	}
+
+	if params.WithTotal {
+		total, err := s.repo.CountOrders(ctx, query)
+		if err != nil {
+			return nil, err
 		}
+		page.Total = &total
 	}
 
-	return response, nil
+	return page, nil
 }
 
+// orderSortValue is the value of the field the order list is sorted by, as
+// stored in the order document.
+func orderSortValue(order *types.Order, sortBy string) interface{} {
+	switch sortBy {
+	case "updated_at":
+		return order.UpdatedAt
+	case "total_price":
+		return order.TotalPrice.Amount
+	case "status":
+		return order.Status
+	case "customer_id":
+		return order.CustomerId
+	}
+	return order.CreatedAt
+}
 
 func (s *Service) fetchCustomerByID(customerID, token string) (*types.CustomerResponseModel, error) {
 	if customerID == "" {
@@ -145,7 +279,7 @@ func (s *Service) fetchCustomerByID(customerID, token string) (*types.CustomerRe
 		"Content-Type": "application/json",
 	}
 	if token != "" {
-		headers["Authorization"] = token 
+		headers["Authorization"] = token
 	}
 
 	var customer types.CustomerResponseModel
@@ -155,66 +289,291 @@ func (s *Service) fetchCustomerByID(customerID, token string) (*types.CustomerRe
 	return &customer, nil
 }
 
-func calculateTotalPrice(items []types.OrderItem) float64 {
-	var total float64
+// resolveItems fills in the name and unit price of every item from the product
+// catalog, so the client can only choose what to order and how many.
+func (s *Service) resolveItems(items []types.OrderItem, token string) error {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductId)
+	}
+
+	products, err := s.fetchProductsByIDs(ids, token)
+	if err != nil {
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+
+	catalog := make(map[string]types.ProductResponseModel, len(products))
+	for _, p := range products {
+		catalog[p.Id] = p
+	}
+
+	for i, item := range items {
+		product, ok := catalog[item.ProductId]
+		if !ok {
+			return customError.NewNotFound(customError.ProductNotFound)
+		}
+		if !product.IsActive {
+			return customError.NewUnprocessableEntity(customError.ProductUnavailable, nil)
+		}
+		items[i].ProductName = product.Name
+		items[i].Category = product.Category
+		items[i].Weight = product.Weight
+		items[i].UnitPrice = money.FromMajor(product.Price, money.DefaultCurrency)
+	}
+	return nil
+}
+
+func (s *Service) fetchProductsByIDs(ids []string, token string) ([]types.ProductResponseModel, error) {
+	var resp types.ProductLookupResponse
+	if err := s.productClient.Post("/product/lookup", productHeaders(token), types.ProductLookupRequest{Ids: ids}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// reserveStock holds the stock of the order's items. Reservations are made
+// with the order service's own credential; customers cannot touch them.
+func (s *Service) reserveStock(order *types.Order) error {
+	items := make([]types.StockItemRequest, len(order.Items))
+	for i, item := range order.Items {
+		items[i] = types.StockItemRequest{
+			ProductId: item.ProductId,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	headers, err := serviceHeaders()
+	if err != nil {
+		return customError.NewInternal(customError.OrderServiceError, err)
+	}
+
+	body := types.ReserveStockRequest{OrderId: order.Id, Items: items}
+	var out map[string]interface{}
+	err = s.productClient.Post("/product/stock/reserve", headers, body, &out)
+	if err != nil {
+		var statusErr *client.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict {
+			return customError.NewConflict(customError.InsufficientStock)
+		}
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+	return nil
+}
+
+func (s *Service) releaseStock(orderID string) error {
+	return s.closeStockReservation("/product/stock/release", orderID)
+}
+
+func (s *Service) commitStock(orderID string) error {
+	return s.closeStockReservation("/product/stock/commit", orderID)
+}
+
+func (s *Service) closeStockReservation(path string, orderID string) error {
+	headers, err := serviceHeaders()
+	if err != nil {
+		return customError.NewInternal(customError.OrderServiceError, err)
+	}
+
+	body := types.StockReservationRequest{OrderId: orderID}
+	var out map[string]interface{}
+	err = s.productClient.Post(path, headers, body, &out)
+	if err != nil {
+		var statusErr *client.StatusError
+		if errors.As(err, &statusErr) {
+			switch statusErr.StatusCode {
+			case http.StatusNotFound:
+				// Orders placed before stock tracking have no reservation.
+				return nil
+			case http.StatusConflict:
+				return customError.NewConflict(customError.StockReservationClosed)
+			}
+		}
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+	return nil
+}
+
+func productHeaders(token string) map[string]string {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+	if token != "" {
+		headers["Authorization"] = token
+	}
+	return headers
+}
+
+// serviceHeaders authenticates a call with the order service's own token,
+// for routes only services may use.
+func serviceHeaders() (map[string]string, error) {
+	token, err := auth.GenerateServiceJWT("order")
+	if err != nil {
+		return nil, err
+	}
+	return productHeaders("Bearer " + token), nil
+}
+
+func calculateTotalPrice(items []types.OrderItem) money.Money {
+	total := money.Zero(money.DefaultCurrency)
 	for _, item := range items {
-		total += float64(item.Quantity) * item.UnitPrice
+		total = total.Add(item.UnitPrice.Mul(int64(item.Quantity)))
 	}
 	return total
 }
 
+// calculatePriceFromRepoResult applies the discounts one after the other, in
+// the order they were added to the order, each on what is left of the total.
+// Only stackable coupons can end up together on an order. Percentage
+// discounts are rounded to the minor unit before the next one is applied.
 func (s *Service) calculatePriceFromRepoResult(repoResult *types.OrderPriceInfo) *types.FinalPriceResult {
 	totalPrice := repoResult.TotalPrice
-	var discountAmount float64 = 0.0
-	var discountType string
+	remaining := totalPrice
+	var discountTypes []string
 
-	if repoResult.Discount != nil {
-		d := repoResult.Discount
-		discountType = d.Type
+	for _, d := range repoResult.Discounts {
+		if d == nil {
+			continue
+		}
 
+		amount := money.Zero(totalPrice.Currency)
 		switch d.Type {
-		case "percentage":
-			discountAmount = totalPrice * (d.Value / 100)
-		case "fixed-amount":
-			discountAmount = d.Value
+		case config.DiscountType.Percentage:
+			amount = remaining.Percent(d.Value)
+			discountTypes = append(discountTypes, d.Type)
+		case config.DiscountType.FixedAmount:
+			amount = fixedDiscountAmount(d, totalPrice.Currency)
+			discountTypes = append(discountTypes, d.Type)
 		default:
-			discountAmount = 0.0
-			discountType = "unknown"
+			discountTypes = append(discountTypes, "unknown")
 		}
-	}
 
-	finalPrice := totalPrice - discountAmount
-	if finalPrice < 0 {
-		finalPrice = 0
+		remaining = remaining.Sub(amount).ClampZero()
 	}
 
 	return &types.FinalPriceResult{
 		OriginalPrice:   totalPrice,
-		DiscountApplied: discountAmount,
-		FinalPrice:      finalPrice,
-		DiscountType:    discountType,
+		DiscountApplied: totalPrice.Sub(remaining),
+		TaxTotal:        money.Zero(totalPrice.Currency),
+		ShippingCost:    money.Zero(totalPrice.Currency),
+		FinalPrice:      remaining,
+		DiscountType:    strings.Join(discountTypes, ","),
+	}
+}
+
+// fixedDiscountAmount returns the amount of a fixed-amount discount. Older
+// discounts only carry a decimal Value, which is in the order's currency.
+func fixedDiscountAmount(d *types.Discount, currency string) money.Money {
+	if d.Amount.IsZero() {
+		return money.FromMajor(d.Value, currency)
 	}
+	if d.Amount.Currency != currency {
+		return money.Zero(currency)
+	}
+	return d.Amount
 }
 
-func (s *Service) CalculatePremiumFinalPrice(ctx context.Context, orderID string) (*types.FinalPriceResult, error) {
-	repoResult, err := s.repo.FindPriceWithMatchingDiscount(ctx, orderID, "premium")
+// priceOrder freezes the price of an order: its total, less the discounts,
+// plus the taxes of the shipping address and the shipping cost. Shipping is
+// not taxed.
+func (s *Service) priceOrder(ctx context.Context, order *types.Order, discounts []*types.Discount) (*types.FinalPriceResult, error) {
+	price := s.calculatePriceFromRepoResult(&types.OrderPriceInfo{
+		TotalPrice: order.TotalPrice,
+		Discounts:  discounts,
+	})
+
+	taxes, err := s.calculateTaxes(ctx, order.Items, order.ShippingAddress, price.OriginalPrice, price.FinalPrice)
+	if err != nil {
+		return nil, err
+	}
+	shipping, err := s.quoteShipping(ctx, order, price.FinalPrice)
 	if err != nil {
 		return nil, err
 	}
 
-	result := s.calculatePriceFromRepoResult(repoResult)
+	price.TaxLines = taxes
+	price.TaxTotal = sumTaxes(taxes, price.FinalPrice.Currency)
+	price.ShippingCost = shipping
+	price.FinalPrice = price.FinalPrice.Add(price.TaxTotal).Add(price.ShippingCost)
+	price.PricedAt = time.Now()
+	return price, nil
+}
+
+func (s *Service) CalculatePremiumFinalPrice(ctx context.Context, orderID string, currency string) (*types.FinalPriceResult, error) {
+	return s.getOrderPrice(ctx, orderID, "premium", currency)
+}
 
-	return result, nil
+func (s *Service) CalculateNonPremiumFinalPrice(ctx context.Context, orderID string, currency string) (*types.FinalPriceResult, error) {
+	return s.getOrderPrice(ctx, orderID, "non-premium", currency)
 }
 
-func (s *Service) CalculateNonPremiumFinalPrice(ctx context.Context, orderID string) (*types.FinalPriceResult, error) {
-	repoResult, err := s.repo.FindPriceWithMatchingDiscount(ctx, orderID, "non-premium")
+// getOrderPrice returns the price stored on the order. Orders placed before
+// prices were stored are still priced on the fly for the caller's membership
+// until an admin reprices them. When a currency is asked for, the price is
+// converted at the rate in effect when the order was placed.
+func (s *Service) getOrderPrice(ctx context.Context, orderID string, role string, currency string) (*types.FinalPriceResult, error) {
+	order, err := s.repo.GetByID(ctx, orderID)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println(repoResult.Discount)
-	result := s.calculatePriceFromRepoResult(repoResult)
+	price := order.Price
+	if price == nil {
+		repoResult, err := s.repo.FindPriceWithMatchingDiscount(ctx, orderID, role)
+		if err != nil {
+			return nil, err
+		}
+		price = s.calculatePriceFromRepoResult(repoResult)
+	}
 
-	return result, nil
+	return s.convertPrice(ctx, price, currency, order.CreatedAt)
+}
+
+// RepriceOrder recalculates and stores the price of an order. Until the order
+// is paid for, item prices are refreshed from the catalog as well. After
+// that, only orders without a stored price can be repriced, from their own
+// items and the discounts that match the customer's membership.
+func (s *Service) RepriceOrder(ctx context.Context, id string, token string) (*types.FinalPriceResult, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPaymentSettled(order.Payment); err != nil {
+		return nil, err
+	}
+
+	discounts := order.Discounts
+	switch {
+	case order.Status == config.OrderStatus.Ordered:
+		if err := s.resolveItems(order.Items, token); err != nil {
+			return nil, err
+		}
+		order.TotalPrice = calculateTotalPrice(order.Items)
+	case order.Price == nil:
+		customer, err := s.fetchCustomerByID(order.CustomerId, token)
+		if err != nil {
+			return nil, err
+		}
+		discounts = discountsForMembership(order.Discounts, customer.Role.Membership)
+	default:
+		return nil, customError.NewConflict(customError.OrderPriceLocked)
+	}
+
+	price, err := s.priceOrder(ctx, order, discounts)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdatePrice(ctx, id, order.Version, order.Items, order.TotalPrice, price); err != nil {
+		return nil, err
+	}
+	return price, nil
+}
+
+func discountsForMembership(discounts []*types.Discount, membership string) []*types.Discount {
+	var result []*types.Discount
+	for _, d := range discounts {
+		if d != nil && (d.Role == "" || d.Role == membership) {
+			result = append(result, d)
+		}
+	}
+	return result
 }
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"reflect"
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/money"
+	"testing"
+)
+
+func TestCalculateTotalPrice(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []types.OrderItem
+		want  money.Money
+	}{
+		{"no items", nil, money.Zero(money.DefaultCurrency)},
+		{"one item", []types.OrderItem{{Quantity: 3, UnitPrice: money.New(1250, "TRY")}}, money.New(3750, "TRY")},
+		{"several items", []types.OrderItem{
+			{Quantity: 2, UnitPrice: money.New(1999, "TRY")},
+			{Quantity: 1, UnitPrice: money.New(1, "TRY")},
+		}, money.New(3999, "TRY")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calculateTotalPrice(tt.items); got != tt.want {
+				t.Fatalf("calculateTotalPrice = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculatePriceFromRepoResult(t *testing.T) {
+	percentage := func(value float64) *types.Discount {
+		return &types.Discount{Type: config.DiscountType.Percentage, Value: value}
+	}
+	fixed := func(amount money.Money) *types.Discount {
+		return &types.Discount{Type: config.DiscountType.FixedAmount, Amount: amount}
+	}
+
+	tests := []struct {
+		name         string
+		total        money.Money
+		discounts    []*types.Discount
+		wantDiscount money.Money
+		wantFinal    money.Money
+		wantType     string
+	}{
+		{"no discount", money.New(10000, "TRY"), nil, money.New(0, "TRY"), money.New(10000, "TRY"), ""},
+		{"percentage", money.New(10000, "TRY"), []*types.Discount{percentage(15)}, money.New(1500, "TRY"), money.New(8500, "TRY"), "percentage"},
+		// 12.5% of 999 is 124.875, rounded half away from zero.
+		{"percentage rounded to the minor unit", money.New(999, "TRY"), []*types.Discount{percentage(12.5)}, money.New(125, "TRY"), money.New(874, "TRY"), "percentage"},
+		{"fixed amount", money.New(10000, "TRY"), []*types.Discount{fixed(money.New(2500, "TRY"))}, money.New(2500, "TRY"), money.New(7500, "TRY"), "fixed-amount"},
+		{"fixed amount above the total", money.New(1000, "TRY"), []*types.Discount{fixed(money.New(2500, "TRY"))}, money.New(1000, "TRY"), money.New(0, "TRY"), "fixed-amount"},
+		{"fixed amount in other currency", money.New(1000, "TRY"), []*types.Discount{fixed(money.New(500, "USD"))}, money.New(0, "TRY"), money.New(1000, "TRY"), "fixed-amount"},
+		{"legacy fixed amount in major units", money.New(10000, "TRY"), []*types.Discount{{Type: config.DiscountType.FixedAmount, Value: 12.5}}, money.New(1250, "TRY"), money.New(8750, "TRY"), "fixed-amount"},
+		// 10% of 10000, then 10% of the remaining 9000.
+		{"percentages applied one after the other", money.New(10000, "TRY"), []*types.Discount{percentage(10), percentage(10)}, money.New(1900, "TRY"), money.New(8100, "TRY"), "percentage,percentage"},
+		{"fixed then percentage", money.New(10000, "TRY"), []*types.Discount{fixed(money.New(2000, "TRY")), percentage(50)}, money.New(6000, "TRY"), money.New(4000, "TRY"), "fixed-amount,percentage"},
+		{"percentage then fixed", money.New(10000, "TRY"), []*types.Discount{percentage(50), fixed(money.New(2000, "TRY"))}, money.New(7000, "TRY"), money.New(3000, "TRY"), "percentage,fixed-amount"},
+		{"unknown discount type", money.New(10000, "TRY"), []*types.Discount{{Type: "bogo", Value: 50}}, money.New(0, "TRY"), money.New(10000, "TRY"), "unknown"},
+		{"nil discount skipped", money.New(10000, "TRY"), []*types.Discount{nil, percentage(10)}, money.New(1000, "TRY"), money.New(9000, "TRY"), "percentage"},
+		{"currency without minor units", money.New(999, "JPY"), []*types.Discount{percentage(10)}, money.New(100, "JPY"), money.New(899, "JPY"), "percentage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Service{}).calculatePriceFromRepoResult(&types.OrderPriceInfo{TotalPrice: tt.total, Discounts: tt.discounts})
+
+			if got.OriginalPrice != tt.total {
+				t.Errorf("OriginalPrice = %s, want %s", got.OriginalPrice, tt.total)
+			}
+			if got.DiscountApplied != tt.wantDiscount {
+				t.Errorf("DiscountApplied = %s, want %s", got.DiscountApplied, tt.wantDiscount)
+			}
+			if got.FinalPrice != tt.wantFinal {
+				t.Errorf("FinalPrice = %s, want %s", got.FinalPrice, tt.wantFinal)
+			}
+			if got.DiscountType != tt.wantType {
+				t.Errorf("DiscountType = %q, want %q", got.DiscountType, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestDiscountsForMembership(t *testing.T) {
+	everyone := &types.Discount{DiscountCode: "ALL"}
+	premium := &types.Discount{DiscountCode: "GOLD", Role: "premium"}
+	discounts := []*types.Discount{everyone, nil, premium}
+
+	tests := []struct {
+		membership string
+		want       []*types.Discount
+	}{
+		{"premium", []*types.Discount{everyone, premium}},
+		{"non-premium", []*types.Discount{everyone}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.membership, func(t *testing.T) {
+			if got := discountsForMembership(discounts, tt.membership); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("discountsForMembership(%q) = %v, want %v", tt.membership, got, tt.want)
+			}
+		})
+	}
+}
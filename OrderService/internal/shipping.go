@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/money"
+	"tesodev-korpes/pkg/pagination"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func (s *Service) CreateShippingMethod(ctx context.Context, req *types.ShippingMethodRequestModel) (*types.ShippingMethodResponseModel, error) {
+	method := FromShippingMethodRequest(req, normalizeShippingMethodCode(req.Code))
+	if err := method.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.shippingRepo.Create(ctx, method); err != nil {
+		return nil, err
+	}
+	return ToShippingMethodResponse(method), nil
+}
+
+func (s *Service) GetShippingMethod(ctx context.Context, code string) (*types.ShippingMethodResponseModel, error) {
+	method, err := s.shippingRepo.GetByCode(ctx, normalizeShippingMethodCode(code))
+	if err != nil {
+		return nil, err
+	}
+	return ToShippingMethodResponse(method), nil
+}
+
+func (s *Service) UpdateShippingMethod(ctx context.Context, code string, req *types.ShippingMethodRequestModel) (*types.ShippingMethodResponseModel, error) {
+	method := FromShippingMethodRequest(req, normalizeShippingMethodCode(code))
+	if err := method.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.shippingRepo.Update(ctx, method); err != nil {
+		return nil, err
+	}
+	return s.GetShippingMethod(ctx, method.Code)
+}
+
+func (s *Service) DeleteShippingMethod(ctx context.Context, code string) error {
+	return s.shippingRepo.Delete(ctx, normalizeShippingMethodCode(code))
+}
+
+// GetShippingMethods lists the methods customers can choose from; staff can
+// also see the inactive ones.
+func (s *Service) GetShippingMethods(ctx context.Context, includeInactive bool, params pagination.Params) (*pagination.Page[*types.ShippingMethodResponseModel], error) {
+	filter := bson.M{}
+	if !includeInactive {
+		filter["is_active"] = true
+	}
+
+	methods, err := s.shippingRepo.Get(ctx, filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	methods, next := pagination.Trim(methods, params, shippingMethodListField, shippingMethodListDesc, func(method types.ShippingMethod) (interface{}, string) {
+		return method.Name, method.Code
+	})
+
+	page := &pagination.Page[*types.ShippingMethodResponseModel]{
+		Data:       make([]*types.ShippingMethodResponseModel, 0, len(methods)),
+		NextCursor: next,
+	}
+	for i := range methods {
+		page.Data = append(page.Data, ToShippingMethodResponse(&methods[i]))
+	}
+
+	if params.WithTotal {
+		total, err := s.shippingRepo.Count(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+// quoteShipping prices the shipping method chosen for the order. The basket is
+// the discounted price of the items, which the free-above threshold is
+// compared with. Orders without a shipping method ship for free.
+func (s *Service) quoteShipping(ctx context.Context, order *types.Order, basket money.Money) (money.Money, error) {
+	if order.Shipping == nil {
+		return money.Zero(basket.Currency), nil
+	}
+
+	method, err := s.shippingRepo.GetByCode(ctx, order.Shipping.Method)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && !method.IsActive) {
+		return money.Money{}, customError.NewUnprocessableEntity(customError.ShippingMethodUnavailable, nil)
+	}
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	order.Shipping.MethodName = method.Name
+	return shippingCost(method, order.Items, basket), nil
+}
+
+func shippingCost(method *types.ShippingMethod, items []types.OrderItem, basket money.Money) money.Money {
+	switch method.Type {
+	case config.ShippingRateType.Weight:
+		return method.Cost.Add(method.PerKg.Mul(chargeableKilograms(items)))
+	case config.ShippingRateType.FreeAboveThreshold:
+		if !basket.LessThan(method.FreeAbove) {
+			return money.Zero(method.Cost.Currency)
+		}
+	}
+	return method.Cost
+}
+
+// chargeableKilograms is the weight of the order rounded up to the next whole
+// kilogram, since carriers charge every started kilogram. The weight is first
+// rounded to the gram so that float error, e.g. seven items of 0.4 kg and one
+// of 0.2 kg adding up to slightly more than 3 kg, does not charge an extra
+// kilogram.
+func chargeableKilograms(items []types.OrderItem) int64 {
+	var grams float64
+	for _, item := range items {
+		grams += item.Weight * 1000 * float64(item.Quantity)
+	}
+	return int64(math.Ceil(math.Round(grams) / 1000))
+}
+
+// shippingCharged is the part of what the customer paid that covers shipping.
+func shippingCharged(order *types.Order) money.Money {
+	if order.Price == nil {
+		return money.Money{}
+	}
+	return order.Price.ShippingCost
+}
+
+func normalizeShippingMethodCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/middleware"
+	"tesodev-korpes/pkg/pagination"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreateShippingMethod godoc
+// @Summary Create a shipping method
+// @Description Create a shipping method priced flat, by weight (cost plus per_kg for every started kilogram) or flat but free above a basket threshold (free_above)
+// @Tags shipping-methods
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param method body types.ShippingMethodRequestModel true "Shipping method"
+// @Success 201 {object} types.ShippingMethodResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid request body or code"
+// @Failure 409 {object} errorPackage.AppError "Shipping method already exists"
+// @Failure 422 {object} errorPackage.AppError "Invalid shipping method"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /shipping-method [post]
+func (h *Handler) CreateShippingMethod(c echo.Context) error {
+	var req types.ShippingMethodRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidShippingMethodBody)
+	}
+
+	method, err := h.service.CreateShippingMethod(c.Request().Context(), &req)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, method)
+}
+
+// GetShippingMethod godoc
+// @Summary Get a shipping method
+// @Tags shipping-methods
+// @Produce json
+// @Security ApiKeyAuth
+// @Param code path string true "Shipping method code"
+// @Success 200 {object} types.ShippingMethodResponseModel
+// @Failure 404 {object} errorPackage.AppError "Shipping method not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /shipping-method/{code} [get]
+func (h *Handler) GetShippingMethod(c echo.Context) error {
+	method, err := h.service.GetShippingMethod(c.Request().Context(), c.Param("code"))
+	if err != nil {
+		return toShippingMethodError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, method)
+}
+
+// GetShippingMethods godoc
+// @Summary List shipping methods
+// @Description List the shipping methods an order can use. Admins and managers also see inactive ones.
+// @Tags shipping-methods
+// @Produce json
+// @Security ApiKeyAuth
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Number of items per page (at most 100)" default(10)
+// @Param total query bool false "Also count all listed shipping methods"
+// @Success 200 {object} map[string]interface{} "Returns data, next_cursor and, if asked for, total"
+// @Failure 400 {object} errorPackage.AppError "Invalid cursor"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /shipping-method/list [get]
+func (h *Handler) GetShippingMethods(c echo.Context) error {
+	params, err := pagination.ParseParams(c.QueryParam("limit"), c.QueryParam("cursor"), c.QueryParam("total"))
+	if err != nil {
+		return err
+	}
+
+	page, err := h.service.GetShippingMethods(c.Request().Context(), middleware.IsStaff(c), params)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// UpdateShippingMethod godoc
+// @Summary Update a shipping method
+// @Description Replace a shipping method. Orders already priced keep their shipping cost.
+// @Tags shipping-methods
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param code path string true "Shipping method code"
+// @Param method body types.ShippingMethodRequestModel true "Shipping method"
+// @Success 200 {object} types.ShippingMethodResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 404 {object} errorPackage.AppError "Shipping method not found"
+// @Failure 422 {object} errorPackage.AppError "Invalid shipping method"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /shipping-method/{code} [put]
+func (h *Handler) UpdateShippingMethod(c echo.Context) error {
+	var req types.ShippingMethodRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidShippingMethodBody)
+	}
+
+	method, err := h.service.UpdateShippingMethod(c.Request().Context(), c.Param("code"), &req)
+	if err != nil {
+		return toShippingMethodError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, method)
+}
+
+// DeleteShippingMethod godoc
+// @Summary Delete a shipping method
+// @Tags shipping-methods
+// @Security ApiKeyAuth
+// @Param code path string true "Shipping method code"
+// @Success 204 "No Content"
+// @Failure 404 {object} errorPackage.AppError "Shipping method not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /shipping-method/{code} [delete]
+func (h *Handler) DeleteShippingMethod(c echo.Context) error {
+	if err := h.service.DeleteShippingMethod(c.Request().Context(), c.Param("code")); err != nil {
+		return toShippingMethodError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toShippingMethodError(c echo.Context, err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return customError.NewNotFound(customError.ShippingMethodNotFound)
+	}
+	return toOrderError(c, err)
+}
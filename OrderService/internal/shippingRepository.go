@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/pagination"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ShippingRepository struct {
+	collection *mongo.Collection
+}
+
+// Shipping methods are listed by name, then by _id.
+const (
+	shippingMethodListField = "name"
+	shippingMethodListDesc  = false
+)
+
+func NewShippingRepository(collection *mongo.Collection) *ShippingRepository {
+	return &ShippingRepository{collection: collection}
+}
+
+func (r *ShippingRepository) Create(ctx context.Context, method *types.ShippingMethod) error {
+	_, err := r.collection.InsertOne(ctx, method)
+	if mongo.IsDuplicateKeyError(err) {
+		return customError.NewConflict(customError.ShippingMethodAlreadyExists)
+	}
+	return err
+}
+
+func (r *ShippingRepository) GetByCode(ctx context.Context, code string) (*types.ShippingMethod, error) {
+	var method types.ShippingMethod
+	if err := r.collection.FindOne(ctx, bson.M{"_id": code}).Decode(&method); err != nil {
+		return nil, err
+	}
+	return &method, nil
+}
+
+func (r *ShippingRepository) Update(ctx context.Context, method *types.ShippingMethod) error {
+	filter := bson.M{"_id": method.Code}
+	update := bson.M{
+		"$set": bson.M{
+			"name":       method.Name,
+			"type":       method.Type,
+			"cost":       method.Cost,
+			"per_kg":     method.PerKg,
+			"free_above": method.FreeAbove,
+			"is_active":  method.IsActive,
+			"updated_at": time.Now(),
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *ShippingRepository) Delete(ctx context.Context, code string) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": code})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Get returns a page of the methods matching filter, by name, plus the first item of the next
+// page if there is one.
+func (r *ShippingRepository) Get(ctx context.Context, filter bson.M, params pagination.Params) ([]types.ShippingMethod, error) {
+	var methods []types.ShippingMethod
+
+	filter, err := pagination.After(filter, shippingMethodListField, shippingMethodListDesc, params.After)
+	if err != nil {
+		return nil, err
+	}
+	findOptions := options.Find().
+		SetSort(pagination.Sort(shippingMethodListField, shippingMethodListDesc)).
+		SetLimit(int64(params.Limit + 1))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &methods); err != nil {
+		return nil, err
+	}
+
+	return methods, nil
+}
+
+func (r *ShippingRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	return r.collection.CountDocuments(ctx, filter)
+}
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/money"
+	"testing"
+)
+
+func TestChargeableKilograms(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []types.OrderItem
+		want  int64
+	}{
+		{"no items", nil, 0},
+		{"weightless items", []types.OrderItem{{Quantity: 3}}, 0},
+		{"exact kilograms", []types.OrderItem{{Weight: 2, Quantity: 3}}, 6},
+		{"started kilogram", []types.OrderItem{{Weight: 0.25, Quantity: 1}}, 1},
+		{"rounded up", []types.OrderItem{{Weight: 1.2, Quantity: 2}, {Weight: 0.5, Quantity: 1}}, 3},
+		{"float error does not add a kilogram", []types.OrderItem{{Weight: 0.4, Quantity: 7}, {Weight: 0.2, Quantity: 1}}, 3},
+		{"tenths adding up exactly", []types.OrderItem{{Weight: 0.1, Quantity: 3}, {Weight: 0.7, Quantity: 1}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chargeableKilograms(tt.items); got != tt.want {
+				t.Fatalf("chargeableKilograms = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShippingCost(t *testing.T) {
+	flat := &types.ShippingMethod{Type: config.ShippingRateType.Flat, Cost: money.New(2999, "TRY")}
+	weight := &types.ShippingMethod{Type: config.ShippingRateType.Weight, Cost: money.New(1500, "TRY"), PerKg: money.New(500, "TRY")}
+	freeAbove := &types.ShippingMethod{Type: config.ShippingRateType.FreeAboveThreshold, Cost: money.New(2999, "TRY"), FreeAbove: money.New(50000, "TRY")}
+
+	items := []types.OrderItem{{Weight: 1.2, Quantity: 2}}
+
+	tests := []struct {
+		name   string
+		method *types.ShippingMethod
+		basket money.Money
+		want   money.Money
+	}{
+		{"flat", flat, money.New(100000, "TRY"), money.New(2999, "TRY")},
+		// 2.4 kg is charged as 3 kg.
+		{"per started kilogram", weight, money.New(100000, "TRY"), money.New(3000, "TRY")},
+		{"below the threshold", freeAbove, money.New(49999, "TRY"), money.New(2999, "TRY")},
+		{"at the threshold", freeAbove, money.New(50000, "TRY"), money.New(0, "TRY")},
+		{"above the threshold", freeAbove, money.New(80000, "TRY"), money.New(0, "TRY")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shippingCost(tt.method, items, tt.basket); got != tt.want {
+				t.Fatalf("shippingCost = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShippingCharged(t *testing.T) {
+	priced := &types.Order{Price: &types.FinalPriceResult{ShippingCost: money.New(2999, "TRY")}}
+	if got := shippingCharged(priced); got != money.New(2999, "TRY") {
+		t.Fatalf("shippingCharged = %s, want 29.99 TRY", got)
+	}
+	if got := shippingCharged(&types.Order{}); !got.IsZero() {
+		t.Fatalf("shippingCharged without a price = %s, want zero", got)
+	}
+}
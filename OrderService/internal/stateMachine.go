@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"time"
+)
+
+// checkTransition returns a conflict unless config.OrderTransitions allows an
+// order to move from one status to the other.
+func checkTransition(from, to string) error {
+	for _, next := range config.OrderTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return customError.NewConflict(customError.OrderStatusConflict, from, to)
+}
+
+func newStatusChange(from, to string, audit types.AuditInfo) types.StatusChange {
+	return types.StatusChange{
+		From:          from,
+		To:            to,
+		ActorId:       audit.ActorId,
+		CorrelationId: audit.CorrelationId,
+		Reason:        audit.Reason,
+		ChangedAt:     time.Now(),
+	}
+}
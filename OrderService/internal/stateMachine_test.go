@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/pkg/customError"
+	"testing"
+)
+
+func TestCheckTransition(t *testing.T) {
+	status := config.OrderStatus
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		allowed bool
+	}{
+		{"ordered to payment pending", status.Ordered, status.PaymentPending, true},
+		{"ordered to canceled", status.Ordered, status.Canceled, true},
+		{"payment pending to paid", status.PaymentPending, status.Paid, true},
+		{"payment pending to canceled", status.PaymentPending, status.Canceled, true},
+		{"paid to shipped", status.Paid, status.Shipped, true},
+		{"paid to canceled", status.Paid, status.Canceled, true},
+		{"shipped to delivered", status.Shipped, status.Delivered, true},
+		{"delivered to returned", status.Delivered, status.Returned, true},
+		{"returned to refunded", status.Returned, status.Refunded, true},
+
+		{"ordered straight to paid", status.Ordered, status.Paid, false},
+		{"ordered straight to shipped", status.Ordered, status.Shipped, false},
+		{"shipped cannot be canceled", status.Shipped, status.Canceled, false},
+		{"delivered cannot be canceled", status.Delivered, status.Canceled, false},
+		{"no way back from paid", status.Paid, status.Ordered, false},
+		{"same status", status.Paid, status.Paid, false},
+		{"canceled is terminal", status.Canceled, status.Ordered, false},
+		{"refunded is terminal", status.Refunded, status.Delivered, false},
+		{"unknown source", "LOST", status.Canceled, false},
+		{"unknown target", status.Ordered, "LOST", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTransition(tt.from, tt.to)
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("checkTransition(%q, %q) = %v, want nil", tt.from, tt.to, err)
+				}
+				return
+			}
+
+			var appErr *customError.AppError
+			if !errors.As(err, &appErr) || appErr.HTTPStatus != http.StatusConflict {
+				t.Fatalf("checkTransition(%q, %q) = %v, want a conflict", tt.from, tt.to, err)
+			}
+		})
+	}
+}
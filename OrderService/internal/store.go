@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/money"
+	"tesodev-korpes/pkg/pagination"
+	"time"
+)
+
+// orderStore is what the service needs from the order collection. Repository
+// implements it against MongoDB; tests use an in-memory store. Updates take
+// the version the order was read at and fail with OrderVersionConflict when
+// it has moved on, or when a status change's From no longer matches.
+type orderStore interface {
+	Create(ctx context.Context, order *types.Order) (string, error)
+	GetByID(ctx context.Context, id string) (*types.Order, error)
+	UpdateStatusByID(ctx context.Context, id string, version int, change types.StatusChange) error
+	UpdatePayment(ctx context.Context, id string, version int, payment *types.Payment, change *types.StatusChange) error
+	UpdateReturns(ctx context.Context, id string, version int, returns []types.ReturnRequest, payment *types.Payment, changes ...types.StatusChange) error
+	UpdatePrice(ctx context.Context, id string, version int, items []types.OrderItem, total money.Money, price *types.FinalPriceResult) error
+	UpdateShipping(ctx context.Context, id string, version int, shipping *types.Shipment, change types.StatusChange) error
+	SoftDeleteByID(ctx context.Context, id string) error
+	GetAllOrders(ctx context.Context, query *types.OrderListQuery, params pagination.Params) ([]types.Order, error)
+	CountOrders(ctx context.Context, query *types.OrderListQuery) (int64, error)
+	FindPriceWithMatchingDiscount(ctx context.Context, orderID string, role string) (*types.OrderPriceInfo, error)
+}
+
+// couponStore is what the service needs from the coupon and redemption
+// collections. Redeem enforces the usage limits and succeeds again for an
+// order that already holds a use; Release does nothing for an order without
+// one.
+type couponStore interface {
+	Create(ctx context.Context, coupon *types.Coupon) error
+	GetByCode(ctx context.Context, code string) (*types.Coupon, error)
+	GetByCodes(ctx context.Context, codes []string) ([]types.Coupon, error)
+	Update(ctx context.Context, coupon *types.Coupon) error
+	Delete(ctx context.Context, code string) error
+	Get(ctx context.Context, params pagination.Params) ([]types.Coupon, error)
+	Count(ctx context.Context) (int64, error)
+	CustomerRedemptions(ctx context.Context, code string, customerID string) (int, error)
+	Redeem(ctx context.Context, coupon *types.Coupon, customerID string, orderID string) error
+	Release(ctx context.Context, code string, customerID string, orderID string) error
+}
+
+// cartStore is what the service needs from the cart collection. Claim holds
+// the cart for one checkout at a time; Unclaim and Clear only act on the claim
+// made at claimedAt.
+type cartStore interface {
+	Get(ctx context.Context, customerID string) (*types.Cart, error)
+	AddItem(ctx context.Context, customerID string, productID string, quantity int) error
+	SetQuantity(ctx context.Context, customerID string, productID string, quantity int) error
+	RemoveItem(ctx context.Context, customerID string, productID string) error
+	SetDiscountCodes(ctx context.Context, customerID string, codes []string) error
+	Claim(ctx context.Context, customerID string, now time.Time) (*types.Cart, error)
+	Unclaim(ctx context.Context, customerID string, claimedAt time.Time) error
+	Clear(ctx context.Context, customerID string, claimedAt time.Time) error
+}
@@ -0,0 +1,254 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryOrders is an in-memory orderStore with the same version checks as
+// Repository. Orders go in and out as copies, so the service cannot change a
+// stored order without saving it. Methods the tests do not need are left to
+// the embedded nil interface and panic.
+type memoryOrders struct {
+	orderStore
+	orders map[string]*types.Order
+	// afterGet, when set, runs once after the next GetByID, to let a test
+	// change the order behind the caller's back.
+	afterGet func()
+}
+
+func newMemoryOrders(orders ...*types.Order) *memoryOrders {
+	m := &memoryOrders{orders: map[string]*types.Order{}}
+	for _, order := range orders {
+		m.orders[order.Id] = copyOrder(order)
+	}
+	return m
+}
+
+// order returns the stored order, failing the test if there is none.
+func (m *memoryOrders) order(t *testing.T, id string) *types.Order {
+	t.Helper()
+	order, ok := m.orders[id]
+	if !ok {
+		t.Fatalf("order %q not stored", id)
+	}
+	return copyOrder(order)
+}
+
+func (m *memoryOrders) Create(_ context.Context, order *types.Order) (string, error) {
+	if _, ok := m.orders[order.Id]; ok {
+		return "", errors.New("duplicate order id")
+	}
+	m.orders[order.Id] = copyOrder(order)
+	return order.Id, nil
+}
+
+func (m *memoryOrders) GetByID(_ context.Context, id string) (*types.Order, error) {
+	order, ok := m.orders[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	order = copyOrder(order)
+	if hook := m.afterGet; hook != nil {
+		m.afterGet = nil
+		hook()
+	}
+	return order, nil
+}
+
+func (m *memoryOrders) UpdateStatusByID(_ context.Context, id string, version int, change types.StatusChange) error {
+	return m.update(id, version, func(*types.Order) {}, change)
+}
+
+func (m *memoryOrders) UpdatePayment(_ context.Context, id string, version int, payment *types.Payment, change *types.StatusChange) error {
+	var changes []types.StatusChange
+	if change != nil {
+		changes = append(changes, *change)
+	}
+	return m.update(id, version, func(o *types.Order) { o.Payment = payment }, changes...)
+}
+
+func (m *memoryOrders) UpdateReturns(_ context.Context, id string, version int, returns []types.ReturnRequest, payment *types.Payment, changes ...types.StatusChange) error {
+	return m.update(id, version, func(o *types.Order) {
+		o.Returns = returns
+		if payment != nil {
+			o.Payment = payment
+		}
+	}, changes...)
+}
+
+// update applies set to a copy of the order under the same conditions as
+// Repository.updateVersioned.
+func (m *memoryOrders) update(id string, version int, set func(*types.Order), changes ...types.StatusChange) error {
+	stored, ok := m.orders[id]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	if stored.Version != version || (len(changes) > 0 && stored.Status != changes[0].From) {
+		return customError.NewConflict(customError.OrderVersionConflict)
+	}
+
+	order := copyOrder(stored)
+	set(order)
+	order = copyOrder(order)
+	if len(changes) > 0 {
+		order.Status = changes[len(changes)-1].To
+		order.StatusHistory = append(order.StatusHistory, changes...)
+	}
+	order.Version++
+	m.orders[id] = order
+	return nil
+}
+
+func copyOrder(order *types.Order) *types.Order {
+	data, err := bson.Marshal(order)
+	if err != nil {
+		panic(err)
+	}
+	var c types.Order
+	if err := bson.Unmarshal(data, &c); err != nil {
+		panic(err)
+	}
+	return &c
+}
+
+// memoryCoupons is an in-memory couponStore with the limits and retry rules of
+// CouponRepository. Redemptions are kept as the order ids per "code:customer".
+type memoryCoupons struct {
+	couponStore
+	coupons     map[string]*types.Coupon
+	redemptions map[string][]string
+}
+
+func newMemoryCoupons(coupons ...*types.Coupon) *memoryCoupons {
+	m := &memoryCoupons{coupons: map[string]*types.Coupon{}, redemptions: map[string][]string{}}
+	for _, coupon := range coupons {
+		c := *coupon
+		m.coupons[c.Code] = &c
+	}
+	return m
+}
+
+func (m *memoryCoupons) GetByCode(_ context.Context, code string) (*types.Coupon, error) {
+	coupon, ok := m.coupons[code]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	c := *coupon
+	return &c, nil
+}
+
+func (m *memoryCoupons) Redeem(_ context.Context, coupon *types.Coupon, customerID string, orderID string) error {
+	id := redemptionID(coupon.Code, customerID)
+	orders := m.redemptions[id]
+	if slices.Contains(orders, orderID) {
+		return nil
+	}
+
+	stored := m.coupons[coupon.Code]
+	if stored == nil || !stored.IsActive || (stored.UsageLimit > 0 && stored.UsedCount >= stored.UsageLimit) {
+		return customError.NewUnprocessableEntity(customError.CouponUsageLimitReached, nil)
+	}
+	if coupon.PerCustomerLimit > 0 && len(orders) >= coupon.PerCustomerLimit {
+		return customError.NewUnprocessableEntity(customError.CouponCustomerLimitReached, nil)
+	}
+	stored.UsedCount++
+	m.redemptions[id] = append(orders, orderID)
+	return nil
+}
+
+func (m *memoryCoupons) Release(_ context.Context, code string, customerID string, orderID string) error {
+	id := redemptionID(code, customerID)
+	i := slices.Index(m.redemptions[id], orderID)
+	if i < 0 {
+		return nil
+	}
+	m.redemptions[id] = slices.Delete(m.redemptions[id], i, i+1)
+	if stored := m.coupons[code]; stored != nil && stored.UsedCount > 0 {
+		stored.UsedCount--
+	}
+	return nil
+}
+
+// memoryCarts is an in-memory cartStore with the claim rules of
+// CartRepository. It is locked, since a test may check out from within a
+// checkout that is waiting on another service.
+type memoryCarts struct {
+	cartStore
+	mu    sync.Mutex
+	carts map[string]*types.Cart
+}
+
+func newMemoryCarts(carts ...*types.Cart) *memoryCarts {
+	m := &memoryCarts{carts: map[string]*types.Cart{}}
+	for _, cart := range carts {
+		m.carts[cart.CustomerId] = copyCart(cart)
+	}
+	return m
+}
+
+// cart returns the stored cart, or nil if there is none.
+func (m *memoryCarts) cart(customerID string) *types.Cart {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cart, ok := m.carts[customerID]; ok {
+		return copyCart(cart)
+	}
+	return nil
+}
+
+func (m *memoryCarts) Get(_ context.Context, customerID string) (*types.Cart, error) {
+	if cart := m.cart(customerID); cart != nil {
+		return cart, nil
+	}
+	return &types.Cart{CustomerId: customerID, Items: []types.CartItem{}}, nil
+}
+
+func (m *memoryCarts) Claim(_ context.Context, customerID string, now time.Time) (*types.Cart, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cart, ok := m.carts[customerID]
+	if !ok || (cart.CheckoutAt != nil && !cart.CheckoutAt.Before(now.Add(-checkoutClaimTimeout))) {
+		return nil, customError.NewConflict(customError.CartCheckoutInProgress)
+	}
+	cart.CheckoutAt = &now
+	return copyCart(cart), nil
+}
+
+func (m *memoryCarts) Unclaim(_ context.Context, customerID string, claimedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cart, ok := m.carts[customerID]; ok && cart.CheckoutAt != nil && cart.CheckoutAt.Equal(claimedAt) {
+		cart.CheckoutAt = nil
+	}
+	return nil
+}
+
+func (m *memoryCarts) Clear(_ context.Context, customerID string, claimedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cart, ok := m.carts[customerID]; ok && cart.CheckoutAt != nil && cart.CheckoutAt.Equal(claimedAt) {
+		delete(m.carts, customerID)
+	}
+	return nil
+}
+
+func copyCart(cart *types.Cart) *types.Cart {
+	c := *cart
+	c.Items = slices.Clone(cart.Items)
+	c.DiscountCodes = slices.Clone(cart.DiscountCodes)
+	if cart.CheckoutAt != nil {
+		at := *cart.CheckoutAt
+		c.CheckoutAt = &at
+	}
+	return &c
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"context"
+	"strings"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/money"
+	"tesodev-korpes/pkg/pagination"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (s *Service) CreateTaxRule(ctx context.Context, req *types.TaxRuleRequestModel) (*types.TaxRuleResponseModel, error) {
+	rule := FromTaxRuleRequest(req)
+	if err := s.taxRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return ToTaxRuleResponse(rule), nil
+}
+
+func (s *Service) GetTaxRule(ctx context.Context, id string) (*types.TaxRuleResponseModel, error) {
+	rule, err := s.taxRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ToTaxRuleResponse(rule), nil
+}
+
+func (s *Service) UpdateTaxRule(ctx context.Context, id string, req *types.TaxRuleRequestModel) (*types.TaxRuleResponseModel, error) {
+	rule := FromTaxRuleRequest(req)
+	rule.Id = id
+	if err := s.taxRepo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+	return s.GetTaxRule(ctx, id)
+}
+
+func (s *Service) DeleteTaxRule(ctx context.Context, id string) error {
+	return s.taxRepo.Delete(ctx, id)
+}
+
+func (s *Service) GetTaxRules(ctx context.Context, state string, params pagination.Params) (*pagination.Page[*types.TaxRuleResponseModel], error) {
+	filter := bson.M{}
+	if state != "" {
+		filter["state"] = normalizeState(state)
+	}
+
+	rules, err := s.taxRepo.Get(ctx, filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, next := pagination.Trim(rules, params, taxRuleListField, taxRuleListDesc, func(rule types.TaxRule) (interface{}, string) {
+		return rule.State, rule.Id
+	})
+
+	page := &pagination.Page[*types.TaxRuleResponseModel]{
+		Data:       make([]*types.TaxRuleResponseModel, 0, len(rules)),
+		NextCursor: next,
+	}
+	for i := range rules {
+		page.Data = append(page.Data, ToTaxRuleResponse(&rules[i]))
+	}
+
+	if params.WithTotal {
+		total, err := s.taxRepo.Count(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+// calculateTaxes returns one tax line per rule matching the shipping address.
+func (s *Service) calculateTaxes(ctx context.Context, items []types.OrderItem, address types.Address, original money.Money, discounted money.Money) ([]types.TaxLine, error) {
+	rules, err := s.taxRepo.GetActiveByState(ctx, normalizeState(address.State))
+	if err != nil {
+		return nil, err
+	}
+	return taxLines(rules, items, address.ZipCode, original, discounted), nil
+}
+
+// taxLines applies the rules of the address's state. A rule taxes the items
+// outside its exempt categories, after the order's discounts, which are spread
+// over the items in proportion to their price.
+func taxLines(rules []types.TaxRule, items []types.OrderItem, zipCode string, original money.Money, discounted money.Money) []types.TaxLine {
+	zip := strings.TrimSpace(zipCode)
+	var lines []types.TaxLine
+	for _, rule := range rules {
+		if rule.ZipPrefix != "" && !strings.HasPrefix(zip, rule.ZipPrefix) {
+			continue
+		}
+
+		exempt := make(map[string]bool, len(rule.ExemptCategories))
+		for _, category := range rule.ExemptCategories {
+			exempt[normalizeCategory(category)] = true
+		}
+
+		base := money.Zero(original.Currency)
+		for _, item := range items {
+			if !exempt[normalizeCategory(item.Category)] {
+				base = base.Add(item.UnitPrice.Mul(int64(item.Quantity)))
+			}
+		}
+		taxable := base.MulDiv(discounted.Amount, original.Amount)
+		if !taxable.IsPositive() {
+			continue
+		}
+
+		lines = append(lines, types.TaxLine{
+			RuleId:        rule.Id,
+			Name:          rule.Name,
+			Rate:          rule.Rate,
+			TaxableAmount: taxable,
+			Amount:        taxable.Percent(rule.Rate),
+		})
+	}
+	return lines
+}
+
+func sumTaxes(lines []types.TaxLine, currency string) money.Money {
+	total := money.Zero(currency)
+	for _, line := range lines {
+		total = total.Add(line.Amount)
+	}
+	return total
+}
+
+func normalizeState(state string) string {
+	return strings.ToUpper(strings.TrimSpace(state))
+}
+
+func normalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/pagination"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreateTaxRule godoc
+// @Summary Create a tax rule
+// @Description Create a tax rule for a state, or for the zip codes of a state starting with zip_prefix. Orders shipped to a matching address get one tax line per rule.
+// @Tags tax-rules
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param rule body types.TaxRuleRequestModel true "Tax rule"
+// @Success 201 {object} types.TaxRuleResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 422 {object} errorPackage.AppError "Invalid tax rule"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /tax-rule [post]
+func (h *Handler) CreateTaxRule(c echo.Context) error {
+	var req types.TaxRuleRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidTaxRuleBody)
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	rule, err := h.service.CreateTaxRule(c.Request().Context(), &req)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, rule)
+}
+
+// GetTaxRule godoc
+// @Summary Get a tax rule
+// @Tags tax-rules
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Tax rule ID (UUID)"
+// @Success 200 {object} types.TaxRuleResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Tax rule not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /tax-rule/{id} [get]
+func (h *Handler) GetTaxRule(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidTaxRuleID)
+	}
+
+	rule, err := h.service.GetTaxRule(c.Request().Context(), id)
+	if err != nil {
+		return toTaxRuleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, rule)
+}
+
+// GetTaxRules godoc
+// @Summary List tax rules
+// @Description List tax rules, optionally of one state
+// @Tags tax-rules
+// @Produce json
+// @Security ApiKeyAuth
+// @Param state query string false "State"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Number of items per page (at most 100)" default(10)
+// @Param total query bool false "Also count all matching tax rules"
+// @Success 200 {object} map[string]interface{} "Returns data, next_cursor and, if asked for, total"
+// @Failure 400 {object} errorPackage.AppError "Invalid cursor"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /tax-rule/list [get]
+func (h *Handler) GetTaxRules(c echo.Context) error {
+	params, err := pagination.ParseParams(c.QueryParam("limit"), c.QueryParam("cursor"), c.QueryParam("total"))
+	if err != nil {
+		return err
+	}
+
+	page, err := h.service.GetTaxRules(c.Request().Context(), c.QueryParam("state"), params)
+	if err != nil {
+		return toOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// UpdateTaxRule godoc
+// @Summary Update a tax rule
+// @Description Replace a tax rule. Orders already priced keep the tax lines they were priced with.
+// @Tags tax-rules
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Tax rule ID (UUID)"
+// @Param rule body types.TaxRuleRequestModel true "Tax rule"
+// @Success 200 {object} types.TaxRuleResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format or request body"
+// @Failure 404 {object} errorPackage.AppError "Tax rule not found"
+// @Failure 422 {object} errorPackage.AppError "Invalid tax rule"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /tax-rule/{id} [put]
+func (h *Handler) UpdateTaxRule(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidTaxRuleID)
+	}
+
+	var req types.TaxRuleRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidTaxRuleBody)
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	rule, err := h.service.UpdateTaxRule(c.Request().Context(), id, &req)
+	if err != nil {
+		return toTaxRuleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, rule)
+}
+
+// DeleteTaxRule godoc
+// @Summary Delete a tax rule
+// @Tags tax-rules
+// @Security ApiKeyAuth
+// @Param id path string true "Tax rule ID (UUID)"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Tax rule not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /tax-rule/{id} [delete]
+func (h *Handler) DeleteTaxRule(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidTaxRuleID)
+	}
+
+	if err := h.service.DeleteTaxRule(c.Request().Context(), id); err != nil {
+		return toTaxRuleError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toTaxRuleError(c echo.Context, err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return customError.NewNotFound(customError.TaxRuleNotFound)
+	}
+	return toOrderError(c, err)
+}
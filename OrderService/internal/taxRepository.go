@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/pagination"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TaxRepository struct {
+	collection *mongo.Collection
+}
+
+// Tax rules are listed by state, then by _id.
+const (
+	taxRuleListField = "state"
+	taxRuleListDesc  = false
+)
+
+func NewTaxRepository(collection *mongo.Collection) *TaxRepository {
+	return &TaxRepository{collection: collection}
+}
+
+func (r *TaxRepository) Create(ctx context.Context, rule *types.TaxRule) error {
+	_, err := r.collection.InsertOne(ctx, rule)
+	return err
+}
+
+func (r *TaxRepository) GetByID(ctx context.Context, id string) (*types.TaxRule, error) {
+	var rule types.TaxRule
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *TaxRepository) Update(ctx context.Context, rule *types.TaxRule) error {
+	filter := bson.M{"_id": rule.Id}
+	update := bson.M{
+		"$set": bson.M{
+			"name":              rule.Name,
+			"state":             rule.State,
+			"zip_prefix":        rule.ZipPrefix,
+			"rate":              rule.Rate,
+			"exempt_categories": rule.ExemptCategories,
+			"is_active":         rule.IsActive,
+			"updated_at":        time.Now(),
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *TaxRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Get returns a page of the rules matching filter, by state, plus the first item of the next
+// page if there is one.
+func (r *TaxRepository) Get(ctx context.Context, filter bson.M, params pagination.Params) ([]types.TaxRule, error) {
+	var rules []types.TaxRule
+
+	filter, err := pagination.After(filter, taxRuleListField, taxRuleListDesc, params.After)
+	if err != nil {
+		return nil, err
+	}
+	findOptions := options.Find().
+		SetSort(pagination.Sort(taxRuleListField, taxRuleListDesc)).
+		SetLimit(int64(params.Limit + 1))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (r *TaxRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+// GetActiveByState returns the active rules of a state, the state-wide ones
+// first, so tax lines always come out in the same order.
+func (r *TaxRepository) GetActiveByState(ctx context.Context, state string) ([]types.TaxRule, error) {
+	findOptions := options.Find().SetSort(bson.D{
+		{Key: "zip_prefix", Value: 1},
+		{Key: "name", Value: 1},
+	})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"state": state, "is_active": true}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []types.TaxRule
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"reflect"
+	"tesodev-korpes/OrderService/internal/types"
+	"tesodev-korpes/pkg/money"
+	"testing"
+)
+
+func TestTaxLines(t *testing.T) {
+	items := []types.OrderItem{
+		{ProductId: "book", Category: "Books", Quantity: 2, UnitPrice: money.New(2000, "TRY")},
+		{ProductId: "lamp", Category: "home", Quantity: 1, UnitPrice: money.New(6000, "TRY")},
+	}
+	original := money.New(10000, "TRY")
+
+	state := types.TaxRule{Id: "state", Name: "State tax", Rate: 18}
+	city := types.TaxRule{Id: "city", Name: "City tax", ZipPrefix: "34", Rate: 2.5}
+	booksExempt := types.TaxRule{Id: "no-books", Name: "Sales tax", Rate: 10, ExemptCategories: []string{" books "}}
+
+	tests := []struct {
+		name       string
+		rules      []types.TaxRule
+		zip        string
+		discounted money.Money
+		want       []types.TaxLine
+	}{
+		{"no rules", nil, "34000", original, nil},
+		{"state rule", []types.TaxRule{state}, "06000", original, []types.TaxLine{
+			{RuleId: "state", Name: "State tax", Rate: 18, TaxableAmount: money.New(10000, "TRY"), Amount: money.New(1800, "TRY")},
+		}},
+		{"local rule inside its zip prefix", []types.TaxRule{state, city}, " 34710 ", original, []types.TaxLine{
+			{RuleId: "state", Name: "State tax", Rate: 18, TaxableAmount: money.New(10000, "TRY"), Amount: money.New(1800, "TRY")},
+			{RuleId: "city", Name: "City tax", Rate: 2.5, TaxableAmount: money.New(10000, "TRY"), Amount: money.New(250, "TRY")},
+		}},
+		{"local rule outside its zip prefix", []types.TaxRule{state, city}, "06000", original, []types.TaxLine{
+			{RuleId: "state", Name: "State tax", Rate: 18, TaxableAmount: money.New(10000, "TRY"), Amount: money.New(1800, "TRY")},
+		}},
+		{"exempt category", []types.TaxRule{booksExempt}, "06000", original, []types.TaxLine{
+			{RuleId: "no-books", Name: "Sales tax", Rate: 10, TaxableAmount: money.New(6000, "TRY"), Amount: money.New(600, "TRY")},
+		}},
+		// The 25% discount is spread over the items: 6000 of lamps become 4500.
+		{"after discount", []types.TaxRule{booksExempt}, "06000", money.New(7500, "TRY"), []types.TaxLine{
+			{RuleId: "no-books", Name: "Sales tax", Rate: 10, TaxableAmount: money.New(4500, "TRY"), Amount: money.New(450, "TRY")},
+		}},
+		// 6000 * 3333 / 10000 = 1999.8, and 2.5% of 2000 is exactly 50.
+		{"discount share rounded", []types.TaxRule{{Id: "r", Name: "R", Rate: 2.5, ExemptCategories: []string{"books"}}}, "06000", money.New(3333, "TRY"), []types.TaxLine{
+			{RuleId: "r", Name: "R", Rate: 2.5, TaxableAmount: money.New(2000, "TRY"), Amount: money.New(50, "TRY")},
+		}},
+		{"nothing left to tax", []types.TaxRule{state}, "06000", money.New(0, "TRY"), nil},
+		{"every item exempt", []types.TaxRule{{Id: "r", Name: "R", Rate: 10, ExemptCategories: []string{"books", "HOME"}}}, "06000", original, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := taxLines(tt.rules, items, tt.zip, original, tt.discounted)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("taxLines =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSumTaxes(t *testing.T) {
+	lines := []types.TaxLine{{Amount: money.New(1800, "TRY")}, {Amount: money.New(250, "TRY")}}
+
+	if got := sumTaxes(lines, "TRY"); got != money.New(2050, "TRY") {
+		t.Fatalf("sumTaxes = %s, want 20.50 TRY", got)
+	}
+	if got := sumTaxes(nil, "TRY"); got != money.Zero("TRY") {
+		t.Fatalf("sumTaxes(nil) = %s, want 0.00 TRY", got)
+	}
+}
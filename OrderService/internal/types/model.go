@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"tesodev-korpes/pkg/money"
+	"time"
+)
 
 type Discount struct {
 	Id           string    `bson:"_id"`
@@ -9,28 +12,200 @@ type Discount struct {
 	EndDate      time.Time `bson:"end_date"`
 	DiscountCode string    `bson:"discount_code"`
 	Type         string    `bson:"type"`
-	Value        float64   `bson:"value"`
+	// Value is the rate of a percentage discount, Amount the amount of a
+	// fixed-amount one. Fixed-amount discounts stored before amounts carried
+	// a currency only have Value, in major units.
+	Value  float64     `bson:"value,omitempty"`
+	Amount money.Money `bson:"amount,omitempty"`
 }
 
 type Order struct {
-	Id              string      `bson:"_id,omitempty"`
-	CustomerId      string      `bson:"customer_id"`
-	Items           []OrderItem `bson:"items"`
-	ShippingAddress Address     `bson:"shipping_address"`
-	BillingAddress  Address     `bson:"billing_address"`
-	TotalPrice      float64     `bson:"total_price"`
-	Discounts       []*Discount `bson:"discount,omitempty"`
-	Status          string      `bson:"status"`
-	CreatedAt       time.Time   `bson:"created_at"`
-	UpdatedAt       time.Time   `bson:"updated_at"`
-	IsDelete        bool        `bson:"is_delete"`
+	Id              string            `bson:"_id,omitempty"`
+	CustomerId      string            `bson:"customer_id"`
+	Items           []OrderItem       `bson:"items"`
+	ShippingAddress Address           `bson:"shipping_address"`
+	BillingAddress  Address           `bson:"billing_address"`
+	TotalPrice      money.Money       `bson:"total_price"`
+	DiscountCodes   []string          `bson:"discount_codes,omitempty"`
+	Discounts       []*Discount       `bson:"discount,omitempty"`
+	Price           *FinalPriceResult `bson:"price,omitempty"`
+	Shipping        *Shipment         `bson:"shipping,omitempty"`
+	Status          string            `bson:"status"`
+	StatusHistory   []StatusChange    `bson:"status_history"`
+	Payment         *Payment          `bson:"payment,omitempty"`
+	Returns         []ReturnRequest   `bson:"returns,omitempty"`
+	Version         int               `bson:"version"`
+	CreatedAt       time.Time         `bson:"created_at"`
+	UpdatedAt       time.Time         `bson:"updated_at"`
+	IsDelete        bool              `bson:"is_delete"`
+}
+
+// StatusChange is one entry of the append-only audit trail of an order.
+type StatusChange struct {
+	From          string    `bson:"from,omitempty" json:"from,omitempty"`
+	To            string    `bson:"to" json:"to"`
+	ActorId       string    `bson:"actor_id" json:"actor_id"`
+	CorrelationId string    `bson:"correlation_id,omitempty" json:"correlation_id,omitempty"`
+	Reason        string    `bson:"reason,omitempty" json:"reason,omitempty"`
+	ChangedAt     time.Time `bson:"changed_at" json:"changed_at"`
+}
+
+// Payment is the payment record of an order, kept on the order document so it
+// changes together with the order status.
+type Payment struct {
+	Gateway         string               `bson:"gateway" json:"gateway"`
+	Status          string               `bson:"status" json:"status"`
+	Amount          money.Money          `bson:"amount" json:"amount"`
+	CapturedAmount  money.Money          `bson:"captured_amount" json:"captured_amount"`
+	RefundedAmount  money.Money          `bson:"refunded_amount" json:"refunded_amount"`
+	AuthorizationId string               `bson:"authorization_id,omitempty" json:"authorization_id,omitempty"`
+	CaptureId       string               `bson:"capture_id,omitempty" json:"capture_id,omitempty"`
+	Transactions    []PaymentTransaction `bson:"transactions" json:"transactions"`
+	CreatedAt       time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+type PaymentTransaction struct {
+	Type      string      `bson:"type" json:"type"`
+	Amount    money.Money `bson:"amount" json:"amount"`
+	Reference string      `bson:"reference" json:"reference"`
+	Approved  bool        `bson:"approved" json:"approved"`
+	Message   string      `bson:"message,omitempty" json:"message,omitempty"`
+	CreatedAt time.Time   `bson:"created_at" json:"created_at"`
+}
+
+// ReturnRequest is a customer's request to send back some or all items of a
+// delivered order. RefundAmount is filled in when staff approve it.
+type ReturnRequest struct {
+	Id           string       `bson:"return_id" json:"id"`
+	Items        []ReturnItem `bson:"items" json:"items"`
+	Reason       string       `bson:"reason,omitempty" json:"reason,omitempty"`
+	Status       string       `bson:"status" json:"status"`
+	RefundAmount money.Money  `bson:"refund_amount" json:"refund_amount"`
+	RequestedBy  string       `bson:"requested_by" json:"requested_by"`
+	ReviewedBy   string       `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+	ReviewNote   string       `bson:"review_note,omitempty" json:"review_note,omitempty"`
+	CreatedAt    time.Time    `bson:"created_at" json:"created_at"`
+	ReviewedAt   *time.Time   `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+}
+
+type ReturnItem struct {
+	ProductId string `bson:"product_id" json:"product_id"`
+	Quantity  int    `bson:"quantity" json:"quantity"`
+}
+
+// Cart is the persistent shopping cart of a customer, keyed by customer id.
+// Only product ids and quantities are kept; names and prices are always taken
+// from the catalog when the cart is shown or checked out.
+type Cart struct {
+	CustomerId    string     `bson:"_id"`
+	Items         []CartItem `bson:"items"`
+	DiscountCodes []string   `bson:"discount_codes,omitempty"`
+	CheckoutAt    *time.Time `bson:"checkout_at,omitempty"`
+	CreatedAt     time.Time  `bson:"created_at"`
+	UpdatedAt     time.Time  `bson:"updated_at"`
+}
+
+// Coupon is a discount code managed by admins. Orders only reference coupons
+// by code; the server checks them and keeps a Discount snapshot on the order,
+// so later changes to the coupon do not affect orders already placed.
+type Coupon struct {
+	Code             string      `bson:"_id"`
+	Description      string      `bson:"description"`
+	Type             string      `bson:"type"`
+	Value            float64     `bson:"value,omitempty"`
+	Amount           money.Money `bson:"amount,omitempty"`
+	Role             string      `bson:"role,omitempty"`
+	StartDate        time.Time   `bson:"start_date"`
+	EndDate          time.Time   `bson:"end_date"`
+	MinBasket        money.Money `bson:"min_basket,omitempty"`
+	UsageLimit       int         `bson:"usage_limit"`
+	PerCustomerLimit int         `bson:"per_customer_limit"`
+	UsedCount        int         `bson:"used_count"`
+	Stackable        bool        `bson:"stackable"`
+	IsActive         bool        `bson:"is_active"`
+	CreatedAt        time.Time   `bson:"created_at"`
+	UpdatedAt        time.Time   `bson:"updated_at"`
+}
+
+// CouponRedemption counts how many orders of a customer used a coupon. The id
+// is "<code>:<customer id>".
+type CouponRedemption struct {
+	Id         string   `bson:"_id"`
+	Code       string   `bson:"code"`
+	CustomerId string   `bson:"customer_id"`
+	OrderIds   []string `bson:"order_ids"`
+	Count      int      `bson:"count"`
+}
+
+// ExchangeRate is the price of one unit of From in To, effective from
+// EffectiveFrom until the next rate for the same pair takes over. Rates are
+// never edited, so any past conversion can be repeated. Rate is a decimal
+// string to keep it exact.
+type ExchangeRate struct {
+	Id            string    `bson:"_id"`
+	From          string    `bson:"from"`
+	To            string    `bson:"to"`
+	Rate          string    `bson:"rate"`
+	EffectiveFrom time.Time `bson:"effective_from"`
+	CreatedBy     string    `bson:"created_by"`
+	CreatedAt     time.Time `bson:"created_at"`
+}
+
+// Shipment is how an order travels: the shipping method chosen at checkout,
+// and the carrier and tracking number given when the order is shipped.
+type Shipment struct {
+	Method         string     `bson:"method" json:"method"`
+	MethodName     string     `bson:"method_name" json:"method_name"`
+	Carrier        string     `bson:"carrier,omitempty" json:"carrier,omitempty"`
+	TrackingNumber string     `bson:"tracking_number,omitempty" json:"tracking_number,omitempty"`
+	ShippedAt      *time.Time `bson:"shipped_at,omitempty" json:"shipped_at,omitempty"`
+}
+
+// ShippingMethod is a delivery option priced by its Type: a flat Cost, Cost
+// plus PerKg for every started kilogram of the order, or a flat Cost waived
+// once the discounted basket reaches FreeAbove. The code is the id.
+type ShippingMethod struct {
+	Code      string      `bson:"_id"`
+	Name      string      `bson:"name"`
+	Type      string      `bson:"type"`
+	Cost      money.Money `bson:"cost"`
+	PerKg     money.Money `bson:"per_kg,omitempty"`
+	FreeAbove money.Money `bson:"free_above,omitempty"`
+	IsActive  bool        `bson:"is_active"`
+	CreatedAt time.Time   `bson:"created_at"`
+	UpdatedAt time.Time   `bson:"updated_at"`
+}
+
+// TaxRule is a sales tax of a state, or of the zip codes starting with
+// ZipPrefix within it. Every rule that matches the shipping address adds a
+// tax line, so a state tax and a local tax are two rules. Products in one of
+// the ExemptCategories are not taxed by the rule.
+type TaxRule struct {
+	Id               string    `bson:"_id"`
+	Name             string    `bson:"name"`
+	State            string    `bson:"state"`
+	ZipPrefix        string    `bson:"zip_prefix,omitempty"`
+	Rate             float64   `bson:"rate"`
+	ExemptCategories []string  `bson:"exempt_categories,omitempty"`
+	IsActive         bool      `bson:"is_active"`
+	CreatedAt        time.Time `bson:"created_at"`
+	UpdatedAt        time.Time `bson:"updated_at"`
+}
+
+type CartItem struct {
+	ProductId string    `bson:"product_id"`
+	Quantity  int       `bson:"quantity"`
+	AddedAt   time.Time `bson:"added_at"`
 }
 
 type OrderItem struct {
-	ProductId   string  `bson:"product_id"`
-	ProductName string  `bson:"product_name"`
-	Quantity    int     `bson:"quantity"`
-	UnitPrice   float64 `bson:"unit_price"`
+	ProductId   string      `bson:"product_id"`
+	ProductName string      `bson:"product_name"`
+	Category    string      `bson:"category,omitempty"`
+	Weight      float64     `bson:"weight,omitempty"`
+	Quantity    int         `bson:"quantity"`
+	UnitPrice   money.Money `bson:"unit_price"`
 }
 
 type Address struct {
@@ -44,5 +219,3 @@ type Phone struct {
 	Id          string `bson:"phone_id,omitempty"`
 	PhoneNumber int    `bson:"phone_number"`
 }
-
-
@@ -1,42 +1,278 @@
 package types
 
-import "time"
+import (
+	"tesodev-korpes/pkg/money"
+	"time"
+)
 
 type CreateOrderRequestModel struct {
-	CustomerId      string      `json:"customer_id,omitempty" validate:"required,dive,required"`
-	Items           []OrderItem `json:"items" validate:"required,dive,required"`
-	Discounts       []*Discount `json:"discounts,omitempty"`
-	ShippingAddress Address     `json:"shipping_address" validate:"required"`
-	BillingAddress  Address     `json:"billing_address" validate:"required"`
+	CustomerId      string                  `json:"customer_id,omitempty" validate:"required,dive,required"`
+	Items           []OrderItemRequestModel `json:"items" validate:"required,dive,required"`
+	DiscountCodes   []string                `json:"discount_codes,omitempty"`
+	ShippingMethod  string                  `json:"shipping_method,omitempty"`
+	ShippingAddress Address                 `json:"shipping_address" validate:"required"`
+	BillingAddress  Address                 `json:"billing_address" validate:"required"`
+}
+
+type OrderItemRequestModel struct {
+	ProductId string `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,gt=0"`
 }
 
 type OrderResponseModel struct {
-	Id              string      `json:"id"`
-	CustomerId      string      `json:"customer_id"`
-	Items           []OrderItem `json:"items"`
-	ShippingAddress Address     `json:"shipping_address"`
-	BillingAddress  Address     `json:"billing_address"`
-	TotalPrice      float64     `json:"total_price"`
-	Status          string      `json:"status"`
-	CreatedAt       time.Time   `json:"created_at"`
-	UpdatedAt       time.Time   `json:"updated_at"`
-	Discounts       []*Discount `json:"discounts,omitempty"`
-	IsDelete        bool        `json:"is_delete"`
+	Id              string            `json:"id"`
+	CustomerId      string            `json:"customer_id"`
+	Items           []OrderItem       `json:"items"`
+	ShippingAddress Address           `json:"shipping_address"`
+	BillingAddress  Address           `json:"billing_address"`
+	TotalPrice      money.Money       `json:"total_price"`
+	Price           *FinalPriceResult `json:"price,omitempty"`
+	Shipping        *Shipment         `json:"shipping,omitempty"`
+	Status          string            `json:"status"`
+	Payment         *Payment          `json:"payment,omitempty"`
+	Version         int               `json:"version"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	Discounts       []*Discount       `json:"discounts,omitempty"`
+	IsDelete        bool              `json:"is_delete"`
 }
 type CustomerResponseModel struct {
-	Id        string    `json:"id"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Email     string    `json:"email"`
-	Phone     []Phone   `json:"phone"`
-	Address   []Address `json:"address"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Id        string       `json:"id"`
+	FirstName string       `json:"first_name"`
+	LastName  string       `json:"last_name"`
+	Email     string       `json:"email"`
+	Phone     []Phone      `json:"phone"`
+	Address   []Address    `json:"address"`
+	Role      CustomerRole `json:"system"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+type CustomerRole struct {
+	SystemRole string `json:"SystemRole"`
+	Membership string `json:"Membership"`
+}
+
+// OrderListQuery is the parsed query of GET /order/list. Zero values mean the
+// filter is not applied.
+type OrderListQuery struct {
+	CustomerId  string
+	Statuses    []string
+	ProductId   string
+	Search      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MinTotal    *money.Money
+	MaxTotal    *money.Money
+	SortBy      string
+	SortDesc    bool
+}
+
+type StatusChangeRequestModel struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type ShipOrderRequestModel struct {
+	Carrier        string `json:"carrier" validate:"required"`
+	TrackingNumber string `json:"tracking_number" validate:"required"`
+	Reason         string `json:"reason,omitempty"`
+}
+
+type AuthorizePaymentRequestModel struct {
+	PaymentToken string `json:"payment_token" validate:"required"`
+}
+
+type CreateReturnRequestModel struct {
+	Items  []ReturnItem `json:"items" validate:"required,dive"`
+	Reason string       `json:"reason,omitempty"`
+}
+
+type ReviewReturnRequestModel struct {
+	Note string `json:"note,omitempty"`
+}
+
+type AddCartItemRequestModel struct {
+	ProductId string `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,gt=0"`
+}
+
+type UpdateCartItemRequestModel struct {
+	Quantity int `json:"quantity" validate:"required,gt=0"`
+}
+
+type ApplyCartDiscountRequestModel struct {
+	Codes []string `json:"codes" validate:"required"`
+}
+
+type CheckoutRequestModel struct {
+	ShippingMethod  string  `json:"shipping_method,omitempty"`
+	ShippingAddress Address `json:"shipping_address" validate:"required"`
+	BillingAddress  Address `json:"billing_address" validate:"required"`
+}
+
+type CartResponseModel struct {
+	CustomerId    string           `json:"customer_id"`
+	Items         []OrderItem      `json:"items"`
+	DiscountCodes []string         `json:"discount_codes,omitempty"`
+	Discounts     []*Discount      `json:"discounts,omitempty"`
+	DiscountError string           `json:"discount_error,omitempty"`
+	Price         FinalPriceResult `json:"price"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+type CreateCouponRequestModel struct {
+	Code             string       `json:"code" validate:"required"`
+	Description      string       `json:"description"`
+	Type             string       `json:"type" validate:"required"`
+	Value            float64      `json:"value,omitempty"`
+	Amount           *money.Money `json:"amount,omitempty"`
+	Role             string       `json:"role,omitempty"`
+	StartDate        time.Time    `json:"start_date" validate:"required"`
+	EndDate          time.Time    `json:"end_date" validate:"required"`
+	MinBasket        *money.Money `json:"min_basket,omitempty"`
+	UsageLimit       int          `json:"usage_limit"`
+	PerCustomerLimit int          `json:"per_customer_limit"`
+	Stackable        bool         `json:"stackable"`
+	IsActive         *bool        `json:"is_active,omitempty"`
+}
+
+type UpdateCouponRequestModel struct {
+	Description      *string      `json:"description,omitempty"`
+	Type             *string      `json:"type,omitempty"`
+	Value            *float64     `json:"value,omitempty"`
+	Amount           *money.Money `json:"amount,omitempty"`
+	Role             *string      `json:"role,omitempty"`
+	StartDate        *time.Time   `json:"start_date,omitempty"`
+	EndDate          *time.Time   `json:"end_date,omitempty"`
+	MinBasket        *money.Money `json:"min_basket,omitempty"`
+	UsageLimit       *int         `json:"usage_limit,omitempty"`
+	PerCustomerLimit *int         `json:"per_customer_limit,omitempty"`
+	Stackable        *bool        `json:"stackable,omitempty"`
+	IsActive         *bool        `json:"is_active,omitempty"`
+}
+
+type CouponResponseModel struct {
+	Code             string       `json:"code"`
+	Description      string       `json:"description,omitempty"`
+	Type             string       `json:"type"`
+	Value            float64      `json:"value,omitempty"`
+	Amount           *money.Money `json:"amount,omitempty"`
+	Role             string       `json:"role,omitempty"`
+	StartDate        time.Time    `json:"start_date"`
+	EndDate          time.Time    `json:"end_date"`
+	MinBasket        *money.Money `json:"min_basket,omitempty"`
+	UsageLimit       int          `json:"usage_limit"`
+	PerCustomerLimit int          `json:"per_customer_limit"`
+	UsedCount        int          `json:"used_count"`
+	Stackable        bool         `json:"stackable"`
+	IsActive         bool         `json:"is_active"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+type CreateExchangeRateRequestModel struct {
+	From          string     `json:"from" validate:"required"`
+	To            string     `json:"to" validate:"required"`
+	Rate          string     `json:"rate" validate:"required"`
+	EffectiveFrom *time.Time `json:"effective_from,omitempty"`
+}
+
+type ExchangeRateResponseModel struct {
+	Id            string    `json:"id"`
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	Rate          string    `json:"rate"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	CreatedBy     string    `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ShippingMethodRequestModel struct {
+	Code      string       `json:"code,omitempty"`
+	Name      string       `json:"name" validate:"required"`
+	Type      string       `json:"type" validate:"required"`
+	Cost      *money.Money `json:"cost,omitempty"`
+	PerKg     *money.Money `json:"per_kg,omitempty"`
+	FreeAbove *money.Money `json:"free_above,omitempty"`
+	IsActive  *bool        `json:"is_active,omitempty"`
+}
+
+type ShippingMethodResponseModel struct {
+	Code      string       `json:"code"`
+	Name      string       `json:"name"`
+	Type      string       `json:"type"`
+	Cost      money.Money  `json:"cost"`
+	PerKg     *money.Money `json:"per_kg,omitempty"`
+	FreeAbove *money.Money `json:"free_above,omitempty"`
+	IsActive  bool         `json:"is_active"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+type TaxRuleRequestModel struct {
+	Name             string   `json:"name" validate:"required"`
+	State            string   `json:"state" validate:"required"`
+	ZipPrefix        string   `json:"zip_prefix,omitempty"`
+	Rate             float64  `json:"rate" validate:"required"`
+	ExemptCategories []string `json:"exempt_categories,omitempty"`
+	IsActive         *bool    `json:"is_active,omitempty"`
+}
+
+type TaxRuleResponseModel struct {
+	Id               string    `json:"id"`
+	Name             string    `json:"name"`
+	State            string    `json:"state"`
+	ZipPrefix        string    `json:"zip_prefix,omitempty"`
+	Rate             float64   `json:"rate"`
+	ExemptCategories []string  `json:"exempt_categories,omitempty"`
+	IsActive         bool      `json:"is_active"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type OrderHistoryResponseModel struct {
+	OrderId string         `json:"order_id"`
+	Status  string         `json:"status"`
+	History []StatusChange `json:"history"`
 }
 
-type Pagination struct {
-	Page  int
-	Limit int
+// AuditInfo carries who triggers a status change and why; it ends up in the
+// order's status history.
+type AuditInfo struct {
+	ActorId       string
+	CorrelationId string
+	Reason        string
+}
+
+type ProductResponseModel struct {
+	Id       string  `json:"id"`
+	Name     string  `json:"name"`
+	Category string  `json:"category"`
+	Price    float64 `json:"price"`
+	Weight   float64 `json:"weight"`
+	IsActive bool    `json:"is_active"`
+}
+
+type ProductLookupRequest struct {
+	Ids []string `json:"ids"`
+}
+
+type ProductLookupResponse struct {
+	Data []ProductResponseModel `json:"data"`
+}
+
+type ReserveStockRequest struct {
+	OrderId string             `json:"order_id"`
+	Items   []StockItemRequest `json:"items"`
+}
+
+type StockItemRequest struct {
+	ProductId string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type StockReservationRequest struct {
+	OrderId string `json:"order_id"`
 }
 
 type OrderWithCustomerResponse struct {
@@ -45,18 +281,44 @@ type OrderWithCustomerResponse struct {
 }
 
 type OrderPriceInfo struct {
-	TotalPrice float64   `bson:"total_price"`
-	Discount   *Discount `bson:"discount,omitempty"`
+	TotalPrice money.Money `bson:"total_price"`
+	Discounts  []*Discount `bson:"discount,omitempty"`
 }
 
+// FinalPriceResult is the price breakdown of an order. It is stored on the
+// order when the order is placed, so PricedAt tells when it was calculated.
 type FinalPriceResult struct {
-	OriginalPrice   float64 `json:"original_price"`
-	DiscountApplied float64 `json:"discount_applied"`
-	FinalPrice      float64 `json:"final_price"`
-	DiscountType    string  `json:"discount_type,omitempty"`
+	OriginalPrice   money.Money `bson:"original_price" json:"original_price"`
+	DiscountApplied money.Money `bson:"discount_applied" json:"discount_applied"`
+	TaxLines        []TaxLine   `bson:"tax_lines,omitempty" json:"tax_lines,omitempty"`
+	TaxTotal        money.Money `bson:"tax_total,omitempty" json:"tax_total"`
+	ShippingCost    money.Money `bson:"shipping_cost,omitempty" json:"shipping_cost"`
+	FinalPrice      money.Money `bson:"final_price" json:"final_price"`
+	DiscountType    string      `bson:"discount_type,omitempty" json:"discount_type,omitempty"`
+	PricedAt        time.Time   `bson:"priced_at,omitempty" json:"priced_at,omitempty"`
+	// Conversion is only set on responses priced in another currency than
+	// the order's.
+	Conversion *PriceConversion `bson:"-" json:"conversion,omitempty"`
+}
+
+// TaxLine is the tax one rule adds to an order. TaxableAmount is the
+// discounted price of the items the rule taxes.
+type TaxLine struct {
+	RuleId        string      `bson:"rule_id" json:"rule_id"`
+	Name          string      `bson:"name" json:"name"`
+	Rate          float64     `bson:"rate" json:"rate"`
+	TaxableAmount money.Money `bson:"taxable_amount" json:"taxable_amount"`
+	Amount        money.Money `bson:"amount" json:"amount"`
+}
+
+type PriceConversion struct {
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	Rate          string    `json:"rate"`
+	EffectiveFrom time.Time `json:"effective_from"`
 }
 
 type AggregationResult struct {
-	TotalPrice float64    `bson:"total_price"`
-	Discount   []Discount `bson:"discount"`
+	TotalPrice money.Money `bson:"total_price"`
+	Discount   []Discount  `bson:"discount"`
 }
@@ -1,8 +1,12 @@
 package types
 
 import (
+	"strings"
+	"tesodev-korpes/OrderService/config"
 	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/money"
 	"tesodev-korpes/pkg/validators"
+	"time"
 )
 
 func (c CreateOrderRequestModel) CreateValidate() *customError.AppError {
@@ -16,6 +20,174 @@ func (c CreateOrderRequestModel) CreateValidate() *customError.AppError {
 	if !validators.IsEmpty(c.ShippingAddress.State) {
 		return customError.NewUnprocessableEntity(customError.InvalidAddressFormat, nil)
 	}
+	if len(c.Items) == 0 {
+		return customError.NewUnprocessableEntity(customError.InvalidOrderItem, nil)
+	}
+	for _, item := range c.Items {
+		if !validators.IsValidUUID(item.ProductId) || item.Quantity <= 0 {
+			return customError.NewUnprocessableEntity(customError.InvalidOrderItem, nil)
+		}
+	}
+
+	return nil
+}
+
+func (c CreateReturnRequestModel) CreateValidate() *customError.AppError {
+
+	if len(c.Items) == 0 {
+		return customError.NewUnprocessableEntity(customError.InvalidReturnItems, nil)
+	}
+	for _, item := range c.Items {
+		if !validators.IsValidUUID(item.ProductId) || item.Quantity <= 0 {
+			return customError.NewUnprocessableEntity(customError.InvalidReturnItems, nil)
+		}
+	}
+
+	return nil
+}
+
+func (c CreateCouponRequestModel) CreateValidate() *customError.AppError {
+
+	code := strings.TrimSpace(c.Code)
+	if !validators.IsEmpty(code) || strings.ContainsAny(code, ": ") {
+		return customError.NewBadRequest(customError.InvalidCouponCode)
+	}
+
+	return nil
+}
+
+// Validate checks a coupon definition. It runs on the merged coupon so that an
+// update cannot leave it in a state a create would have rejected.
+func (c Coupon) Validate() *customError.AppError {
+
+	switch c.Type {
+	case config.DiscountType.Percentage:
+		if c.Value <= 0 || c.Value > 100 {
+			return customError.NewUnprocessableEntity(customError.InvalidCouponDefinition, nil)
+		}
+	case config.DiscountType.FixedAmount:
+		if !c.Amount.IsPositive() || !money.IsValidCurrency(c.Amount.Currency) {
+			return customError.NewUnprocessableEntity(customError.InvalidCouponDefinition, nil)
+		}
+	default:
+		return customError.NewUnprocessableEntity(customError.InvalidCouponDefinition, nil)
+	}
+	if c.StartDate.IsZero() || !c.EndDate.After(c.StartDate) {
+		return customError.NewUnprocessableEntity(customError.InvalidCouponDefinition, nil)
+	}
+	if !c.MinBasket.IsZero() && (c.MinBasket.IsNegative() || !money.IsValidCurrency(c.MinBasket.Currency)) {
+		return customError.NewUnprocessableEntity(customError.InvalidCouponDefinition, nil)
+	}
+	if c.UsageLimit < 0 || c.PerCustomerLimit < 0 {
+		return customError.NewUnprocessableEntity(customError.InvalidCouponDefinition, nil)
+	}
+
+	return nil
+}
+
+func (c CreateExchangeRateRequestModel) CreateValidate() *customError.AppError {
+
+	from, ok := money.ParseCurrency(c.From)
+	if !ok {
+		return customError.NewUnprocessableEntity(customError.InvalidExchangeRate, nil)
+	}
+	to, ok := money.ParseCurrency(c.To)
+	if !ok || from == to {
+		return customError.NewUnprocessableEntity(customError.InvalidExchangeRate, nil)
+	}
+	if _, ok := money.ParseRate(c.Rate); !ok {
+		return customError.NewUnprocessableEntity(customError.InvalidExchangeRate, nil)
+	}
+	// Orders keep the rate in force when they were placed, so a rate that
+	// starts in the past would change conversions that were already made.
+	if c.EffectiveFrom != nil && c.EffectiveFrom.Before(time.Now()) {
+		return customError.NewUnprocessableEntity(customError.ExchangeRateInPast, nil)
+	}
+
+	return nil
+}
+
+func (c TaxRuleRequestModel) Validate() *customError.AppError {
+
+	if !validators.IsEmpty(c.Name) || !validators.IsEmpty(c.State) {
+		return customError.NewUnprocessableEntity(customError.InvalidTaxRule, nil)
+	}
+	if c.Rate <= 0 || c.Rate > 100 {
+		return customError.NewUnprocessableEntity(customError.InvalidTaxRule, nil)
+	}
+
+	return nil
+}
+
+func (c ShipOrderRequestModel) Validate() *customError.AppError {
+
+	if !validators.IsEmpty(c.Carrier) || !validators.IsEmpty(c.TrackingNumber) {
+		return customError.NewUnprocessableEntity(customError.InvalidShipment, nil)
+	}
+
+	return nil
+}
+
+// Validate checks a shipping method. Costs are in the catalog currency since
+// they are added to the price of the items.
+func (c ShippingMethod) Validate() *customError.AppError {
+
+	if !validators.IsEmpty(c.Code) || strings.ContainsAny(c.Code, " /") {
+		return customError.NewBadRequest(customError.InvalidShippingMethodCode)
+	}
+	if !validators.IsEmpty(c.Name) {
+		return customError.NewUnprocessableEntity(customError.InvalidShippingMethod, nil)
+	}
+	if c.Cost.IsNegative() || !isCatalogCurrency(c.Cost) {
+		return customError.NewUnprocessableEntity(customError.InvalidShippingMethod, nil)
+	}
+
+	switch c.Type {
+	case config.ShippingRateType.Flat:
+	case config.ShippingRateType.Weight:
+		if !c.PerKg.IsPositive() || !isCatalogCurrency(c.PerKg) {
+			return customError.NewUnprocessableEntity(customError.InvalidShippingMethod, nil)
+		}
+	case config.ShippingRateType.FreeAboveThreshold:
+		if !c.FreeAbove.IsPositive() || !isCatalogCurrency(c.FreeAbove) {
+			return customError.NewUnprocessableEntity(customError.InvalidShippingMethod, nil)
+		}
+	default:
+		return customError.NewUnprocessableEntity(customError.InvalidShippingMethod, nil)
+	}
+
+	return nil
+}
+
+func isCatalogCurrency(m money.Money) bool {
+	return m.Currency == money.DefaultCurrency
+}
+
+func (q OrderListQuery) Validate() *customError.AppError {
+
+	if q.CustomerId != "" && !validators.IsValidUUID(q.CustomerId) {
+		return customError.NewBadRequest(customError.InvalidOrderQuery)
+	}
+	if q.ProductId != "" && !validators.IsValidUUID(q.ProductId) {
+		return customError.NewBadRequest(customError.InvalidOrderQuery)
+	}
+	for _, status := range q.Statuses {
+		if !config.IsOrderStatus(status) {
+			return customError.NewBadRequest(customError.InvalidOrderQuery)
+		}
+	}
+	if q.CreatedFrom != nil && q.CreatedTo != nil && q.CreatedTo.Before(*q.CreatedFrom) {
+		return customError.NewBadRequest(customError.InvalidOrderQuery)
+	}
+	if q.MinTotal != nil && q.MinTotal.IsNegative() {
+		return customError.NewBadRequest(customError.InvalidOrderQuery)
+	}
+	if q.MinTotal != nil && q.MaxTotal != nil && q.MaxTotal.LessThan(*q.MinTotal) {
+		return customError.NewBadRequest(customError.InvalidOrderQuery)
+	}
+	if _, ok := config.OrderSortFields[q.SortBy]; !ok {
+		return customError.NewBadRequest(customError.InvalidOrderQuery)
+	}
 
 	return nil
 }
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"tesodev-korpes/OrderService/config"
+	"tesodev-korpes/pkg/money"
+	"testing"
+	"time"
+)
+
+func TestCouponValidate(t *testing.T) {
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	percentage := func() Coupon {
+		return Coupon{
+			Code:      "SPRING",
+			Type:      config.DiscountType.Percentage,
+			Value:     10,
+			StartDate: start,
+			EndDate:   start.AddDate(0, 1, 0),
+		}
+	}
+	fixed := func() Coupon {
+		c := percentage()
+		c.Type, c.Value, c.Amount = config.DiscountType.FixedAmount, 0, money.New(500, "TRY")
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		coupon func() Coupon
+		valid  bool
+	}{
+		{"percentage", percentage, true},
+		{"percentage of 100", func() Coupon { c := percentage(); c.Value = 100; return c }, true},
+		{"percentage of 0", func() Coupon { c := percentage(); c.Value = 0; return c }, false},
+		{"percentage over 100", func() Coupon { c := percentage(); c.Value = 100.5; return c }, false},
+		{"negative percentage", func() Coupon { c := percentage(); c.Value = -5; return c }, false},
+		{"fixed amount", fixed, true},
+		{"fixed amount of 0", func() Coupon { c := fixed(); c.Amount = money.New(0, "TRY"); return c }, false},
+		{"fixed amount without currency", func() Coupon { c := fixed(); c.Amount = money.New(500, ""); return c }, false},
+		{"fixed amount in unknown currency", func() Coupon { c := fixed(); c.Amount = money.New(500, "XXX"); return c }, false},
+		{"unknown type", func() Coupon { c := percentage(); c.Type = "bogo"; return c }, false},
+		{"no start date", func() Coupon { c := percentage(); c.StartDate = time.Time{}; return c }, false},
+		{"ends when it starts", func() Coupon { c := percentage(); c.EndDate = c.StartDate; return c }, false},
+		{"ends before it starts", func() Coupon { c := percentage(); c.EndDate = c.StartDate.Add(-time.Hour); return c }, false},
+		{"minimum basket", func() Coupon { c := percentage(); c.MinBasket = money.New(10000, "TRY"); return c }, true},
+		{"negative minimum basket", func() Coupon { c := percentage(); c.MinBasket = money.New(-1, "TRY"); return c }, false},
+		{"minimum basket in unknown currency", func() Coupon { c := percentage(); c.MinBasket = money.New(100, "XXX"); return c }, false},
+		{"usage limits", func() Coupon { c := percentage(); c.UsageLimit, c.PerCustomerLimit = 100, 1; return c }, true},
+		{"negative usage limit", func() Coupon { c := percentage(); c.UsageLimit = -1; return c }, false},
+		{"negative per-customer limit", func() Coupon { c := percentage(); c.PerCustomerLimit = -1; return c }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.coupon().Validate()
+			if tt.valid && err != nil {
+				t.Fatalf("Validate() = %v, want nil", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatalf("Validate() = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestCreateExchangeRateValidate(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		req   CreateExchangeRateRequestModel
+		valid bool
+	}{
+		{"effective now", CreateExchangeRateRequestModel{From: "USD", To: "TRY", Rate: "32.4517"}, true},
+		{"lower-case currencies", CreateExchangeRateRequestModel{From: "usd", To: "try", Rate: "32.4517"}, true},
+		{"scheduled", CreateExchangeRateRequestModel{From: "USD", To: "TRY", Rate: "32.4517", EffectiveFrom: &future}, true},
+		{"in the past", CreateExchangeRateRequestModel{From: "USD", To: "TRY", Rate: "32.4517", EffectiveFrom: &past}, false},
+		{"same currency", CreateExchangeRateRequestModel{From: "USD", To: "usd", Rate: "1"}, false},
+		{"unknown currency", CreateExchangeRateRequestModel{From: "USD", To: "XXX", Rate: "1"}, false},
+		{"zero rate", CreateExchangeRateRequestModel{From: "USD", To: "TRY", Rate: "0"}, false},
+		{"negative rate", CreateExchangeRateRequestModel{From: "USD", To: "TRY", Rate: "-32"}, false},
+		{"rate is not a number", CreateExchangeRateRequestModel{From: "USD", To: "TRY", Rate: "abc"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.CreateValidate()
+			if tt.valid && err != nil {
+				t.Fatalf("CreateValidate() = %v, want nil", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatalf("CreateValidate() = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	config4 "tesodev-korpes/ProductService/config"
+	"tesodev-korpes/ProductService/internal"
+	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/auth"
+	"tesodev-korpes/pkg/middleware"
+	sharedConfig "tesodev-korpes/shared/config"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func BootProductService(client *mongo.Client, e *echo.Echo) {
+	config := config4.GetProductConfig("dev")
+	productCol, err := pkg.GetMongoCollection(client, config.DbConfig.DBName, config.DbConfig.ColName)
+	if err != nil {
+		panic(err)
+	}
+
+	stockCol, err := pkg.GetMongoCollection(client, config.DbConfig.DBName, config.DbConfig.StockColName)
+	if err != nil {
+		panic(err)
+	}
+	reservationCol, err := pkg.GetMongoCollection(client, config.DbConfig.DBName, config.DbConfig.ReservationColName)
+	if err != nil {
+		panic(err)
+	}
+
+	customerCol, err := pkg.GetMongoCollection(client, config.DbConfig.DBName, config.DbConfig.CustomerColName)
+	if err != nil {
+		panic(err)
+	}
+	revokedTokenCol, err := pkg.GetMongoCollection(client, config.DbConfig.DBName, config.DbConfig.RevokedTokenColName)
+	if err != nil {
+		panic(err)
+	}
+
+	repo := internal.NewRepository(productCol)
+	stockRepo := internal.NewStockRepository(stockCol, reservationCol)
+	service := internal.NewService(repo, stockRepo)
+	authConfig := sharedConfig.GetAuthConfig()
+	denylist := auth.NewDenylist(revokedTokenCol, authConfig.RevocationCacheTTL)
+	statuses := auth.NewUserStatusCache(customerCol, authConfig.UserStatusCacheTTL)
+	internal.NewHandler(e, service, denylist, statuses)
+	middleware.MustCheckRoutePolicies(e, &sharedConfig.Cfg, "product")
+	e.Logger.Fatal(e.Start(config.Port))
+}
@@ -0,0 +1,88 @@
+package config
+
+var ReservationStatus = struct {
+	Reserved  string
+	Released  string
+	Committed string
+}{
+	Reserved:  "RESERVED",
+	Released:  "RELEASED",
+	Committed: "COMMITTED",
+}
+
+type ProductConfig struct {
+	Port     string
+	DbConfig struct {
+		DBName              string
+		ColName             string
+		StockColName        string
+		ReservationColName  string
+		CustomerColName     string
+		RevokedTokenColName string
+	}
+}
+
+var cfgs = map[string]ProductConfig{
+	"prod": {
+		Port: ":8003",
+		DbConfig: struct {
+			DBName              string
+			ColName             string
+			StockColName        string
+			ReservationColName  string
+			CustomerColName     string
+			RevokedTokenColName string
+		}{
+			DBName:              "tesodev",
+			ColName:             "product",
+			StockColName:        "stock",
+			ReservationColName:  "stock_reservation",
+			CustomerColName:     "customer",
+			RevokedTokenColName: "revoked_token",
+		},
+	},
+	"qa": {
+		Port: ":8003",
+		DbConfig: struct {
+			DBName              string
+			ColName             string
+			StockColName        string
+			ReservationColName  string
+			CustomerColName     string
+			RevokedTokenColName string
+		}{
+			DBName:              "tesodev",
+			ColName:             "product",
+			StockColName:        "stock",
+			ReservationColName:  "stock_reservation",
+			CustomerColName:     "customer",
+			RevokedTokenColName: "revoked_token",
+		},
+	},
+	"dev": {
+		Port: ":8003",
+		DbConfig: struct {
+			DBName              string
+			ColName             string
+			StockColName        string
+			ReservationColName  string
+			CustomerColName     string
+			RevokedTokenColName string
+		}{
+			DBName:              "tesodev",
+			ColName:             "product",
+			StockColName:        "stock",
+			ReservationColName:  "stock_reservation",
+			CustomerColName:     "customer",
+			RevokedTokenColName: "revoked_token",
+		},
+	},
+}
+
+func GetProductConfig(env string) *ProductConfig {
+	config, isExist := cfgs[env]
+	if !isExist {
+		panic("config does not exist")
+	}
+	return &config
+}
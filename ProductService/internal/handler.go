@@ -0,0 +1,254 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"tesodev-korpes/ProductService/internal/types"
+	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/auth"
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/middleware"
+	"tesodev-korpes/shared/config"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// @title Product Service API
+// @version 1.0
+// @description API for managing the product catalog
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(e *echo.Echo, service *Service, denylist *auth.Denylist, statuses *auth.UserStatusCache) {
+	handler := &Handler{
+		service: service,
+	}
+
+	e.Use(middleware.Authentication(denylist, statuses, middleware.PublicRoute(&config.Cfg)))
+	e.Use(middleware.AuthorizationMiddleware(&config.Cfg))
+
+	g := e.Group("/product")
+	g.POST("", handler.Create)
+	g.GET("/list", handler.GetListProduct)
+	g.GET("/:id", handler.GetByID)
+	g.PUT("/:id", handler.Update)
+	g.DELETE("/:id", handler.Delete)
+	g.POST("/lookup", handler.Lookup)
+
+	g.GET("/:id/stock", handler.GetStock)
+	g.PUT("/:id/stock", handler.UpdateStock)
+	g.POST("/stock/reserve", handler.ReserveStock)
+	g.POST("/stock/release", handler.ReleaseStock)
+	g.POST("/stock/commit", handler.CommitStock)
+}
+
+// Create godoc
+// @Summary Create a new product
+// @Description Add a product to the catalog
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param product body types.CreateProductRequestModel true "Product to create"
+// @Success 201 {object} map[string]interface{} "Returns created product ID"
+// @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 422 {object} errorPackage.AppError "Validation error on input data"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /product [post]
+func (h *Handler) Create(c echo.Context) error {
+	var req types.CreateProductRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidProductBody)
+	}
+
+	if err := req.CreateValidate(); err != nil {
+		return err
+	}
+
+	createdID, err := h.service.Create(c.Request().Context(), &req)
+	if err != nil {
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"message":   "Succeeded!",
+		"createdId": createdID,
+	})
+}
+
+// GetByID godoc
+// @Summary Get product by ID
+// @Description Get a catalog product by its unique ID
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} types.ProductResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Product not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /product/{id} [get]
+func (h *Handler) GetByID(c echo.Context) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidProductID)
+	}
+
+	product, err := h.service.GetByID(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return customError.NewNotFound(customError.ProductNotFound)
+		}
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+
+	return c.JSON(http.StatusOK, product)
+}
+
+// Update godoc
+// @Summary Update an existing product
+// @Description Update name, description, category, price or availability of a product
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Product ID"
+// @Param product body types.UpdateProductRequestModel true "Product data to update"
+// @Success 200 {object} types.ProductResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format or request body"
+// @Failure 404 {object} errorPackage.AppError "Product not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /product/{id} [put]
+func (h *Handler) Update(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidProductID)
+	}
+
+	var req types.UpdateProductRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidProductBody)
+	}
+
+	if err := req.UpdateValidate(); err != nil {
+		return err
+	}
+
+	product, err := h.service.Update(c.Request().Context(), id, &req)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return customError.NewNotFound(customError.ProductNotFound)
+		}
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+
+	return c.JSON(http.StatusOK, product)
+}
+
+// Delete godoc
+// @Summary Delete a product by ID
+// @Description Remove a product from the catalog
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Product ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Product not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /product/{id} [delete]
+func (h *Handler) Delete(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidProductID)
+	}
+
+	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return customError.NewNotFound(customError.ProductNotFound)
+		}
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetListProduct godoc
+// @Summary List products with pagination
+// @Description Retrieve a paginated list of catalog products
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Success 200 {object} map[string]interface{} "Returns list of products"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /product/list [get]
+func (h *Handler) GetListProduct(c echo.Context) error {
+	params := types.Pagination{
+		Limit: 10,
+		Page:  1,
+	}
+
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			params.Page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 {
+			params.Limit = limitInt
+		}
+	}
+
+	products, err := h.service.Get(c.Request().Context(), params)
+	if err != nil {
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": products})
+}
+
+// Lookup godoc
+// @Summary Look up several products at once
+// @Description Resolve a list of product IDs to their catalog entries. Used by the order service to price order items.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param lookup body types.LookupRequestModel true "Product IDs to resolve"
+// @Success 200 {object} map[string]interface{} "Returns the products that were found"
+// @Failure 400 {object} errorPackage.AppError "Invalid request body or product ID"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /product/lookup [post]
+func (h *Handler) Lookup(c echo.Context) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+
+	var req types.LookupRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidProductBody)
+	}
+
+	if err := req.LookupValidate(); err != nil {
+		return err
+	}
+
+	products, err := h.service.Lookup(c.Request().Context(), req.Ids)
+	if err != nil {
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": products})
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"tesodev-korpes/ProductService/internal/types"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func FromCreateProductRequest(req *types.CreateProductRequestModel) *types.Product {
+	if req == nil {
+		return nil
+	}
+
+	return &types.Product{
+		Id:          uuid.New().String(),
+		Name:        req.Name,
+		Description: req.Description,
+		Category:    req.Category,
+		Price:       req.Price,
+		Weight:      req.Weight,
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+func FromUpdateProductRequest(product *types.Product, req *types.UpdateProductRequestModel) *types.Product {
+	if product == nil || req == nil {
+		return product
+	}
+	if req.Name != "" {
+		product.Name = req.Name
+	}
+	if req.Description != "" {
+		product.Description = req.Description
+	}
+	if req.Category != "" {
+		product.Category = req.Category
+	}
+	if req.Price != nil {
+		product.Price = *req.Price
+	}
+	if req.Weight != nil {
+		product.Weight = *req.Weight
+	}
+	if req.IsActive != nil {
+		product.IsActive = *req.IsActive
+	}
+	return product
+}
+
+func ToProductResponse(product *types.Product) *types.ProductResponseModel {
+	if product == nil {
+		return nil
+	}
+	return &types.ProductResponseModel{
+		Id:          product.Id,
+		Name:        product.Name,
+		Description: product.Description,
+		Category:    product.Category,
+		Price:       product.Price,
+		Weight:      product.Weight,
+		IsActive:    product.IsActive,
+		CreatedAt:   product.CreatedAt,
+		UpdatedAt:   product.UpdatedAt,
+	}
+}
+
+func ToStockResponse(stock *types.Stock) *types.StockResponseModel {
+	if stock == nil {
+		return nil
+	}
+	return &types.StockResponseModel{
+		ProductId: stock.ProductId,
+		OnHand:    stock.OnHand,
+		Reserved:  stock.Reserved,
+		Available: stock.OnHand - stock.Reserved,
+		UpdatedAt: stock.UpdatedAt,
+	}
+}
+
+// FromReserveStockRequest merges repeated product ids so every ledger entry is
+// touched only once per reservation.
+func FromReserveStockRequest(req *types.ReserveStockRequestModel) []types.ReservationItem {
+	if req == nil {
+		return nil
+	}
+
+	index := make(map[string]int, len(req.Items))
+	items := make([]types.ReservationItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		if i, ok := index[item.ProductId]; ok {
+			items[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.ProductId] = len(items)
+		items = append(items, types.ReservationItem{
+			ProductId: item.ProductId,
+			Quantity:  item.Quantity,
+		})
+	}
+	return items
+}
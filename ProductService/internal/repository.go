@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/ProductService/internal/types"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Repository struct {
+	collection *mongo.Collection
+}
+
+func NewRepository(col *mongo.Collection) *Repository {
+	return &Repository{
+		collection: col,
+	}
+}
+
+func (r *Repository) Create(ctx context.Context, product *types.Product) (string, error) {
+	_, err := r.collection.InsertOne(ctx, product)
+	if err != nil {
+		return "", err
+	}
+	return product.Id, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id string) (*types.Product, error) {
+	var product types.Product
+	filter := bson.M{"_id": id}
+	err := r.collection.FindOne(ctx, filter).Decode(&product)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *Repository) GetByIDs(ctx context.Context, ids []string) ([]types.Product, error) {
+	var products []types.Product
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *Repository) Update(ctx context.Context, id string, product *types.Product) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"name":        product.Name,
+			"description": product.Description,
+			"category":    product.Category,
+			"price":       product.Price,
+			"weight":      product.Weight,
+			"is_active":   product.IsActive,
+			"updated_at":  time.Now(),
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *Repository) Get(ctx context.Context, opt *options.FindOptions) ([]types.Product, error) {
+	var products []types.Product
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opt)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
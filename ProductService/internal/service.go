@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"context"
+	"tesodev-korpes/ProductService/internal/types"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Service struct {
+	repo      *Repository
+	stockRepo *StockRepository
+}
+
+func NewService(repo *Repository, stockRepo *StockRepository) *Service {
+	return &Service{
+		repo:      repo,
+		stockRepo: stockRepo,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, req *types.CreateProductRequestModel) (string, error) {
+	product := FromCreateProductRequest(req)
+	return s.repo.Create(ctx, product)
+}
+
+func (s *Service) GetByID(ctx context.Context, id string) (*types.ProductResponseModel, error) {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ToProductResponse(product), nil
+}
+
+func (s *Service) Update(ctx context.Context, id string, req *types.UpdateProductRequestModel) (*types.ProductResponseModel, error) {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := FromUpdateProductRequest(product, req)
+	if err := s.repo.Update(ctx, id, updated); err != nil {
+		return nil, err
+	}
+	return ToProductResponse(updated), nil
+}
+
+func (s *Service) Delete(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *Service) Get(ctx context.Context, params types.Pagination) ([]types.ProductResponseModel, error) {
+	skip := (params.Page - 1) * params.Limit
+
+	findOptions := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(params.Limit)).
+		SetSort(bson.D{{Key: "name", Value: 1}})
+
+	products, err := s.repo.Get(ctx, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]types.ProductResponseModel, 0, len(products))
+	for _, p := range products {
+		responses = append(responses, *ToProductResponse(&p))
+	}
+	return responses, nil
+}
+
+// Lookup returns the catalog entries for the given ids. Unknown ids are simply
+// left out of the result so the caller can decide how to report them.
+func (s *Service) Lookup(ctx context.Context, ids []string) ([]types.ProductResponseModel, error) {
+	products, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]types.ProductResponseModel, 0, len(products))
+	for _, p := range products {
+		responses = append(responses, *ToProductResponse(&p))
+	}
+	return responses, nil
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"tesodev-korpes/ProductService/internal/types"
+	"tesodev-korpes/pkg"
+	"tesodev-korpes/pkg/customError"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetStock godoc
+// @Summary Get stock of a product
+// @Description Returns quantity on hand, reserved quantity and what is still available
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} types.StockResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format"
+// @Failure 404 {object} errorPackage.AppError "Product not found"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /product/{id}/stock [get]
+func (h *Handler) GetStock(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidProductID)
+	}
+
+	stock, err := h.service.GetStock(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return customError.NewNotFound(customError.ProductNotFound)
+		}
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+// UpdateStock godoc
+// @Summary Set quantity on hand
+// @Description Set the physical quantity of a product. It cannot go below what is currently reserved.
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Product ID"
+// @Param stock body types.UpdateStockRequestModel true "New quantity on hand"
+// @Success 200 {object} types.StockResponseModel
+// @Failure 400 {object} errorPackage.AppError "Invalid ID format or request body"
+// @Failure 404 {object} errorPackage.AppError "Product not found"
+// @Failure 409 {object} errorPackage.AppError "Quantity lower than reserved"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /product/{id}/stock [put]
+func (h *Handler) UpdateStock(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsValidUUID(id) {
+		return customError.NewBadRequest(customError.InvalidProductID)
+	}
+
+	var req types.UpdateStockRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidStockBody)
+	}
+
+	if err := req.UpdateValidate(); err != nil {
+		return err
+	}
+
+	stock, err := h.service.SetStock(c.Request().Context(), id, req.OnHand)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return customError.NewNotFound(customError.ProductNotFound)
+		}
+
+		var appErr *customError.AppError
+		if errors.As(err, &appErr) {
+			return err
+		}
+
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+// ReserveStock godoc
+// @Summary Reserve stock for an order
+// @Description Holds the quantities of all items of an order. Either every item is reserved or none is. Only services may call it.
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param reservation body types.ReserveStockRequestModel true "Order and items to reserve"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 409 {object} errorPackage.AppError "Insufficient stock"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /product/stock/reserve [post]
+func (h *Handler) ReserveStock(c echo.Context) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+
+	var req types.ReserveStockRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidStockBody)
+	}
+
+	if err := req.ReserveValidate(); err != nil {
+		return err
+	}
+
+	if err := h.service.ReserveStock(c.Request().Context(), &req); err != nil {
+		var appErr *customError.AppError
+		if errors.As(err, &appErr) {
+			return err
+		}
+
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "Stock reserved successfully"})
+}
+
+// ReleaseStock godoc
+// @Summary Release the stock reservation of an order
+// @Description Returns the reserved quantities of a canceled order to the available pool. Only services may call it.
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param reservation body types.StockReservationRequestModel true "Order whose reservation is released"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 404 {object} errorPackage.AppError "Reservation not found"
+// @Failure 409 {object} errorPackage.AppError "Reservation already committed"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /product/stock/release [post]
+func (h *Handler) ReleaseStock(c echo.Context) error {
+	return h.closeReservation(c, h.service.ReleaseStock, "Stock released successfully")
+}
+
+// CommitStock godoc
+// @Summary Commit the stock reservation of an order
+// @Description Deducts the reserved quantities of a shipped order from the quantity on hand. Only services may call it.
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param reservation body types.StockReservationRequestModel true "Order whose reservation is committed"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} errorPackage.AppError "Invalid request body"
+// @Failure 404 {object} errorPackage.AppError "Reservation not found"
+// @Failure 409 {object} errorPackage.AppError "Reservation already released"
+// @Failure 500 {object} errorPackage.AppError "Internal server error"
+// @Router /product/stock/commit [post]
+func (h *Handler) CommitStock(c echo.Context) error {
+	return h.closeReservation(c, h.service.CommitStock, "Stock committed successfully")
+}
+
+func (h *Handler) closeReservation(c echo.Context, action func(ctx context.Context, orderID string) error, message string) error {
+	correlationID, _ := c.Get("CorrelationID").(string)
+
+	var req types.StockReservationRequestModel
+	if err := c.Bind(&req); err != nil {
+		return customError.NewBadRequest(customError.InvalidStockBody)
+	}
+
+	if err := req.ReservationValidate(); err != nil {
+		return err
+	}
+
+	if err := action(c.Request().Context(), req.OrderId); err != nil {
+		var appErr *customError.AppError
+		if errors.As(err, &appErr) {
+			return err
+		}
+
+		customError.LogErrorWithCorrelation(err, correlationID)
+		return customError.NewInternal(customError.ProductServiceError, err)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": message})
+}
@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"tesodev-korpes/ProductService/config"
+	"tesodev-korpes/ProductService/internal/types"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errInsufficientStock = errors.New("insufficient stock")
+	errBelowReserved     = errors.New("on hand below reserved")
+	errReservationClosed = errors.New("reservation already closed")
+)
+
+// StockRepository keeps the stock ledger (one document per product) and the
+// reservations made for orders (one document per order id).
+type StockRepository struct {
+	stock        *mongo.Collection
+	reservations *mongo.Collection
+}
+
+func NewStockRepository(stock, reservations *mongo.Collection) *StockRepository {
+	return &StockRepository{
+		stock:        stock,
+		reservations: reservations,
+	}
+}
+
+func (r *StockRepository) GetByProductID(ctx context.Context, productID string) (*types.Stock, error) {
+	var stock types.Stock
+	err := r.stock.FindOne(ctx, bson.M{"_id": productID}).Decode(&stock)
+	if err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
+
+// SetOnHand sets the physical quantity of a product. The ledger document is
+// created on first use; it is never allowed to drop below what is reserved.
+func (r *StockRepository) SetOnHand(ctx context.Context, productID string, onHand int) (*types.Stock, error) {
+	filter := bson.M{
+		"_id":      productID,
+		"reserved": bson.M{"$lte": onHand},
+	}
+	update := bson.M{
+		"$set":         bson.M{"on_hand": onHand, "updated_at": time.Now()},
+		"$setOnInsert": bson.M{"reserved": 0},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var stock types.Stock
+	err := r.stock.FindOneAndUpdate(ctx, filter, update, opts).Decode(&stock)
+	if err != nil {
+		// The filter did not match an existing ledger because of the reserved
+		// guard, so the upsert collided with the existing _id.
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errBelowReserved
+		}
+		return nil, err
+	}
+	return &stock, nil
+}
+
+// Reserve holds stock for every item of an order. Each product is decremented
+// with a single conditional update; if any item cannot be covered the items
+// already held are given back, so either the whole order is reserved or nothing.
+func (r *StockRepository) Reserve(ctx context.Context, orderID string, items []types.ReservationItem) error {
+	reservation := types.StockReservation{
+		OrderId:   orderID,
+		Items:     items,
+		Status:    config.ReservationStatus.Reserved,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if _, err := r.reservations.InsertOne(ctx, reservation); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// Already reserved for this order; reserving is idempotent.
+			return nil
+		}
+		return err
+	}
+
+	for i, item := range items {
+		filter := bson.M{
+			"_id": item.ProductId,
+			"$expr": bson.M{"$gte": bson.A{
+				bson.M{"$subtract": bson.A{"$on_hand", "$reserved"}},
+				item.Quantity,
+			}},
+		}
+		update := bson.M{
+			"$inc": bson.M{"reserved": item.Quantity},
+			"$set": bson.M{"updated_at": time.Now()},
+		}
+
+		res, err := r.stock.UpdateOne(ctx, filter, update)
+		if err == nil && res.MatchedCount == 0 {
+			err = errInsufficientStock
+		}
+		if err != nil {
+			r.undoReserve(ctx, items[:i])
+			_, _ = r.reservations.DeleteOne(ctx, bson.M{"_id": orderID})
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *StockRepository) undoReserve(ctx context.Context, items []types.ReservationItem) {
+	for _, item := range items {
+		_, _ = r.stock.UpdateOne(ctx, bson.M{"_id": item.ProductId}, bson.M{
+			"$inc": bson.M{"reserved": -item.Quantity},
+			"$set": bson.M{"updated_at": time.Now()},
+		})
+	}
+}
+
+// Release gives the reserved quantities of an order back to the available pool.
+func (r *StockRepository) Release(ctx context.Context, orderID string) error {
+	reservation, err := r.closeReservation(ctx, orderID, config.ReservationStatus.Released)
+	if err != nil || reservation == nil {
+		return err
+	}
+
+	for _, item := range reservation.Items {
+		_, err := r.stock.UpdateOne(ctx, bson.M{"_id": item.ProductId}, bson.M{
+			"$inc": bson.M{"reserved": -item.Quantity},
+			"$set": bson.M{"updated_at": time.Now()},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Commit turns the reservation of an order into an actual stock movement:
+// the quantities leave both the reserved and the on hand counters.
+func (r *StockRepository) Commit(ctx context.Context, orderID string) error {
+	reservation, err := r.closeReservation(ctx, orderID, config.ReservationStatus.Committed)
+	if err != nil || reservation == nil {
+		return err
+	}
+
+	for _, item := range reservation.Items {
+		_, err := r.stock.UpdateOne(ctx, bson.M{"_id": item.ProductId}, bson.M{
+			"$inc": bson.M{"reserved": -item.Quantity, "on_hand": -item.Quantity},
+			"$set": bson.M{"updated_at": time.Now()},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeReservation moves a reservation out of RESERVED. It returns nil without
+// an error when the reservation was already closed with the same status, so
+// retried release/commit calls do not move stock twice.
+func (r *StockRepository) closeReservation(ctx context.Context, orderID, status string) (*types.StockReservation, error) {
+	filter := bson.M{"_id": orderID, "status": config.ReservationStatus.Reserved}
+	update := bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}}
+
+	var reservation types.StockReservation
+	err := r.reservations.FindOneAndUpdate(ctx, filter, update).Decode(&reservation)
+	if err == nil {
+		return &reservation, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	var existing types.StockReservation
+	if err := r.reservations.FindOne(ctx, bson.M{"_id": orderID}).Decode(&existing); err != nil {
+		return nil, err
+	}
+	if existing.Status == status {
+		return nil, nil
+	}
+	return nil, errReservationClosed
+}
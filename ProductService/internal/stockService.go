@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"tesodev-korpes/ProductService/internal/types"
+	"tesodev-korpes/pkg/customError"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func (s *Service) GetStock(ctx context.Context, productID string) (*types.StockResponseModel, error) {
+	if _, err := s.repo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	stock, err := s.stockRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// A product without a ledger entry simply has nothing in stock.
+			return ToStockResponse(&types.Stock{ProductId: productID}), nil
+		}
+		return nil, err
+	}
+	return ToStockResponse(stock), nil
+}
+
+func (s *Service) SetStock(ctx context.Context, productID string, onHand int) (*types.StockResponseModel, error) {
+	if _, err := s.repo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	stock, err := s.stockRepo.SetOnHand(ctx, productID, onHand)
+	if err != nil {
+		if errors.Is(err, errBelowReserved) {
+			return nil, customError.NewConflict(customError.StockBelowReserved)
+		}
+		return nil, err
+	}
+	return ToStockResponse(stock), nil
+}
+
+func (s *Service) ReserveStock(ctx context.Context, req *types.ReserveStockRequestModel) error {
+	err := s.stockRepo.Reserve(ctx, req.OrderId, FromReserveStockRequest(req))
+	if errors.Is(err, errInsufficientStock) {
+		return customError.NewConflict(customError.InsufficientStock)
+	}
+	return err
+}
+
+func (s *Service) ReleaseStock(ctx context.Context, orderID string) error {
+	return toReservationError(s.stockRepo.Release(ctx, orderID))
+}
+
+func (s *Service) CommitStock(ctx context.Context, orderID string) error {
+	return toReservationError(s.stockRepo.Commit(ctx, orderID))
+}
+
+func toReservationError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return customError.NewNotFound(customError.StockReservationNotFound)
+	case errors.Is(err, errReservationClosed):
+		return customError.NewConflict(customError.StockReservationClosed)
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"tesodev-korpes/ProductService/internal/types"
+	"tesodev-korpes/pkg/customError"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestFromReserveStockRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []types.ReservationItemRequestModel
+		want  []types.ReservationItem
+	}{
+		{"no items", nil, []types.ReservationItem{}},
+		{"distinct products", []types.ReservationItemRequestModel{{ProductId: "a", Quantity: 1}, {ProductId: "b", Quantity: 2}}, []types.ReservationItem{{ProductId: "a", Quantity: 1}, {ProductId: "b", Quantity: 2}}},
+		{"repeated product merged in place", []types.ReservationItemRequestModel{{ProductId: "a", Quantity: 1}, {ProductId: "b", Quantity: 2}, {ProductId: "a", Quantity: 3}}, []types.ReservationItem{{ProductId: "a", Quantity: 4}, {ProductId: "b", Quantity: 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromReserveStockRequest(&types.ReserveStockRequestModel{OrderId: "o", Items: tt.items})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("FromReserveStockRequest = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToStockResponseAvailable(t *testing.T) {
+	tests := []struct {
+		onHand, reserved, want int
+	}{
+		{10, 0, 10},
+		{10, 4, 6},
+		{10, 10, 0},
+	}
+
+	for _, tt := range tests {
+		got := ToStockResponse(&types.Stock{ProductId: "p", OnHand: tt.onHand, Reserved: tt.reserved})
+		if got.Available != tt.want {
+			t.Errorf("Available(on hand %d, reserved %d) = %d, want %d", tt.onHand, tt.reserved, got.Available, tt.want)
+		}
+	}
+}
+
+func TestToReservationError(t *testing.T) {
+	other := errors.New("connection reset")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantSame   bool
+	}{
+		{"no error", nil, 0, true},
+		{"no reservation", mongo.ErrNoDocuments, http.StatusNotFound, false},
+		{"already closed", fmt.Errorf("commit: %w", errReservationClosed), http.StatusConflict, false},
+		{"other errors unchanged", other, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toReservationError(tt.err)
+			if tt.wantSame {
+				if got != tt.err {
+					t.Fatalf("toReservationError = %v, want %v", got, tt.err)
+				}
+				return
+			}
+
+			var appErr *customError.AppError
+			if !errors.As(got, &appErr) || appErr.HTTPStatus != tt.wantStatus {
+				t.Fatalf("toReservationError = %v, want status %d", got, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package types
+
+import "time"
+
+type Product struct {
+	Id          string    `bson:"_id,omitempty"`
+	Name        string    `bson:"name"`
+	Description string    `bson:"description"`
+	Category    string    `bson:"category"`
+	Price       float64   `bson:"price"`
+	Weight      float64   `bson:"weight,omitempty"`
+	IsActive    bool      `bson:"is_active"`
+	CreatedAt   time.Time `bson:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at"`
+}
+
+type Stock struct {
+	ProductId string    `bson:"_id"`
+	OnHand    int       `bson:"on_hand"`
+	Reserved  int       `bson:"reserved"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type StockReservation struct {
+	OrderId   string            `bson:"_id"`
+	Items     []ReservationItem `bson:"items"`
+	Status    string            `bson:"status"`
+	CreatedAt time.Time         `bson:"created_at"`
+	UpdatedAt time.Time         `bson:"updated_at"`
+}
+
+type ReservationItem struct {
+	ProductId string `bson:"product_id"`
+	Quantity  int    `bson:"quantity"`
+}
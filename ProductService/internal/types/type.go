@@ -0,0 +1,67 @@
+package types
+
+import "time"
+
+type CreateProductRequestModel struct {
+	Name        string  `json:"name" validate:"required"`
+	Description string  `json:"description,omitempty"`
+	Category    string  `json:"category" validate:"required"`
+	Price       float64 `json:"price" validate:"required,gt=0"`
+	Weight      float64 `json:"weight,omitempty" validate:"gte=0"`
+}
+
+type UpdateProductRequestModel struct {
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Price       *float64 `json:"price,omitempty"`
+	Weight      *float64 `json:"weight,omitempty"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+type ProductResponseModel struct {
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Price       float64   `json:"price"`
+	Weight      float64   `json:"weight,omitempty"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type LookupRequestModel struct {
+	Ids []string `json:"ids" validate:"required"`
+}
+
+type UpdateStockRequestModel struct {
+	OnHand int `json:"on_hand" validate:"gte=0"`
+}
+
+type StockResponseModel struct {
+	ProductId string    `json:"product_id"`
+	OnHand    int       `json:"on_hand"`
+	Reserved  int       `json:"reserved"`
+	Available int       `json:"available"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ReserveStockRequestModel struct {
+	OrderId string                        `json:"order_id" validate:"required"`
+	Items   []ReservationItemRequestModel `json:"items" validate:"required,dive"`
+}
+
+type ReservationItemRequestModel struct {
+	ProductId string `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,gt=0"`
+}
+
+type StockReservationRequestModel struct {
+	OrderId string `json:"order_id" validate:"required"`
+}
+
+type Pagination struct {
+	Page  int
+	Limit int
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"tesodev-korpes/pkg/customError"
+	"tesodev-korpes/pkg/validators"
+)
+
+func (c CreateProductRequestModel) CreateValidate() *customError.AppError {
+
+	if !validators.IsEmpty(c.Name) {
+		return customError.NewUnprocessableEntity(customError.InvalidProductName, nil)
+	}
+	if !validators.IsEmpty(c.Category) {
+		return customError.NewUnprocessableEntity(customError.InvalidProductCategory, nil)
+	}
+	if c.Price <= 0 {
+		return customError.NewUnprocessableEntity(customError.InvalidProductPrice, nil)
+	}
+	if c.Weight < 0 {
+		return customError.NewUnprocessableEntity(customError.InvalidProductWeight, nil)
+	}
+
+	return nil
+}
+
+func (c UpdateProductRequestModel) UpdateValidate() *customError.AppError {
+
+	if c.Price != nil && *c.Price <= 0 {
+		return customError.NewUnprocessableEntity(customError.InvalidProductPrice, nil)
+	}
+	if c.Weight != nil && *c.Weight < 0 {
+		return customError.NewUnprocessableEntity(customError.InvalidProductWeight, nil)
+	}
+
+	return nil
+}
+
+func (c LookupRequestModel) LookupValidate() *customError.AppError {
+
+	if len(c.Ids) == 0 {
+		return customError.NewBadRequest(customError.InvalidProductBody)
+	}
+	for _, id := range c.Ids {
+		if !validators.IsValidUUID(id) {
+			return customError.NewBadRequest(customError.InvalidProductID)
+		}
+	}
+
+	return nil
+}
+
+func (c UpdateStockRequestModel) UpdateValidate() *customError.AppError {
+
+	if c.OnHand < 0 {
+		return customError.NewUnprocessableEntity(customError.InvalidStockQuantity, nil)
+	}
+
+	return nil
+}
+
+func (c ReserveStockRequestModel) ReserveValidate() *customError.AppError {
+
+	if !validators.IsValidUUID(c.OrderId) {
+		return customError.NewBadRequest(customError.InvalidOrderID)
+	}
+	if len(c.Items) == 0 {
+		return customError.NewBadRequest(customError.InvalidStockBody)
+	}
+	for _, item := range c.Items {
+		if !validators.IsValidUUID(item.ProductId) {
+			return customError.NewBadRequest(customError.InvalidProductID)
+		}
+		if item.Quantity <= 0 {
+			return customError.NewUnprocessableEntity(customError.InvalidStockQuantity, nil)
+		}
+	}
+
+	return nil
+}
+
+func (c StockReservationRequestModel) ReservationValidate() *customError.AppError {
+
+	if !validators.IsValidUUID(c.OrderId) {
+		return customError.NewBadRequest(customError.InvalidOrderID)
+	}
+
+	return nil
+}
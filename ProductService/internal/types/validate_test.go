@@ -0,0 +1,50 @@
+package types
+
+import "testing"
+
+func TestReserveValidate(t *testing.T) {
+	orderID := "0b5f9d3e-8c1a-4f7e-9a2b-3c4d5e6f7a8b"
+	productID := "7c1e2d3f-4a5b-4c6d-8e7f-9a0b1c2d3e4f"
+
+	tests := []struct {
+		name  string
+		req   ReserveStockRequestModel
+		valid bool
+	}{
+		{"valid", ReserveStockRequestModel{OrderId: orderID, Items: []ReservationItemRequestModel{{ProductId: productID, Quantity: 2}}}, true},
+		{"order id is not a uuid", ReserveStockRequestModel{OrderId: "42", Items: []ReservationItemRequestModel{{ProductId: productID, Quantity: 2}}}, false},
+		{"no items", ReserveStockRequestModel{OrderId: orderID}, false},
+		{"product id is not a uuid", ReserveStockRequestModel{OrderId: orderID, Items: []ReservationItemRequestModel{{ProductId: "p", Quantity: 2}}}, false},
+		{"zero quantity", ReserveStockRequestModel{OrderId: orderID, Items: []ReservationItemRequestModel{{ProductId: productID, Quantity: 0}}}, false},
+		{"negative quantity", ReserveStockRequestModel{OrderId: orderID, Items: []ReservationItemRequestModel{{ProductId: productID, Quantity: -1}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.ReserveValidate()
+			if tt.valid && err != nil {
+				t.Fatalf("ReserveValidate() = %v, want nil", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatalf("ReserveValidate() = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestUpdateStockValidate(t *testing.T) {
+	tests := []struct {
+		onHand int
+		valid  bool
+	}{
+		{0, true},
+		{25, true},
+		{-1, false},
+	}
+
+	for _, tt := range tests {
+		if err := (UpdateStockRequestModel{OnHand: tt.onHand}).UpdateValidate(); (err == nil) != tt.valid {
+			t.Errorf("UpdateValidate(on hand %d) = %v, want valid %v", tt.onHand, err, tt.valid)
+		}
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	customercmd "tesodev-korpes/CustomerService/cmd"
 	ordercmd "tesodev-korpes/OrderService/cmd"
+	productcmd "tesodev-korpes/ProductService/cmd"
 	_ "tesodev-korpes/docs"
 	"tesodev-korpes/pkg"
 	"tesodev-korpes/pkg/middleware"
 	"tesodev-korpes/shared/config"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	echoSwagger "github.com/swaggo/echo-swagger"
@@ -15,6 +17,10 @@ import (
 
 func main() {
 
+	if err := config.Cfg.LoadConfiguredPolicy(); err != nil {
+		panic(err)
+	}
+
 	dbConf := config.GetDBConfig("dev")
 
 	client, err := pkg.GetMongoClient(dbConf.MongoDuration, dbConf.MongoClientURI)
@@ -37,9 +43,22 @@ func main() {
 	orderEcho.Use(middleware.ErrorHandler())
 	orderEcho.GET("/swagger/*", echoSwagger.WrapHandler)
 
+	productEcho := echo.New()
+	productEcho.Use(middleware.CorrelationIdMiddleware())
+	productEcho.Use(middleware.LoggingMiddleware)
+	productEcho.Use(middleware.RecoveryMiddleware)
+	productEcho.Use(middleware.ErrorHandler())
+	productEcho.GET("/swagger/*", echoSwagger.WrapHandler)
+
+	go config.Cfg.WatchPolicy(5 * time.Second)
+
 	go func() {
 		customercmd.BootCustomerService(client, customerEcho)
 	}()
 
+	go func() {
+		productcmd.BootProductService(client, productEcho)
+	}()
+
 	ordercmd.BootOrderService(client, orderEcho)
 }
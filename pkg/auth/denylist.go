@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxCachedRevocations bounds the revocations and the lookups remembered in
+// memory; expired ones are swept once it is reached.
+const maxCachedRevocations = 10000
+
+// Denylist holds the jti of access tokens revoked before they expire. Entries
+// are dropped by a TTL index once the token would have expired anyway.
+//
+// A revocation is never lifted, so revoked jtis are remembered until their
+// token expires. A jti found not revoked is remembered for ttl, so a token
+// revoked by another process is rejected at most ttl later; revocations made
+// through this Denylist take effect at once. A zero ttl looks every such jti
+// up again.
+type Denylist struct {
+	collection *mongo.Collection
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	revoked map[string]time.Time
+	allowed map[string]time.Time
+}
+
+type revokedToken struct {
+	Jti       string    `bson:"_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func NewDenylist(collection *mongo.Collection, ttl time.Duration) *Denylist {
+	return &Denylist{
+		collection: collection,
+		ttl:        ttl,
+		revoked:    make(map[string]time.Time),
+		allowed:    make(map[string]time.Time),
+	}
+}
+
+// EnsureIndexes creates the TTL index that expires denylist entries.
+func (d *Denylist) EnsureIndexes(ctx context.Context) error {
+	_, err := d.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+// Revoke denies the token with the given jti until expiresAt.
+func (d *Denylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := d.collection.ReplaceOne(ctx,
+		bson.M{"_id": jti},
+		revokedToken{Jti: jti, ExpiresAt: expiresAt},
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	d.remember(jti, expiresAt, time.Now())
+	return nil
+}
+
+// IsRevoked reports whether the token with the given jti was revoked.
+func (d *Denylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	now := time.Now()
+
+	d.mu.Lock()
+	expiresAt, revoked := d.revoked[jti]
+	until, allowed := d.allowed[jti]
+	d.mu.Unlock()
+	if revoked {
+		return now.Before(expiresAt), nil
+	}
+	if allowed && now.Before(until) {
+		return false, nil
+	}
+
+	var entry revokedToken
+	err := d.collection.FindOne(ctx, bson.M{"_id": jti}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		d.allow(jti, now)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !now.Before(entry.ExpiresAt) {
+		return false, nil
+	}
+
+	d.remember(jti, entry.ExpiresAt, now)
+	return true, nil
+}
+
+// remember caches a revocation until its token expires.
+func (d *Denylist) remember(jti string, expiresAt, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sweep(d.revoked, now)
+	d.revoked[jti] = expiresAt
+	delete(d.allowed, jti)
+}
+
+// allow caches for ttl that a jti is not revoked. When the cache is full of
+// live entries the answer is not cached.
+func (d *Denylist) allow(jti string, now time.Time) {
+	if d.ttl <= 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if sweep(d.allowed, now) {
+		d.allowed[jti] = now.Add(d.ttl)
+	}
+}
+
+// sweep drops the expired entries of a full cache and reports whether there
+// is room for another one.
+func sweep(entries map[string]time.Time, now time.Time) bool {
+	if len(entries) < maxCachedRevocations {
+		return true
+	}
+	for id, exp := range entries {
+		if !now.Before(exp) {
+			delete(entries, id)
+		}
+	}
+	return len(entries) < maxCachedRevocations
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestDenylistIsRevoked(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+	notFound := mtest.CreateCursorResponse(0, "tesodev.revoked_token", mtest.FirstBatch)
+	revoked := mtest.CreateCursorResponse(0, "tesodev.revoked_token", mtest.FirstBatch,
+		bson.D{{Key: "_id", Value: "jti-revoked"}, {Key: "expires_at", Value: time.Now().Add(time.Minute)}})
+
+	mt.Run("a jti not revoked is looked up once per ttl", func(mt *mtest.T) {
+		denylist := NewDenylist(mt.Coll, time.Minute)
+		mt.AddMockResponses(notFound)
+
+		for i := 0; i < 3; i++ {
+			if got, err := denylist.IsRevoked(ctx, "jti-1"); got || err != nil {
+				mt.Fatalf("IsRevoked = %v, %v, want false", got, err)
+			}
+		}
+		if started := len(mt.GetAllStartedEvents()); started != 1 {
+			mt.Fatalf("%d lookups, want 1", started)
+		}
+
+		denylist.allowed["jti-1"] = time.Now().Add(-time.Second)
+		mt.AddMockResponses(revoked)
+		if got, err := denylist.IsRevoked(ctx, "jti-1"); !got || err != nil {
+			mt.Fatalf("IsRevoked after the ttl = %v, %v, want true", got, err)
+		}
+	})
+
+	mt.Run("a zero ttl looks every time", func(mt *mtest.T) {
+		denylist := NewDenylist(mt.Coll, 0)
+		mt.AddMockResponses(notFound, notFound)
+
+		for i := 0; i < 2; i++ {
+			if got, err := denylist.IsRevoked(ctx, "jti-1"); got || err != nil {
+				mt.Fatalf("IsRevoked = %v, %v, want false", got, err)
+			}
+		}
+		if started := len(mt.GetAllStartedEvents()); started != 2 {
+			mt.Fatalf("%d lookups, want 2", started)
+		}
+	})
+
+	mt.Run("revoking overrides a cached lookup", func(mt *mtest.T) {
+		denylist := NewDenylist(mt.Coll, time.Minute)
+		mt.AddMockResponses(notFound, mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		if got, _ := denylist.IsRevoked(ctx, "jti-1"); got {
+			mt.Fatalf("IsRevoked before Revoke = true, want false")
+		}
+		if err := denylist.Revoke(ctx, "jti-1", time.Now().Add(time.Minute)); err != nil {
+			mt.Fatalf("Revoke error = %v", err)
+		}
+		if got, err := denylist.IsRevoked(ctx, "jti-1"); !got || err != nil {
+			mt.Fatalf("IsRevoked after Revoke = %v, %v, want true", got, err)
+		}
+	})
+}
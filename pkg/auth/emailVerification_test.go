@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestEmailVerificationToken(t *testing.T) {
+	token, err := GenerateEmailVerificationToken("customer-1", "jane@example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateEmailVerificationToken error = %v", err)
+	}
+	claims, err := VerifyEmailVerificationToken(token)
+	if err != nil {
+		t.Fatalf("VerifyEmailVerificationToken error = %v", err)
+	}
+	if claims.Subject != "customer-1" || claims.Email != "jane@example.com" {
+		t.Fatalf("claims = %+v, want customer-1 at jane@example.com", claims)
+	}
+}
+
+func TestEmailVerificationTokenRejects(t *testing.T) {
+	access, err := GenerateJWT(Identity{Id: "customer-1", Role: "user", SessionId: "session-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := GenerateEmailVerificationToken("customer-1", "jane@example.com", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noEmail, err := GenerateEmailVerificationToken("customer-1", "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherIssuer, err := sign(&EmailVerificationClaims{
+		Email: "jane@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "customer-1",
+			Issuer:    "someone-else",
+			Audience:  jwt.ClaimStrings{emailVerificationAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"access token", access},
+		{"expired", expired},
+		{"no email", noEmail},
+		{"other issuer", otherIssuer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := VerifyEmailVerificationToken(tt.token); err == nil {
+				t.Fatalf("VerifyEmailVerificationToken error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestVerificationTokenIsNotAnAccessToken(t *testing.T) {
+	token, err := GenerateEmailVerificationToken("customer-1", "jane@example.com", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyJWT(token); err == nil {
+		t.Fatalf("VerifyJWT accepted an email verification token")
+	}
+}
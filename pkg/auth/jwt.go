@@ -2,48 +2,153 @@ package auth
 
 import (
 	"errors"
-	"tesodev-korpes/pkg/customError"
 	"tesodev-korpes/shared/config"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte(config.GetAuthConfig().JWTSecret)
+var (
+	keys       = mustLoadKeySet()
+	authConfig = config.GetAuthConfig()
+)
+
+func mustLoadKeySet() *KeySet {
+	keySet, err := LoadKeySet(authConfig)
+	if err != nil {
+		panic(err)
+	}
+	return keySet
+}
 
+// AccessTokenTTL is how long an access token is valid.
+const AccessTokenTTL = 1 * time.Hour
+
+// ServiceRole is the role of the tokens services call each other with. No
+// customer account has it, so routes only services may use grant their
+// permission to this role alone.
+const ServiceRole = "service"
+
+// serviceTokenTTL is how long a service token is valid. Services sign a new
+// one for every call.
+const serviceTokenTTL = 5 * time.Minute
+
+// Claims carries everything the services need to authorize a request, so
+// they do not have to look the customer up. SessionID is the login session
+// the token belongs to; it stays the same across refreshes.
 type Claims struct {
-	ID string `json:"id"`
+	ID         string `json:"id"`
+	Role       string `json:"role"`
+	Membership string `json:"membership"`
+	SessionID  string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(Id string) (string, error) {
+// Identity is the customer an access token is issued for.
+type Identity struct {
+	Id         string
+	Role       string
+	Membership string
+	SessionId  string
+}
+
+// GenerateJWT issues an access token for the customer. Every token gets a
+// unique jti so it can be revoked before it expires.
+func GenerateJWT(identity Identity) (string, error) {
+	token, _, err := IssueJWT(identity)
+	return token, err
+}
+
+// IssueJWT is GenerateJWT that also returns the claims of the token, for
+// callers that keep track of the jti to revoke the token later.
+func IssueJWT(identity Identity) (string, *Claims, error) {
+	now := time.Now()
 	claims := &Claims{
-		ID: Id,
+		ID:         identity.Id,
+		Role:       identity.Role,
+		Membership: identity.Membership,
+		SessionID:  identity.SessionId,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.NewString(),
+			Subject:   identity.Id,
+			Issuer:    authConfig.Issuer,
+			Audience:  jwt.ClaimStrings{authConfig.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	token, err := sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
 }
 
+// GenerateServiceJWT issues a short-lived token identifying the calling
+// service, for requests made on its own behalf rather than a customer's.
+func GenerateServiceJWT(service string) (string, error) {
+	now := time.Now()
+	id := ServiceRole + ":" + service
+	claims := &Claims{
+		ID:   id,
+		Role: ServiceRole,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   id,
+			Issuer:    authConfig.Issuer,
+			Audience:  jwt.ClaimStrings{authConfig.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(serviceTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	return sign(claims)
+}
+
+// sign signs the claims with the current signing key and names it in the kid
+// header.
+func sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(keys.signingMethod, claims)
+	token.Header["kid"] = keys.signingID
+	return token.SignedString(keys.signingKey)
+}
+
+// VerifyJWT checks the token against the verification key its kid header
+// names, and checks its issuer and audience.
 func VerifyJWT(tokenString string) (*Claims, error) {
 	claims := &Claims{}
-
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, customError.NewUnauthorized(customError.MissingAuthToken)
-		}
-		return jwtSecret, nil
-	})
-	if err != nil {
+	if err := parseToken(tokenString, claims, authConfig.Audience); err != nil {
 		return nil, err
 	}
+	if claims.RegisteredClaims.ID == "" || claims.ID == "" || claims.Role == "" {
+		return nil, errors.New("token lacks jti, id or role")
+	}
 
+	return claims, nil
+}
+
+// parseToken checks the token against the verification key its kid header
+// names, and checks its issuer and the given audience.
+func parseToken(tokenString string, claims jwt.Claims, audience string) error {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		return keys.lookup(token)
+	}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(authConfig.Issuer),
+		jwt.WithAudience(audience),
+	)
+	if err != nil {
+		return err
+	}
 	if !token.Valid {
-		return nil, errors.New("token is invalid or expired")
+		return errors.New("token is invalid or expired")
 	}
+	return nil
+}
 
-	return claims, nil
+// JWKS returns the public keys tokens are verified with.
+func JWKS() JWKSet {
+	return keys.JWKS()
 }
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestGenerateJWT(t *testing.T) {
+	identity := Identity{Id: "customer-1", Role: "admin", Membership: "premium", SessionId: "session-1"}
+
+	first, err := GenerateJWT(identity)
+	if err != nil {
+		t.Fatalf("GenerateJWT error = %v", err)
+	}
+	second, err := GenerateJWT(identity)
+	if err != nil {
+		t.Fatalf("GenerateJWT error = %v", err)
+	}
+
+	claims, err := VerifyJWT(first)
+	if err != nil {
+		t.Fatalf("VerifyJWT error = %v", err)
+	}
+	if claims.ID != "customer-1" || claims.Subject != "customer-1" || claims.Role != "admin" ||
+		claims.Membership != "premium" || claims.SessionID != "session-1" {
+		t.Fatalf("VerifyJWT claims = %+v, want the identity back", claims)
+	}
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != AccessTokenTTL {
+		t.Fatalf("token lives %s, want %s", ttl, AccessTokenTTL)
+	}
+
+	again, err := VerifyJWT(second)
+	if err != nil {
+		t.Fatalf("VerifyJWT error = %v", err)
+	}
+	if claims.RegisteredClaims.ID == "" || claims.RegisteredClaims.ID == again.RegisteredClaims.ID {
+		t.Fatalf("jti = %q and %q, want two distinct ids", claims.RegisteredClaims.ID, again.RegisteredClaims.ID)
+	}
+}
+
+func TestGenerateServiceJWT(t *testing.T) {
+	token, err := GenerateServiceJWT("order-service")
+	if err != nil {
+		t.Fatalf("GenerateServiceJWT error = %v", err)
+	}
+	claims, err := VerifyJWT(token)
+	if err != nil {
+		t.Fatalf("VerifyJWT error = %v", err)
+	}
+	if claims.Role != ServiceRole || claims.ID != "service:order-service" || claims.SessionID != "" {
+		t.Fatalf("VerifyJWT claims = %+v, want a service token for order-service", claims)
+	}
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != serviceTokenTTL {
+		t.Fatalf("token lives %s, want %s", ttl, serviceTokenTTL)
+	}
+}
+
+func TestVerifyJWTRejects(t *testing.T) {
+	now := time.Now()
+	valid := func() *Claims {
+		return &Claims{
+			ID:   "customer-1",
+			Role: "user",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        "jti-1",
+				Subject:   "customer-1",
+				Issuer:    authConfig.Issuer,
+				Audience:  jwt.ClaimStrings{authConfig.Audience},
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+				IssuedAt:  jwt.NewNumericDate(now),
+			},
+		}
+	}
+	signed := func(change func(*Claims)) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			claims := valid()
+			change(claims)
+			token, err := sign(claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}
+	}
+
+	tests := []struct {
+		name  string
+		token func(t *testing.T) string
+		valid bool
+	}{
+		{"valid", signed(func(*Claims) {}), true},
+		{"expired", signed(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute)) }), false},
+		{"no expiry", signed(func(c *Claims) { c.ExpiresAt = nil }), false},
+		{"other issuer", signed(func(c *Claims) { c.Issuer = "someone-else" }), false},
+		{"other audience", signed(func(c *Claims) { c.Audience = jwt.ClaimStrings{"other-service"} }), false},
+		{"no jti", signed(func(c *Claims) { c.RegisteredClaims.ID = "" }), false},
+		{"no id", signed(func(c *Claims) { c.ID = "" }), false},
+		{"no role", signed(func(c *Claims) { c.Role = "" }), false},
+		{"HMAC signed", func(t *testing.T) string {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, valid())
+			token.Header["kid"] = keys.signingID
+			signed, err := token.SignedString([]byte("secret"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return signed
+		}, false},
+		{"unsigned", func(t *testing.T) string {
+			signed, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return signed
+		}, false},
+		{"garbage", func(*testing.T) string { return "not.a.token" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyJWT(tt.token(t))
+			if tt.valid && err != nil {
+				t.Fatalf("VerifyJWT error = %v, want nil", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatalf("VerifyJWT error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"tesodev-korpes/shared/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const minRSABits = 2048
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+// KeySet is the signing key of this process and every key it accepts
+// signatures from, by kid. Rotating keys means signing with a new key while
+// the old public key stays in the verification set until its tokens expire.
+type KeySet struct {
+	signingID     string
+	signingMethod jwt.SigningMethod
+	signingKey    crypto.Signer
+	verification  map[string]verificationKey
+}
+
+// LoadKeySet reads the signing key and the verification keys named in cfg.
+// The kid of a key file is its base name without extension, unless
+// SigningKeyID is set. Without a signing key file an ephemeral Ed25519 key is
+// generated, which is only fit for local development.
+func LoadKeySet(cfg config.AuthConfig) (*KeySet, error) {
+	keys := &KeySet{verification: make(map[string]verificationKey)}
+
+	if cfg.SigningKeyFile != "" {
+		signer, err := readPrivateKey(cfg.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys.signingKey = signer
+		keys.signingID = cfg.SigningKeyID
+		if keys.signingID == "" {
+			keys.signingID = keyID(cfg.SigningKeyFile)
+		}
+	} else {
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		keys.signingKey = private
+		keys.signingID = "ephemeral-" + uuid.NewString()
+		log.Printf("auth: JWT_SIGNING_KEY_FILE not set, signing with ephemeral key %s; tokens will not survive a restart", keys.signingID)
+	}
+
+	method, err := signingMethod(keys.signingKey.Public())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.SigningKeyFile, err)
+	}
+	keys.signingMethod = method
+	keys.verification[keys.signingID] = verificationKey{method: method, public: keys.signingKey.Public()}
+
+	if cfg.VerificationKeysDir == "" {
+		return keys, nil
+	}
+	files, err := filepath.Glob(filepath.Join(cfg.VerificationKeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if err := keys.addVerificationKey(keyID(file), file); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// JWK is one public key in JSON Web Key form (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the body of a JWKS endpoint.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns every verification key, sorted by kid.
+func (k *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(k.verification))}
+	for kid, key := range k.verification {
+		jwk := JWK{Kid: kid, Use: "sig", Alg: key.method.Alg()}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// lookup returns the key a token header names with kid, provided the token is
+// signed with that key's algorithm.
+func (k *KeySet) lookup(token *jwt.Token) (crypto.PublicKey, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.verification[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("key %q does not sign with %s", kid, token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+func (k *KeySet) addVerificationKey(kid, file string) error {
+	public, err := readPublicKey(file)
+	if err != nil {
+		return err
+	}
+	method, err := signingMethod(public)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	if existing, ok := k.verification[kid]; ok {
+		if equal, ok := existing.public.(interface{ Equal(crypto.PublicKey) bool }); ok && equal.Equal(public) {
+			return nil
+		}
+		return fmt.Errorf("%s: key id %q is already used by another key", file, kid)
+	}
+	k.verification[kid] = verificationKey{method: method, public: public}
+	return nil
+}
+
+func signingMethod(public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA key has %d bits, at least %d are required", key.N.BitLen(), minRSABits)
+		}
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", public)
+}
+
+func readPrivateKey(file string) (crypto.Signer, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("%s: unsupported private key type %T", file, key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%s: not a PKCS#8 or PKCS#1 private key", file)
+}
+
+func readPublicKey(file string) (crypto.PublicKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return key, nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New(file + ": no PEM block found")
+	}
+	return block, nil
+}
+
+func keyID(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
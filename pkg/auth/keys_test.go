@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"tesodev-korpes/shared/config"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKeyPair writes a private key and its public key as PEM files named
+// after kid, the private one in dir and the public one in dir/public.
+func writeKeyPair(t *testing.T, dir, kid string, private crypto.Signer, pkcs1 bool) (privateFile, publicFile string) {
+	t.Helper()
+
+	var der []byte
+	var err error
+	if pkcs1 {
+		der = x509.MarshalPKCS1PrivateKey(private.(*rsa.PrivateKey))
+	} else {
+		der, err = x509.MarshalPKCS8PrivateKey(private)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateFile = filepath.Join(dir, kid+".key")
+	writePEM(t, privateFile, "PRIVATE KEY", der)
+
+	der, err = x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "public"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	publicFile = filepath.Join(dir, "public", kid+".pem")
+	writePEM(t, publicFile, "PUBLIC KEY", der)
+	return privateFile, publicFile
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newEd25519(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return private
+}
+
+func newRSA(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return private
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	edFile, _ := writeKeyPair(t, dir, "2026-01", newEd25519(t), false)
+	rsaFile, _ := writeKeyPair(t, dir, "rsa", newRSA(t, 2048), true)
+	weakFile, _ := writeKeyPair(t, dir, "weak", newRSA(t, 1024), false)
+	notPEM := filepath.Join(dir, "garbage.key")
+	if err := os.WriteFile(notPEM, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.AuthConfig
+		wantKid string
+		wantAlg string
+		wantErr bool
+	}{
+		{"Ed25519 key named by its file", config.AuthConfig{SigningKeyFile: edFile}, "2026-01", "EdDSA", false},
+		{"explicit key id", config.AuthConfig{SigningKeyFile: edFile, SigningKeyID: "main"}, "main", "EdDSA", false},
+		{"PKCS#1 RSA key", config.AuthConfig{SigningKeyFile: rsaFile}, "rsa", "RS256", false},
+		{"RSA key too short", config.AuthConfig{SigningKeyFile: weakFile}, "", "", true},
+		{"not a PEM file", config.AuthConfig{SigningKeyFile: notPEM}, "", "", true},
+		{"missing file", config.AuthConfig{SigningKeyFile: filepath.Join(dir, "missing.key")}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keySet, err := LoadKeySet(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("LoadKeySet error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadKeySet error = %v", err)
+			}
+			if keySet.signingID != tt.wantKid || keySet.signingMethod.Alg() != tt.wantAlg {
+				t.Fatalf("signing key = %s/%s, want %s/%s", keySet.signingID, keySet.signingMethod.Alg(), tt.wantKid, tt.wantAlg)
+			}
+			if _, ok := keySet.verification[tt.wantKid]; !ok {
+				t.Fatalf("signing key %s is not in the verification set", tt.wantKid)
+			}
+		})
+	}
+}
+
+func TestLoadKeySetVerificationKeys(t *testing.T) {
+	dir := t.TempDir()
+	// The public half of the signing key sits next to the older keys and
+	// must not count twice.
+	current, _ := writeKeyPair(t, dir, "2026-02", newEd25519(t), false)
+	writeKeyPair(t, dir, "2026-01", newRSA(t, 2048), false)
+
+	keySet, err := LoadKeySet(config.AuthConfig{SigningKeyFile: current, VerificationKeysDir: filepath.Join(dir, "public")})
+	if err != nil {
+		t.Fatalf("LoadKeySet error = %v", err)
+	}
+
+	jwks := keySet.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(jwks.Keys))
+	}
+	old, now := jwks.Keys[0], jwks.Keys[1]
+	if old.Kid != "2026-01" || old.Kty != "RSA" || old.Alg != "RS256" || old.N == "" || old.E != "AQAB" {
+		t.Fatalf("old key = %+v, want an RS256 RSA key with kid 2026-01", old)
+	}
+	if now.Kid != "2026-02" || now.Kty != "OKP" || now.Crv != "Ed25519" || now.Alg != "EdDSA" || now.X == "" {
+		t.Fatalf("current key = %+v, want an EdDSA Ed25519 key with kid 2026-02", now)
+	}
+	for _, key := range jwks.Keys {
+		if key.Use != "sig" {
+			t.Fatalf("key %s use = %q, want sig", key.Kid, key.Use)
+		}
+	}
+}
+
+func TestLoadKeySetConflictingKid(t *testing.T) {
+	dir := t.TempDir()
+	current, _ := writeKeyPair(t, dir, "main", newEd25519(t), false)
+	other := t.TempDir()
+	writeKeyPair(t, other, "main", newEd25519(t), false)
+
+	if _, err := LoadKeySet(config.AuthConfig{SigningKeyFile: current, VerificationKeysDir: filepath.Join(other, "public")}); err == nil {
+		t.Fatalf("LoadKeySet error = nil, want a key id conflict")
+	}
+}
+
+func TestKeySetLookup(t *testing.T) {
+	dir := t.TempDir()
+	current, _ := writeKeyPair(t, dir, "ed", newEd25519(t), false)
+	writeKeyPair(t, dir, "rsa", newRSA(t, 2048), false)
+
+	keySet, err := LoadKeySet(config.AuthConfig{SigningKeyFile: current, VerificationKeysDir: filepath.Join(dir, "public")})
+	if err != nil {
+		t.Fatalf("LoadKeySet error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		method  jwt.SigningMethod
+		kid     interface{}
+		wantErr bool
+	}{
+		{"EdDSA key", jwt.SigningMethodEdDSA, "ed", false},
+		{"RSA key", jwt.SigningMethodRS256, "rsa", false},
+		{"unknown kid", jwt.SigningMethodEdDSA, "other", true},
+		{"no kid", jwt.SigningMethodEdDSA, nil, true},
+		{"algorithm of another key", jwt.SigningMethodRS256, "ed", true},
+		{"HMAC with a public key", jwt.SigningMethodHS256, "rsa", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.New(tt.method)
+			if tt.kid != nil {
+				token.Header["kid"] = tt.kid
+			}
+			_, err := keySet.lookup(token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("lookup error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// useKeys makes the package sign and verify with keySet for the rest of the
+// test.
+func useKeys(t *testing.T, keySet *KeySet) {
+	t.Helper()
+	previous := keys
+	keys = keySet
+	t.Cleanup(func() { keys = previous })
+}
+
+func TestKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldFile, _ := writeKeyPair(t, dir, "2026-01", newRSA(t, 2048), false)
+	newFile, _ := writeKeyPair(t, dir, "2026-02", newEd25519(t), false)
+	strangerFile, _ := writeKeyPair(t, t.TempDir(), "2026-01", newRSA(t, 2048), false)
+
+	oldKeys, err := LoadKeySet(config.AuthConfig{SigningKeyFile: oldFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := LoadKeySet(config.AuthConfig{SigningKeyFile: newFile, VerificationKeysDir: filepath.Join(dir, "public")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stranger, err := LoadKeySet(config.AuthConfig{SigningKeyFile: strangerFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity := Identity{Id: "customer-1", Role: "user", Membership: "premium", SessionId: "session-1"}
+	issue := func(keySet *KeySet) string {
+		useKeys(t, keySet)
+		token, err := GenerateJWT(identity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name    string
+		signer  *KeySet
+		verify  *KeySet
+		wantErr bool
+	}{
+		{"signed with the old key, verified after rotation", oldKeys, rotated, false},
+		{"signed with the new key", rotated, rotated, false},
+		{"new key not yet known to an old verifier", rotated, oldKeys, true},
+		{"same kid, different key", stranger, rotated, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := issue(tt.signer)
+			useKeys(t, tt.verify)
+			_, err := VerifyJWT(token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyJWT error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
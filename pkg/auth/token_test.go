@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestNewOpaqueToken(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		token, hash, err := NewOpaqueToken()
+		if err != nil {
+			t.Fatalf("NewOpaqueToken error = %v", err)
+		}
+		if raw, err := base64.RawURLEncoding.DecodeString(token); err != nil || len(raw) != 32 {
+			t.Fatalf("token %q is not 32 random bytes in URL-safe base64", token)
+		}
+		if hash != HashOpaqueToken(token) || hash == token {
+			t.Fatalf("hash %q is not the storage key of token %q", hash, token)
+		}
+		if seen[token] {
+			t.Fatalf("token %q issued twice", token)
+		}
+		seen[token] = true
+	}
+}
+
+func TestHashOpaqueToken(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+
+	for _, tt := range tests {
+		if got := HashOpaqueToken(tt.token); got != tt.want {
+			t.Errorf("HashOpaqueToken(%q) = %s, want %s", tt.token, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxCachedStatuses bounds the cache; expired entries are swept once it is
+// reached.
+const maxCachedStatuses = 10000
+
+// UserStatus is what authentication needs to know about an account beyond
+// the token: whether it still exists and is active, and whether its email
+// address is verified.
+type UserStatus struct {
+	Exists        bool
+	Active        bool
+	EmailVerified bool
+}
+
+// UserStatusCache looks up account status, remembering each answer for ttl so
+// authenticated requests do not each cost a Mongo round trip. An account
+// deactivated or deleted elsewhere is rejected at most ttl later. A zero ttl
+// turns the cache off.
+type UserStatusCache struct {
+	collection *mongo.Collection
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedStatus
+}
+
+type cachedStatus struct {
+	status    UserStatus
+	expiresAt time.Time
+}
+
+// NewUserStatusCache looks statuses up in collection, the customers every
+// service authenticates against.
+func NewUserStatusCache(collection *mongo.Collection, ttl time.Duration) *UserStatusCache {
+	return &UserStatusCache{
+		collection: collection,
+		ttl:        ttl,
+		entries:    make(map[string]cachedStatus),
+	}
+}
+
+// Get returns the status of the customer with the given id.
+func (c *UserStatusCache) Get(ctx context.Context, userID string) (UserStatus, error) {
+	now := time.Now()
+	if c.ttl > 0 {
+		c.mu.Lock()
+		entry, ok := c.entries[userID]
+		c.mu.Unlock()
+		if ok && now.Before(entry.expiresAt) {
+			return entry.status, nil
+		}
+	}
+	return c.Refresh(ctx, userID)
+}
+
+// Refresh looks the status of the customer up again, skipping the cache, and
+// caches the answer. Checks that must not lag behind a change made by another
+// service, such as an email address just verified, use it.
+func (c *UserStatusCache) Refresh(ctx context.Context, userID string) (UserStatus, error) {
+	status, err := c.load(ctx, userID)
+	if err != nil {
+		return UserStatus{}, err
+	}
+
+	if c.ttl > 0 {
+		now := time.Now()
+		c.mu.Lock()
+		if len(c.entries) >= maxCachedStatuses {
+			for id, entry := range c.entries {
+				if !now.Before(entry.expiresAt) {
+					delete(c.entries, id)
+				}
+			}
+		}
+		c.entries[userID] = cachedStatus{status: status, expiresAt: now.Add(c.ttl)}
+		c.mu.Unlock()
+	}
+	return status, nil
+}
+
+// Invalidate drops the cached status of a customer, so a change made by this
+// process takes effect on its next request.
+func (c *UserStatusCache) Invalidate(userID string) {
+	c.mu.Lock()
+	delete(c.entries, userID)
+	c.mu.Unlock()
+}
+
+func (c *UserStatusCache) load(ctx context.Context, userID string) (UserStatus, error) {
+	// Customers stored before is_active or email verification existed have
+	// no such fields and count as active and verified.
+	var result struct {
+		IsActive            *bool `bson:"is_active"`
+		VerificationPending bool  `bson:"email_verification_pending"`
+	}
+
+	opts := options.FindOne().SetProjection(bson.M{"is_active": 1, "email_verification_pending": 1})
+	err := c.collection.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return UserStatus{}, nil
+	}
+	if err != nil {
+		return UserStatus{}, err
+	}
+
+	return UserStatus{
+		Exists:        true,
+		Active:        result.IsActive == nil || *result.IsActive,
+		EmailVerified: !result.VerificationPending,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestUserStatusCache(t *testing.T) {
+	// Without a collection, any lookup that reaches Mongo panics, so these
+	// cases only exercise what the cache answers by itself.
+	now := time.Now()
+	active := UserStatus{Exists: true, Active: true, EmailVerified: true}
+	cache := &UserStatusCache{
+		ttl: time.Minute,
+		entries: map[string]cachedStatus{
+			"fresh":   {status: active, expiresAt: now.Add(time.Minute)},
+			"expired": {status: active, expiresAt: now.Add(-time.Second)},
+		},
+	}
+
+	status, err := cache.Get(context.Background(), "fresh")
+	if err != nil || status != active {
+		t.Fatalf("Get(fresh) = %+v, %v, want the cached status", status, err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("Get(expired) answered from the cache, want a lookup")
+			}
+		}()
+		cache.Get(context.Background(), "expired")
+	}()
+
+	cache.Invalidate("fresh")
+	if _, ok := cache.entries["fresh"]; ok {
+		t.Fatalf("Invalidate(fresh) left the entry cached")
+	}
+	cache.Invalidate("unknown")
+}
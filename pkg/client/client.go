@@ -8,6 +8,17 @@ import (
 	"github.com/valyala/fasthttp"
 )
 
+// StatusError is returned when the remote service answers with an unexpected
+// status code, so callers can react to e.g. a 404 or 409 from another service.
+type StatusError struct {
+	Method     string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s failed: %d", e.Method, e.StatusCode)
+}
+
 type Client struct {
 	http    *fasthttp.Client
 	baseURL string
@@ -37,7 +48,7 @@ func (c *Client) Get(path string, headers map[string]string, out interface{}) er
 		return err
 	}
 	if sc := resp.StatusCode(); sc != fasthttp.StatusOK {
-		return fmt.Errorf("GET failed: %d", sc)
+		return &StatusError{Method: fasthttp.MethodGet, StatusCode: sc}
 	}
 	return json.Unmarshal(resp.Body(), out)
 }
@@ -63,7 +74,7 @@ func (c *Client) Post(path string, headers map[string]string, body interface{},
 		return err
 	}
 	if sc := resp.StatusCode(); sc != fasthttp.StatusOK && sc != fasthttp.StatusCreated {
-		return fmt.Errorf("POST failed: %d", sc)
+		return &StatusError{Method: fasthttp.MethodPost, StatusCode: sc}
 	}
 	return json.Unmarshal(resp.Body(), out)
 }
@@ -89,7 +100,7 @@ func (c *Client) Put(path string, headers map[string]string, body interface{}, o
 		return err
 	}
 	if sc := resp.StatusCode(); sc != fasthttp.StatusOK && sc != fasthttp.StatusNoContent {
-		return fmt.Errorf("PUT failed: %d", sc)
+		return &StatusError{Method: fasthttp.MethodPut, StatusCode: sc}
 	}
 	if out != nil && len(resp.Body()) > 0 {
 		return json.Unmarshal(resp.Body(), out)
@@ -118,7 +129,7 @@ func (c *Client) Patch(path string, headers map[string]string, body interface{},
 		return err
 	}
 	if sc := resp.StatusCode(); sc != fasthttp.StatusOK && sc != fasthttp.StatusNoContent {
-		return fmt.Errorf("PATCH failed: %d", sc)
+		return &StatusError{Method: fasthttp.MethodPatch, StatusCode: sc}
 	}
 	if out != nil && len(resp.Body()) > 0 {
 		return json.Unmarshal(resp.Body(), out)
@@ -140,7 +151,7 @@ func (c *Client) Delete(path string, headers map[string]string, out interface{})
 		return err
 	}
 	if sc := resp.StatusCode(); sc != fasthttp.StatusOK && sc != fasthttp.StatusNoContent {
-		return fmt.Errorf("DELETE failed: %d", sc)
+		return &StatusError{Method: fasthttp.MethodDelete, StatusCode: sc}
 	}
 	if out != nil && len(resp.Body()) > 0 {
 		return json.Unmarshal(resp.Body(), out)
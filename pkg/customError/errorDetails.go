@@ -13,6 +13,11 @@ type ErrorKey string
 
 var ErrorDefinitions = map[ErrorKey]ErrorDetails{
 	// ----- 400 Bad Request -----
+	InvalidCursor: {
+		TypeCode:   400001,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested cursor is invalid or belongs to another query.",
+	},
 	InvalidCustomerID: {
 		TypeCode:   400101,
 		StatusCode: http.StatusBadRequest,
@@ -33,6 +38,16 @@ var ErrorDefinitions = map[ErrorKey]ErrorDetails{
 		StatusCode: http.StatusBadRequest,
 		Message:    "Do not empty field role",
 	},
+	InvalidPasswordResetToken: {
+		TypeCode:   400105,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The password reset token is invalid, expired or already used.",
+	},
+	InvalidVerificationToken: {
+		TypeCode:   400106,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The email verification token is invalid or expired.",
+	},
 	InvalidOrderID: {
 		TypeCode:   400201,
 		StatusCode: http.StatusBadRequest,
@@ -48,6 +63,91 @@ var ErrorDefinitions = map[ErrorKey]ErrorDetails{
 		StatusCode: http.StatusBadRequest,
 		Message:    "The requested invalid order body json.",
 	},
+	InvalidPaymentBody: {
+		TypeCode:   400204,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid payment body json.",
+	},
+	InvalidReturnBody: {
+		TypeCode:   400205,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid return body json.",
+	},
+	InvalidReturnID: {
+		TypeCode:   400206,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid return id.",
+	},
+	InvalidCartBody: {
+		TypeCode:   400207,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid cart body json.",
+	},
+	InvalidCouponBody: {
+		TypeCode:   400208,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid discount code body json.",
+	},
+	InvalidCouponCode: {
+		TypeCode:   400209,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested discount code is invalid.",
+	},
+	InvalidCurrency: {
+		TypeCode:   400210,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested currency is not supported.",
+	},
+	InvalidExchangeRateBody: {
+		TypeCode:   400211,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid exchange rate body json.",
+	},
+	InvalidExchangeRateID: {
+		TypeCode:   400212,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid exchange rate id.",
+	},
+	InvalidTaxRuleBody: {
+		TypeCode:   400213,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid tax rule body json.",
+	},
+	InvalidTaxRuleID: {
+		TypeCode:   400214,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid tax rule id.",
+	},
+	InvalidShippingMethodBody: {
+		TypeCode:   400215,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid shipping method body json.",
+	},
+	InvalidShippingMethodCode: {
+		TypeCode:   400216,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested shipping method code is invalid.",
+	},
+	InvalidOrderQuery: {
+		TypeCode:   400217,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested order list query is invalid.",
+	},
+	InvalidProductID: {
+		TypeCode:   400501,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid product id.",
+	},
+	InvalidProductBody: {
+		TypeCode:   400502,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid product body json.",
+	},
+	InvalidStockBody: {
+		TypeCode:   400503,
+		StatusCode: http.StatusBadRequest,
+		Message:    "The requested invalid stock body json.",
+	},
 	UnknownBadRequest: {
 		TypeCode:   400301,
 		StatusCode: http.StatusNotFound,
@@ -65,6 +165,23 @@ var ErrorDefinitions = map[ErrorKey]ErrorDetails{
 		StatusCode: http.StatusUnauthorized,
 		Message:    "Invalid or missing authorization token.",
 	},
+	InvalidRefreshToken: {
+		TypeCode:   401003,
+		StatusCode: http.StatusUnauthorized,
+		Message:    "The refresh token is invalid, expired or revoked.",
+	},
+	InvalidCurrentPassword: {
+		TypeCode:   401004,
+		StatusCode: http.StatusUnauthorized,
+		Message:    "The current password is incorrect.",
+	},
+
+	// ----- 402 Payment Required -----
+	PaymentDeclined: {
+		TypeCode:   402201,
+		StatusCode: http.StatusPaymentRequired,
+		Message:    "The payment was declined by the payment gateway.",
+	},
 
 	// ----- 403 Forbidden -----
 	ForbiddenAccess: {
@@ -72,6 +189,16 @@ var ErrorDefinitions = map[ErrorKey]ErrorDetails{
 		StatusCode: http.StatusForbidden,
 		Message:    "You do not have permission to access this resource.",
 	},
+	AccountDisabled: {
+		TypeCode:   403002,
+		StatusCode: http.StatusForbidden,
+		Message:    "This account is deactivated.",
+	},
+	EmailNotVerified: {
+		TypeCode:   403003,
+		StatusCode: http.StatusForbidden,
+		Message:    "Verify your email address before placing orders.",
+	},
 
 	// ----- 404 Not Found -----
 	CustomerNotFound: {
@@ -84,6 +211,51 @@ var ErrorDefinitions = map[ErrorKey]ErrorDetails{
 		StatusCode: http.StatusNotFound,
 		Message:    "The requested order was not found.",
 	},
+	PaymentNotFound: {
+		TypeCode:   404202,
+		StatusCode: http.StatusNotFound,
+		Message:    "No payment exists for the requested order.",
+	},
+	ReturnNotFound: {
+		TypeCode:   404203,
+		StatusCode: http.StatusNotFound,
+		Message:    "The requested return was not found.",
+	},
+	CartItemNotFound: {
+		TypeCode:   404204,
+		StatusCode: http.StatusNotFound,
+		Message:    "The requested product is not in the cart.",
+	},
+	CouponNotFound: {
+		TypeCode:   404205,
+		StatusCode: http.StatusNotFound,
+		Message:    "The requested discount code not found.",
+	},
+	ExchangeRateNotFound: {
+		TypeCode:   404206,
+		StatusCode: http.StatusNotFound,
+		Message:    "No exchange rate is defined for the requested currencies and date.",
+	},
+	TaxRuleNotFound: {
+		TypeCode:   404207,
+		StatusCode: http.StatusNotFound,
+		Message:    "The requested tax rule not found.",
+	},
+	ShippingMethodNotFound: {
+		TypeCode:   404208,
+		StatusCode: http.StatusNotFound,
+		Message:    "The requested shipping method was not found.",
+	},
+	ProductNotFound: {
+		TypeCode:   404501,
+		StatusCode: http.StatusNotFound,
+		Message:    "The requested product was not found.",
+	},
+	StockReservationNotFound: {
+		TypeCode:   404502,
+		StatusCode: http.StatusNotFound,
+		Message:    "No stock reservation exists for the requested order.",
+	},
 	UnknownFotFound: {
 		TypeCode:   404301,
 		StatusCode: http.StatusNotFound,
@@ -94,7 +266,82 @@ var ErrorDefinitions = map[ErrorKey]ErrorDetails{
 	OrderStatusConflict: {
 		TypeCode:   409201,
 		StatusCode: http.StatusConflict,
-		Message:    "Cannot %s order while it is in '%s' status.",
+		Message:    "Cannot change order status from '%s' to '%s'.",
+	},
+	CustomerVersionConflict: {
+		TypeCode:   409101,
+		StatusCode: http.StatusConflict,
+		Message:    "The customer was modified by another request, reload it and retry.",
+	},
+	EmailAlreadyVerified: {
+		TypeCode:   409102,
+		StatusCode: http.StatusConflict,
+		Message:    "The email address is already verified.",
+	},
+	OrderVersionConflict: {
+		TypeCode:   409202,
+		StatusCode: http.StatusConflict,
+		Message:    "The order was modified by another request, reload it and retry.",
+	},
+	PaymentNotCaptured: {
+		TypeCode:   409203,
+		StatusCode: http.StatusConflict,
+		Message:    "The order cannot be shipped before its payment is captured.",
+	},
+	PaymentStatusConflict: {
+		TypeCode:   409204,
+		StatusCode: http.StatusConflict,
+		Message:    "Cannot %s a payment while it is in '%s' status.",
+	},
+	ReturnNotAllowed: {
+		TypeCode:   409205,
+		StatusCode: http.StatusConflict,
+		Message:    "Returns can only be opened for delivered orders, current status is '%s'.",
+	},
+	ReturnStatusConflict: {
+		TypeCode:   409206,
+		StatusCode: http.StatusConflict,
+		Message:    "Cannot %s a return while it is in '%s' status.",
+	},
+	CouponAlreadyExists: {
+		TypeCode:   409207,
+		StatusCode: http.StatusConflict,
+		Message:    "A discount code with the same code already exists.",
+	},
+	OrderPriceLocked: {
+		TypeCode:   409208,
+		StatusCode: http.StatusConflict,
+		Message:    "The price of an order can only be recalculated before payment.",
+	},
+	ShippingMethodAlreadyExists: {
+		TypeCode:   409209,
+		StatusCode: http.StatusConflict,
+		Message:    "A shipping method with this code already exists.",
+	},
+	CartCheckoutInProgress: {
+		TypeCode:   409210,
+		StatusCode: http.StatusConflict,
+		Message:    "The cart is already being checked out.",
+	},
+	PaymentInProgress: {
+		TypeCode:   409211,
+		StatusCode: http.StatusConflict,
+		Message:    "The payment of this order is still being processed, retry once it completes.",
+	},
+	InsufficientStock: {
+		TypeCode:   409501,
+		StatusCode: http.StatusConflict,
+		Message:    "Insufficient stock for one or more products.",
+	},
+	StockBelowReserved: {
+		TypeCode:   409502,
+		StatusCode: http.StatusConflict,
+		Message:    "Quantity on hand cannot be lower than the reserved quantity.",
+	},
+	StockReservationClosed: {
+		TypeCode:   409503,
+		StatusCode: http.StatusConflict,
+		Message:    "The stock reservation for this order is already closed.",
 	},
 
 	// ----- 422 Unprocessable Entity -----
@@ -133,6 +380,128 @@ var ErrorDefinitions = map[ErrorKey]ErrorDetails{
 		StatusCode: http.StatusUnprocessableEntity,
 		Message:    "Invalid address information provided. City, state, and zip code are required.",
 	},
+	PasswordChangeNotAllowed: {
+		TypeCode:   422108,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "Passwords can only be changed through the password endpoints.",
+	},
+	InvalidReturnItems: {
+		TypeCode:   422202,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "Returned items must belong to the order and must not exceed the quantity still returnable.",
+	},
+	EmptyCart: {
+		TypeCode:   422203,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "The cart is empty.",
+	},
+	InvalidCouponDefinition: {
+		TypeCode:   422204,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "The discount type, value or validity period is invalid.",
+	},
+	CouponNotApplicable: {
+		TypeCode:   422205,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "The discount code is not valid for this order.",
+	},
+	CouponBelowMinBasket: {
+		TypeCode:   422206,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "The order total is below the minimum basket of the discount code.",
+	},
+	CouponNotStackable: {
+		TypeCode:   422207,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "The discount code cannot be combined with other discount codes.",
+	},
+	CouponUsageLimitReached: {
+		TypeCode:   422208,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "The discount code has reached its usage limit.",
+	},
+	CouponCustomerLimitReached: {
+		TypeCode:   422209,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "The discount code has already been used the maximum number of times by this customer.",
+	},
+	InvalidExchangeRate: {
+		TypeCode:   422210,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "The exchange rate must be a positive decimal between two different supported currencies.",
+	},
+	InvalidTaxRule: {
+		TypeCode:   422211,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "A tax rule needs a name, a state and a rate between 0 and 100.",
+	},
+	InvalidShippingMethod: {
+		TypeCode:   422212,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "The shipping method type or its costs are invalid.",
+	},
+	ShippingMethodUnavailable: {
+		TypeCode:   422213,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "The chosen shipping method is not available.",
+	},
+	InvalidShipment: {
+		TypeCode:   422214,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "A carrier and a tracking number are required to ship an order.",
+	},
+	ExchangeRateInPast: {
+		TypeCode:   422215,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "An exchange rate cannot take effect in the past.",
+	},
+	InvalidProductName: {
+		TypeCode:   422501,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "Product name is required.",
+	},
+	InvalidProductCategory: {
+		TypeCode:   422502,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "Product category is required.",
+	},
+	InvalidProductPrice: {
+		TypeCode:   422503,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "Product price must be greater than zero.",
+	},
+	ProductUnavailable: {
+		TypeCode:   422504,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "One or more products are not available for sale.",
+	},
+	InvalidStockQuantity: {
+		TypeCode:   422505,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "Stock quantity must not be negative.",
+	},
+	InvalidProductWeight: {
+		TypeCode:   422506,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "Product weight must not be negative.",
+	},
+	InvalidOrderItem: {
+		TypeCode:   422201,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "Every order item needs a product id and a quantity greater than zero.",
+	},
+
+	// ----- 429 Too Many Requests -----
+	AccountLocked: {
+		TypeCode:   429001,
+		StatusCode: http.StatusTooManyRequests,
+		Message:    "Too many failed login attempts. Try again in %d seconds.",
+	},
+	VerificationResendThrottled: {
+		TypeCode:   429002,
+		StatusCode: http.StatusTooManyRequests,
+		Message:    "A verification email was sent recently. Try again in %d seconds.",
+	},
 
 	// ----- 500 Internal Server Error -----
 	InternalServerError: {
@@ -150,6 +519,11 @@ var ErrorDefinitions = map[ErrorKey]ErrorDetails{
 		StatusCode: http.StatusInternalServerError,
 		Message:    "An error occurred in the order service.",
 	},
+	ProductServiceError: {
+		TypeCode:   500501,
+		StatusCode: http.StatusInternalServerError,
+		Message:    "An error occurred in the product service.",
+	},
 	UnknownServiceError: {
 		TypeCode:   500301,
 		StatusCode: http.StatusInternalServerError,
@@ -165,42 +539,121 @@ var ErrorDefinitions = map[ErrorKey]ErrorDetails{
 // Hata anahtarları (constants)
 const (
 	// Bad Request
-	InvalidCustomerID   ErrorKey = "InvalidCustomerID"
-	InvalidCustomerBody ErrorKey = "InvalidCustomerBody"
-	EmptyCustomerID     ErrorKey = "EmptyCustomerID"
-	EmptyOrderID        ErrorKey = "EmptyOrderID"
-	EmptyRole           ErrorKey = "EmptyROle"
-	InvalidOrderID      ErrorKey = "InvalidOrderID"
-	InvalidOrderBody    ErrorKey = "InvalidOrderBody"
-	UnknownBadRequest   ErrorKey = "UnknownBadRequest"
+	InvalidCursor             ErrorKey = "InvalidCursor"
+	InvalidCustomerID         ErrorKey = "InvalidCustomerID"
+	InvalidCustomerBody       ErrorKey = "InvalidCustomerBody"
+	EmptyCustomerID           ErrorKey = "EmptyCustomerID"
+	EmptyOrderID              ErrorKey = "EmptyOrderID"
+	EmptyRole                 ErrorKey = "EmptyROle"
+	InvalidPasswordResetToken ErrorKey = "InvalidPasswordResetToken"
+	InvalidVerificationToken  ErrorKey = "InvalidVerificationToken"
+	InvalidOrderID            ErrorKey = "InvalidOrderID"
+	InvalidOrderBody          ErrorKey = "InvalidOrderBody"
+	InvalidPaymentBody        ErrorKey = "InvalidPaymentBody"
+	InvalidReturnBody         ErrorKey = "InvalidReturnBody"
+	InvalidReturnID           ErrorKey = "InvalidReturnID"
+	InvalidCartBody           ErrorKey = "InvalidCartBody"
+	InvalidCouponBody         ErrorKey = "InvalidCouponBody"
+	InvalidCouponCode         ErrorKey = "InvalidCouponCode"
+	InvalidCurrency           ErrorKey = "InvalidCurrency"
+	InvalidExchangeRateBody   ErrorKey = "InvalidExchangeRateBody"
+	InvalidExchangeRateID     ErrorKey = "InvalidExchangeRateID"
+	InvalidTaxRuleBody        ErrorKey = "InvalidTaxRuleBody"
+	InvalidTaxRuleID          ErrorKey = "InvalidTaxRuleID"
+	InvalidShippingMethodBody ErrorKey = "InvalidShippingMethodBody"
+	InvalidShippingMethodCode ErrorKey = "InvalidShippingMethodCode"
+	InvalidOrderQuery         ErrorKey = "InvalidOrderQuery"
+	InvalidProductID          ErrorKey = "InvalidProductID"
+	InvalidProductBody        ErrorKey = "InvalidProductBody"
+	InvalidStockBody          ErrorKey = "InvalidStockBody"
+	UnknownBadRequest         ErrorKey = "UnknownBadRequest"
 
 	// Unauthorized
-	InvalidCredentials ErrorKey = "InvalidCredentials"
-	MissingAuthToken   ErrorKey = "MissingAuthToken"
+	InvalidCredentials     ErrorKey = "InvalidCredentials"
+	MissingAuthToken       ErrorKey = "MissingAuthToken"
+	InvalidRefreshToken    ErrorKey = "InvalidRefreshToken"
+	InvalidCurrentPassword ErrorKey = "InvalidCurrentPassword"
+
+	// Payment Required
+	PaymentDeclined ErrorKey = "PaymentDeclined"
 
 	// Forbidden
-	ForbiddenAccess ErrorKey = "ForbiddenAccess"
+	ForbiddenAccess  ErrorKey = "ForbiddenAccess"
+	AccountDisabled  ErrorKey = "AccountDisabled"
+	EmailNotVerified ErrorKey = "EmailNotVerified"
 
 	// Not Found
-	CustomerNotFound ErrorKey = "CustomerNotFound"
-	OrderNotFound    ErrorKey = "OrderNotFound"
-	UnknownFotFound  ErrorKey = "UnknownFotFound"
+	CustomerNotFound         ErrorKey = "CustomerNotFound"
+	OrderNotFound            ErrorKey = "OrderNotFound"
+	PaymentNotFound          ErrorKey = "PaymentNotFound"
+	ReturnNotFound           ErrorKey = "ReturnNotFound"
+	CartItemNotFound         ErrorKey = "CartItemNotFound"
+	CouponNotFound           ErrorKey = "CouponNotFound"
+	ExchangeRateNotFound     ErrorKey = "ExchangeRateNotFound"
+	TaxRuleNotFound          ErrorKey = "TaxRuleNotFound"
+	ShippingMethodNotFound   ErrorKey = "ShippingMethodNotFound"
+	ProductNotFound          ErrorKey = "ProductNotFound"
+	StockReservationNotFound ErrorKey = "StockReservationNotFound"
+	UnknownFotFound          ErrorKey = "UnknownFotFound"
 
 	// Conflict
-	OrderStatusConflict ErrorKey = "OrderStatusConflict"
+	OrderStatusConflict         ErrorKey = "OrderStatusConflict"
+	CustomerVersionConflict     ErrorKey = "CustomerVersionConflict"
+	EmailAlreadyVerified        ErrorKey = "EmailAlreadyVerified"
+	OrderVersionConflict        ErrorKey = "OrderVersionConflict"
+	PaymentNotCaptured          ErrorKey = "PaymentNotCaptured"
+	PaymentStatusConflict       ErrorKey = "PaymentStatusConflict"
+	ReturnNotAllowed            ErrorKey = "ReturnNotAllowed"
+	ReturnStatusConflict        ErrorKey = "ReturnStatusConflict"
+	CouponAlreadyExists         ErrorKey = "CouponAlreadyExists"
+	OrderPriceLocked            ErrorKey = "OrderPriceLocked"
+	ShippingMethodAlreadyExists ErrorKey = "ShippingMethodAlreadyExists"
+	CartCheckoutInProgress      ErrorKey = "CartCheckoutInProgress"
+	PaymentInProgress           ErrorKey = "PaymentInProgress"
+	InsufficientStock           ErrorKey = "InsufficientStock"
+	StockBelowReserved          ErrorKey = "StockBelowReserved"
+	StockReservationClosed      ErrorKey = "StockReservationClosed"
 
 	// Unprocessable Entity
-	InvalidDataFormat     ErrorKey = "InvalidDataFormat"
-	InvalidEmailFormat    ErrorKey = "InvalidEmailFormat"
-	InvalidFirstName      ErrorKey = "InvalidFirstName"
-	InvalidLastName       ErrorKey = "InvalidLastName"
-	InvalidPasswordFormat ErrorKey = "InvalidPasswordFormat"
-	InvalidPhoneFormat    ErrorKey = "InvalidPhoneFormat"
-	InvalidAddressFormat  ErrorKey = "InvalidAddressFormat"
+	InvalidDataFormat          ErrorKey = "InvalidDataFormat"
+	InvalidEmailFormat         ErrorKey = "InvalidEmailFormat"
+	InvalidFirstName           ErrorKey = "InvalidFirstName"
+	InvalidLastName            ErrorKey = "InvalidLastName"
+	InvalidPasswordFormat      ErrorKey = "InvalidPasswordFormat"
+	InvalidPhoneFormat         ErrorKey = "InvalidPhoneFormat"
+	InvalidAddressFormat       ErrorKey = "InvalidAddressFormat"
+	PasswordChangeNotAllowed   ErrorKey = "PasswordChangeNotAllowed"
+	InvalidReturnItems         ErrorKey = "InvalidReturnItems"
+	EmptyCart                  ErrorKey = "EmptyCart"
+	InvalidCouponDefinition    ErrorKey = "InvalidCouponDefinition"
+	CouponNotApplicable        ErrorKey = "CouponNotApplicable"
+	CouponBelowMinBasket       ErrorKey = "CouponBelowMinBasket"
+	CouponNotStackable         ErrorKey = "CouponNotStackable"
+	CouponUsageLimitReached    ErrorKey = "CouponUsageLimitReached"
+	CouponCustomerLimitReached ErrorKey = "CouponCustomerLimitReached"
+	InvalidExchangeRate        ErrorKey = "InvalidExchangeRate"
+	InvalidTaxRule             ErrorKey = "InvalidTaxRule"
+	InvalidShippingMethod      ErrorKey = "InvalidShippingMethod"
+	ShippingMethodUnavailable  ErrorKey = "ShippingMethodUnavailable"
+	InvalidShipment            ErrorKey = "InvalidShipment"
+	ExchangeRateInPast         ErrorKey = "ExchangeRateInPast"
+	InvalidProductName         ErrorKey = "InvalidProductName"
+	InvalidProductCategory     ErrorKey = "InvalidProductCategory"
+	InvalidProductPrice        ErrorKey = "InvalidProductPrice"
+	ProductUnavailable         ErrorKey = "ProductUnavailable"
+	InvalidStockQuantity       ErrorKey = "InvalidStockQuantity"
+	InvalidProductWeight       ErrorKey = "InvalidProductWeight"
+	InvalidOrderItem           ErrorKey = "InvalidOrderItem"
+
+	// Too Many Requests
+	AccountLocked               ErrorKey = "AccountLocked"
+	VerificationResendThrottled ErrorKey = "VerificationResendThrottled"
+
 	// Internal Server Error
 	InternalServerError  ErrorKey = "InternalServerError"
 	CustomerServiceError ErrorKey = "CustomerServiceError"
 	OrderServiceError    ErrorKey = "OrderServiceError"
+	ProductServiceError  ErrorKey = "ProductServiceError"
 	UnknownServiceError  ErrorKey = "UnknownServiceError"
 	FrameworkError       ErrorKey = "FrameworkError"
 )
@@ -56,6 +56,10 @@ func NewUnauthorized(key ErrorKey) *AppError {
 	return newAppError(key, nil)
 }
 
+func NewPaymentRequired(key ErrorKey, err error) *AppError {
+	return newAppError(key, err)
+}
+
 func NewForbidden(key ErrorKey) *AppError {
 	return newAppError(key, nil)
 }
@@ -68,6 +72,10 @@ func NewUnprocessableEntity(key ErrorKey, err error) *AppError {
 	return newAppError(key, err)
 }
 
+func NewTooManyRequests(key ErrorKey, args ...interface{}) *AppError {
+	return newAppError(key, nil, args...)
+}
+
 func NewInternal(key ErrorKey, err error) *AppError {
 	log.Errorf("Internal error occurred with key %s: %v", key, err)
 	return newAppError(key, err)
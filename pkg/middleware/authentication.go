@@ -1,22 +1,23 @@
 package middleware
 
 import (
-	"context"
-	"fmt"
 	"strings"
 	"tesodev-korpes/pkg/auth"
 	"tesodev-korpes/pkg/customError"
-	"time"
 
 	"github.com/labstack/echo/v4"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type SkipperFunc func(c echo.Context) bool
 
-func Authentication(mongoClient *mongo.Client, skipper SkipperFunc) echo.MiddlewareFunc {
+// Authentication verifies the bearer token, rejects tokens on the denylist and
+// tokens of deleted or deactivated accounts, and puts the caller's id, role,
+// membership, session and email verification status, plus the token's jti
+// and expiry, on the context. Role and membership come from the token;
+// account status comes from statuses, which a service that changes account
+// status invalidates for its own requests. Service tokens belong to no
+// account and skip the status check.
+func Authentication(denylist *auth.Denylist, statuses *auth.UserStatusCache, skipper SkipperFunc) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			if skipper != nil && skipper(c) {
@@ -36,50 +37,33 @@ func Authentication(mongoClient *mongo.Client, skipper SkipperFunc) echo.Middlew
 				return customError.NewUnauthorized(customError.MissingAuthToken)
 			}
 
-			role, err := ExistUser(mongoClient, claims.ID)
+			revoked, err := denylist.IsRevoked(c.Request().Context(), claims.RegisteredClaims.ID)
 			if err != nil {
+				return customError.NewInternal(customError.CustomerServiceError, err)
+			}
+			if revoked {
 				return customError.NewUnauthorized(customError.MissingAuthToken)
 			}
-			fmt.Println("fjksdjkfjksdfkjsdfjkjndsfjfdsjkjkfds", claims.ID, role.Membership, role.SystemRole)
-			c.Set("userId", claims.ID)
-			c.Set("userRole", role.SystemRole)
-			c.Set("userMembership", role.Membership)
-			return next(c)
-		}
-	}
-}
-
-type RoleInfo struct {
-	SystemRole string `bson:"role"`
-	Membership string `bson:"membership"`
-}
 
-func ExistUser(mongoClient *mongo.Client, userID string) (*RoleInfo, error) {
-	col := mongoClient.Database("tesodev").Collection("customer")
-
-	// 1. Decode edilecek struct, veritabanından gelecek olan yapıyla eşleşmeli.
-	// Projeksiyon kullandığımız için bize sadece { "role": { ... } } yapısında bir döküman gelecek.
-	var result struct {
-		Role RoleInfo `bson:"role"`
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	// 2. Sadece "role" alanını çekmek için projeksiyon (projection) tanımla.
-	// Bu, gereksiz veri transferini önler ve performansı artırır.
-	opts := options.FindOne().SetProjection(bson.M{"role": 1})
+			var status auth.UserStatus
+			if claims.Role != auth.ServiceRole {
+				status, err = statuses.Get(c.Request().Context(), claims.ID)
+				if err != nil {
+					return customError.NewInternal(customError.CustomerServiceError, err)
+				}
+				if !status.Exists || !status.Active {
+					return customError.NewUnauthorized(customError.MissingAuthToken)
+				}
+			}
 
-	// 3. Sorguya projeksiyonu ekleyerek FindOne işlemini yap ve sonucu "result" struct'ına decode et.
-	err := col.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&result)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, customError.NewNotFound(customError.CustomerNotFound)
+			c.Set("userId", claims.ID)
+			c.Set("userRole", claims.Role)
+			c.Set("userMembership", claims.Membership)
+			c.Set("sessionId", claims.SessionID)
+			c.Set("emailVerified", status.EmailVerified)
+			c.Set("tokenId", claims.RegisteredClaims.ID)
+			c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+			return next(c)
 		}
-		return nil, err
 	}
-
-	// 4. Dönen sonucun içindeki gömülü Role objesini (artık RoleInfo tipinde) döndür.
-	fmt.Printf("Role found: Membership=%s, SystemRole=%s\n", result.Role.Membership, result.Role.SystemRole)
-	return &result.Role, nil
 }
@@ -1,43 +1,36 @@
 package middleware
 
 import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"tesodev-korpes/pkg/customError"
 	"tesodev-korpes/shared/config"
 
 	"github.com/labstack/echo/v4"
 )
 
-// func AuthorizationMiddleware(allowedRoles []string) echo.MiddlewareFunc {
-// 	return func(next echo.HandlerFunc) echo.HandlerFunc {
-// 		return func(c echo.Context) error {
-// 			userRole, ok := c.Get("userRole").(string)
-// 			fmt.Println(userRole)
-// 			if !ok || userRole == "" {
-// 				return customError.NewBadRequest(customError.EmptyRole)
-// 			}
-
-// 			for _, allowed := range allowedRoles {
-// 				if strings.EqualFold(userRole, allowed) {
-// 					return next(c)
-// 				}
-// 			}
-
-// 			return customError.NewForbidden(customError.ForbiddenAccess)
-// 		}
-// 	}
-// }
-
+// AuthorizationMiddleware checks the caller's role against the policy of the
+// matched route, looked up in the current RBAC policy as "METHOD /path". A
+// policy with no roles makes the route public. Routes without a policy are
+// denied; CheckRoutePolicies reports them at boot so that never happens by
+// accident. It must run after Authentication.
 func AuthorizationMiddleware(cfg *config.Config) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			path := c.Path()
-
-			allowedRoles, found := cfg.EndpointRoles[path]
+			allowedRoles, found := cfg.Policy().RouteRoles(policyKey(c.Request().Method, c.Path()))
 			if !found {
+				if !isRoute(c) {
+					return echo.ErrNotFound
+				}
+				return customError.NewForbidden(customError.ForbiddenAccess)
+			}
+			if len(allowedRoles) == 0 {
 				return next(c)
 			}
-			userRole := c.Get("userRole")
 
+			userRole, _ := c.Get("userRole").(string)
 			for _, role := range allowedRoles {
 				if role == userRole {
 					return next(c)
@@ -48,3 +41,101 @@ func AuthorizationMiddleware(cfg *config.Config) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// PublicRoute is the SkipperFunc that lets Authentication pass the routes the
+// current RBAC policy marks public, so whether a route needs a token is
+// decided by the policy file alone.
+func PublicRoute(cfg *config.Config) SkipperFunc {
+	return func(c echo.Context) bool {
+		roles, found := cfg.Policy().RouteRoles(policyKey(c.Request().Method, c.Path()))
+		return found && len(roles) == 0
+	}
+}
+
+// RoutePolicy is one line of the effective permission table. No roles means
+// the route is public.
+type RoutePolicy struct {
+	Route string
+	Roles []string
+}
+
+// CheckRoutePolicies matches every route registered on e with its policy. It
+// returns the permission table sorted by path, and an error naming the routes
+// without a policy.
+func CheckRoutePolicies(e *echo.Echo, policy *config.Policy) ([]RoutePolicy, error) {
+	var table []RoutePolicy
+	var missing []string
+
+	for _, route := range e.Routes() {
+		if route.Method == echo.RouteNotFound {
+			continue
+		}
+
+		key := policyKey(route.Method, route.Path)
+		roles, found := policy.RouteRoles(key)
+		if !found {
+			missing = append(missing, key)
+			continue
+		}
+		table = append(table, RoutePolicy{Route: key, Roles: roles})
+	}
+
+	sort.Slice(table, func(i, j int) bool {
+		pi, pj := routePath(table[i].Route), routePath(table[j].Route)
+		if pi != pj {
+			return pi < pj
+		}
+		return table[i].Route < table[j].Route
+	})
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return table, fmt.Errorf("routes without an authorization policy: %s", strings.Join(missing, ", "))
+	}
+	return table, nil
+}
+
+// MustCheckRoutePolicies logs the permission table of a service and panics if
+// one of its routes has no policy. The same check then guards every policy
+// reload, so a reloaded file cannot drop a route of a running service.
+func MustCheckRoutePolicies(e *echo.Echo, cfg *config.Config, service string) {
+	table, err := CheckRoutePolicies(e, cfg.Policy())
+	for _, policy := range table {
+		access := "public"
+		if len(policy.Roles) > 0 {
+			access = strings.Join(policy.Roles, ", ")
+		}
+		log.Printf("[%s] %-45s %s", service, policy.Route, access)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	err = cfg.AddPolicyCheck(func(policy *config.Policy) error {
+		if _, err := CheckRoutePolicies(e, policy); err != nil {
+			return fmt.Errorf("%s service: %w", service, err)
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func policyKey(method, path string) string {
+	return method + " " + path
+}
+
+func routePath(key string) string {
+	return key[strings.IndexByte(key, ' ')+1:]
+}
+
+// isRoute tells a matched route from Echo's catch-all routes for unknown
+// paths, which must still answer 404.
+func isRoute(c echo.Context) bool {
+	for _, route := range c.Echo().Routes() {
+		if route.Path == c.Path() && route.Method == c.Request().Method {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"tesodev-korpes/shared/config"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestPublicRoute(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rbac.yaml")
+	write := func(policy string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const roles = "roles:\n  user:\n    permissions: [product.read]\n"
+
+	var cfg config.Config
+	write(roles + "routes:\n  GET /product/:id: public\n  POST /product: product.read\n")
+	if err := cfg.LoadPolicyFile(path); err != nil {
+		t.Fatal(err)
+	}
+	skip := PublicRoute(&cfg)
+
+	route := func(method, path string) echo.Context {
+		c := echo.New().NewContext(httptest.NewRequest(method, "/", nil), httptest.NewRecorder())
+		c.SetPath(path)
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   bool
+	}{
+		{"public route", http.MethodGet, "/product/:id", true},
+		{"other method", http.MethodDelete, "/product/:id", false},
+		{"protected route", http.MethodPost, "/product", false},
+		{"route not in the policy", http.MethodGet, "/customer/list", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := skip(route(tt.method, tt.path)); got != tt.want {
+				t.Fatalf("PublicRoute(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("follows a reloaded policy", func(t *testing.T) {
+		write(roles + "routes:\n  GET /product/:id: product.read\n  POST /product: public\n")
+		if err := cfg.ReloadPolicy(); err != nil {
+			t.Fatal(err)
+		}
+		if skip(route(http.MethodGet, "/product/:id")) || !skip(route(http.MethodPost, "/product")) {
+			t.Fatalf("PublicRoute still answers from the old policy")
+		}
+	})
+}
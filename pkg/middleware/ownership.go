@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"tesodev-korpes/pkg/customError"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// StaffRoles may act on resources of any customer.
+var StaffRoles = []string{"admin", "manager"}
+
+// OwnerFunc returns the id of the customer owning the resource with the given
+// id.
+type OwnerFunc func(ctx context.Context, id string) (string, error)
+
+// SelfOwned is the OwnerFunc of customer records: a customer owns itself.
+func SelfOwned(_ context.Context, id string) (string, error) {
+	return id, nil
+}
+
+// IsStaff reports whether the authenticated caller has one of StaffRoles.
+func IsStaff(c echo.Context) bool {
+	role, _ := c.Get("userRole").(string)
+	for _, staff := range StaffRoles {
+		if role == staff {
+			return true
+		}
+	}
+	return false
+}
+
+// Ownership limits the routes having the given path parameter to the owner of
+// the resource it names; staff pass through. Routes without the parameter are
+// not affected, so it can be applied to a whole group. It runs after
+// Authentication. Unknown resources are left to the handler to report.
+func Ownership(param string, owner OwnerFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Param(param)
+			if id == "" || IsStaff(c) {
+				return next(c)
+			}
+
+			userID, _ := c.Get("userId").(string)
+			if userID == "" {
+				return customError.NewUnauthorized(customError.MissingAuthToken)
+			}
+
+			ownerID, err := owner(c.Request().Context(), id)
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return next(c)
+			}
+			if err != nil {
+				return customError.NewInternal(customError.InternalServerError, err)
+			}
+			if ownerID != userID {
+				return customError.NewForbidden(customError.ForbiddenAccess)
+			}
+			return next(c)
+		}
+	}
+}
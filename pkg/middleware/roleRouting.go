@@ -10,7 +10,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func RoleRouting(cfg config.Config, handlers map[string]echo.HandlerFunc) echo.MiddlewareFunc {
+func RoleRouting(cfg *config.Config, handlers map[string]echo.HandlerFunc) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			userRole, ok := c.Get("userMembership").(string)
@@ -18,7 +18,7 @@ func RoleRouting(cfg config.Config, handlers map[string]echo.HandlerFunc) echo.M
 				return customError.NewBadRequest(customError.EmptyRole)
 			}
 
-			internalPathTemplate, ok := cfg.RoleMapping[userRole]
+			internalPathTemplate, ok := cfg.Policy().MembershipRoute(userRole)
 			if !ok {
 				return customError.NewForbidden(customError.ForbiddenAccess)
 			}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"tesodev-korpes/pkg/auth"
+	"tesodev-korpes/pkg/customError"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireVerifiedEmail refuses callers whose email address is not verified
+// yet. It runs after Authentication, which reads the status from the status
+// cache; when that says not verified, the status is looked up again so a
+// customer who has just verified is not refused until the cache expires.
+func RequireVerifiedEmail(statuses *auth.UserStatusCache) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if verified, _ := c.Get("emailVerified").(bool); verified {
+				return next(c)
+			}
+
+			userID, _ := c.Get("userId").(string)
+			if userID == "" {
+				return customError.NewForbidden(customError.EmailNotVerified)
+			}
+			status, err := statuses.Refresh(c.Request().Context(), userID)
+			if err != nil {
+				return customError.NewInternal(customError.CustomerServiceError, err)
+			}
+			if !status.EmailVerified {
+				return customError.NewForbidden(customError.EmailNotVerified)
+			}
+
+			c.Set("emailVerified", true)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"tesodev-korpes/pkg/auth"
+	"tesodev-korpes/pkg/customError"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	customer := func(pending bool) bson.D {
+		return mtest.CreateCursorResponse(0, "tesodev.customer", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "customer-1"},
+			{Key: "email_verification_pending", Value: pending},
+		})
+	}
+
+	tests := []struct {
+		name        string
+		verified    interface{}
+		userID      string
+		response    bson.D
+		wantNext    bool
+		wantLookups int
+	}{
+		{"verified", true, "customer-1", nil, true, 0},
+		{"verified since the status was cached", false, "customer-1", customer(false), true, 1},
+		{"still not verified", false, "customer-1", customer(true), false, 1},
+		{"not authenticated", nil, "", nil, false, 0},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			if tt.response != nil {
+				mt.AddMockResponses(tt.response)
+			}
+			statuses := auth.NewUserStatusCache(mt.Coll, time.Minute)
+
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/order", nil), httptest.NewRecorder())
+			if tt.verified != nil {
+				c.Set("emailVerified", tt.verified)
+			}
+			if tt.userID != "" {
+				c.Set("userId", tt.userID)
+			}
+
+			called := false
+			err := RequireVerifiedEmail(statuses)(func(echo.Context) error {
+				called = true
+				return nil
+			})(c)
+
+			if lookups := len(mt.GetAllStartedEvents()); lookups != tt.wantLookups {
+				mt.Fatalf("%d lookups, want %d", lookups, tt.wantLookups)
+			}
+			if called != tt.wantNext {
+				mt.Fatalf("next called = %v, want %v", called, tt.wantNext)
+			}
+			if tt.wantNext {
+				if err != nil {
+					mt.Fatalf("error = %v, want nil", err)
+				}
+				return
+			}
+			appErr, ok := err.(*customError.AppError)
+			if !ok || appErr.Code != customError.ErrorDefinitions[customError.EmailNotVerified].TypeCode {
+				mt.Fatalf("error = %v, want EmailNotVerified", err)
+			}
+		})
+	}
+}
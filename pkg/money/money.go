@@ -0,0 +1,262 @@
+// Package money represents amounts of money exactly, as an integer number of
+// minor units (kuruş, cents) together with an ISO 4217 currency code.
+//
+// Rounding rules: converting a decimal amount to money and taking a share of
+// an amount (percentages, prorations) round half away from zero to the minor
+// unit of the currency. Every discount is rounded on its own, before the next
+// one is applied.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// DefaultCurrency is the currency of catalog prices and of amounts stored
+// before amounts carried a currency.
+var DefaultCurrency = "TRY"
+
+// exponents lists the number of minor-unit digits of the supported
+// currencies.
+var exponents = map[string]int{
+	"TRY": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CHF": 2,
+	"JPY": 0,
+	"KWD": 3,
+}
+
+var ErrCurrencyMismatch = errors.New("money: currency mismatch")
+
+type Money struct {
+	Amount   int64  `bson:"amount" json:"amount"`
+	Currency string `bson:"currency" json:"currency"`
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// FromMajor converts a decimal amount in major units, such as a catalog price
+// of 12.5, to money.
+func FromMajor(value float64, currency string) Money {
+	scale := math.Pow10(Exponent(currency))
+	return Money{Amount: int64(math.Round(value * scale)), Currency: currency}
+}
+
+func IsValidCurrency(currency string) bool {
+	_, ok := exponents[currency]
+	return ok
+}
+
+// Exponent returns the number of minor-unit digits of a currency. Unknown
+// currencies are treated as having two.
+func Exponent(currency string) int {
+	if exp, ok := exponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+func Zero(currency string) Money {
+	return Money{Currency: currency}
+}
+
+// IsZero reports whether m is the zero value, which lets omitempty drop unset
+// amounts.
+func (m Money) IsZero() bool {
+	return m.Amount == 0 && m.Currency == ""
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add and Sub panic when the currencies differ; mixing currencies without a
+// conversion is a programming error. A zero value takes the other currency.
+func (m Money) Add(o Money) Money {
+	return Money{Amount: m.Amount + o.Amount, Currency: m.sameCurrency(o)}
+}
+
+func (m Money) Sub(o Money) Money {
+	return Money{Amount: m.Amount - o.Amount, Currency: m.sameCurrency(o)}
+}
+
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+// Percent returns the given percentage of m, rounded half away from zero.
+// Percentages are taken with two decimals, so 12.345% is 12.35%.
+func (m Money) Percent(percent float64) Money {
+	basisPoints := int64(math.Round(percent * 100))
+	return m.MulDiv(basisPoints, 10000)
+}
+
+// MulDiv returns m*num/den rounded half away from zero. It is used to split an
+// amount in proportion to another.
+func (m Money) MulDiv(num, den int64) Money {
+	if den == 0 {
+		return Money{Currency: m.Currency}
+	}
+	return Money{Amount: divRound(m.Amount*num, den), Currency: m.Currency}
+}
+
+// Convert converts m to another currency at the given rate, the price of one
+// unit of m's currency in the other currency. The result is rounded half away
+// from zero to the minor unit of the target currency.
+func (m Money) Convert(rate *big.Rat, currency string) Money {
+	v := new(big.Rat).SetInt64(m.Amount)
+	v.Mul(v, rate)
+	v.Mul(v, new(big.Rat).SetFrac(pow10(Exponent(currency)), pow10(Exponent(m.Currency))))
+
+	num, den := v.Num(), v.Denom()
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Money{Amount: q.Int64(), Currency: currency}
+}
+
+// Min returns the smaller of m and o.
+func (m Money) Min(o Money) Money {
+	m.sameCurrency(o)
+	if o.Amount < m.Amount {
+		return o
+	}
+	return m
+}
+
+// ClampZero returns m, or zero if m is negative.
+func (m Money) ClampZero() Money {
+	if m.Amount < 0 {
+		return Money{Currency: m.Currency}
+	}
+	return m
+}
+
+func (m Money) LessThan(o Money) bool {
+	m.sameCurrency(o)
+	return m.Amount < o.Amount
+}
+
+// Major returns the amount in major units. It is for display and for systems
+// that take decimal amounts; never calculate with it.
+func (m Money) Major() float64 {
+	return float64(m.Amount) / math.Pow10(Exponent(m.Currency))
+}
+
+func (m Money) String() string {
+	exp := Exponent(m.Currency)
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	if exp == 0 {
+		return fmt.Sprintf("%s%d %s", sign, amount, m.Currency)
+	}
+	scale := int64(math.Pow10(exp))
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/scale, exp, amount%scale, m.Currency)
+}
+
+func (m Money) sameCurrency(o Money) string {
+	switch {
+	case m.Currency == o.Currency:
+		return m.Currency
+	case m.IsZero():
+		return o.Currency
+	case o.IsZero():
+		return m.Currency
+	}
+	panic(fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency))
+}
+
+// UnmarshalBSONValue reads the {amount, currency} document written by this
+// package, and also a plain number in major units as stored by older
+// documents, which is taken to be in DefaultCurrency. This keeps existing
+// orders readable until they are migrated.
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bsoncore.Value{Type: t, Data: data}
+
+	switch t {
+	case bsontype.EmbeddedDocument:
+		type plain Money
+		var p plain
+		if err := bson.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		*m = Money(p)
+		if m.Currency == "" {
+			m.Currency = DefaultCurrency
+		}
+		return nil
+	case bsontype.Double:
+		*m = FromMajor(value.Double(), DefaultCurrency)
+	case bsontype.Int32:
+		*m = FromMajor(float64(value.Int32()), DefaultCurrency)
+	case bsontype.Int64:
+		*m = FromMajor(float64(value.Int64()), DefaultCurrency)
+	case bsontype.Null, bsontype.Undefined:
+		*m = Money{}
+	default:
+		return fmt.Errorf("money: cannot decode BSON %s", t)
+	}
+	return nil
+}
+
+// ParseCurrency normalizes a currency code given by a client and reports
+// whether it is supported.
+func ParseCurrency(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return code, IsValidCurrency(code)
+}
+
+// ParseRate parses an exchange rate written as a positive decimal, such as
+// "32.4517".
+func ParseRate(rate string) (*big.Rat, bool) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok || r.Sign() <= 0 {
+		return nil, false
+	}
+	return r, true
+}
+
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+func divRound(num, den int64) int64 {
+	if den < 0 {
+		num, den = -num, -den
+	}
+	q, r := num/den, num%den
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= den {
+		if num < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
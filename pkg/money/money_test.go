@@ -0,0 +1,234 @@
+package money
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFromMajor(t *testing.T) {
+	tests := []struct {
+		value    float64
+		currency string
+		want     Money
+	}{
+		{12.5, "TRY", New(1250, "TRY")},
+		{0.1 + 0.2, "TRY", New(30, "TRY")},
+		{19.999, "USD", New(2000, "USD")},
+		{1234, "JPY", New(1234, "JPY")},
+		{1.2345, "KWD", New(1235, "KWD")},
+		{-3.5, "EUR", New(-350, "EUR")},
+	}
+
+	for _, tt := range tests {
+		if got := FromMajor(tt.value, tt.currency); got != tt.want {
+			t.Errorf("FromMajor(%v, %q) = %s, want %s", tt.value, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMulDiv(t *testing.T) {
+	tests := []struct {
+		name     string
+		m        Money
+		num, den int64
+		want     Money
+	}{
+		{"exact", New(1000, "TRY"), 1, 4, New(250, "TRY")},
+		{"rounds down below half", New(1000, "TRY"), 1, 3, New(333, "TRY")},
+		{"rounds up above half", New(2000, "TRY"), 1, 3, New(667, "TRY")},
+		{"half away from zero", New(5, "TRY"), 1, 2, New(3, "TRY")},
+		{"negative half away from zero", New(-5, "TRY"), 1, 2, New(-3, "TRY")},
+		{"negative denominator", New(5, "TRY"), 1, -2, New(-3, "TRY")},
+		{"zero denominator", New(1000, "TRY"), 1, 0, New(0, "TRY")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.MulDiv(tt.num, tt.den); got != tt.want {
+				t.Fatalf("%s.MulDiv(%d, %d) = %s, want %s", tt.m, tt.num, tt.den, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		m       Money
+		percent float64
+		want    Money
+	}{
+		{New(10000, "TRY"), 18, New(1800, "TRY")},
+		{New(999, "TRY"), 12.5, New(125, "TRY")},
+		{New(1000, "TRY"), 12.345, New(124, "TRY")},
+		{New(1000, "TRY"), 0, New(0, "TRY")},
+		{New(1000, "TRY"), 100, New(1000, "TRY")},
+		{New(333, "JPY"), 10, New(33, "JPY")},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.Percent(tt.percent); got != tt.want {
+			t.Errorf("%s.Percent(%v) = %s, want %s", tt.m, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		wantAdd Money
+		wantSub Money
+	}{
+		{"same currency", New(1000, "TRY"), New(250, "TRY"), New(1250, "TRY"), New(750, "TRY")},
+		{"zero value takes the other currency", Money{}, New(250, "USD"), New(250, "USD"), New(-250, "USD")},
+		{"zero value on the right", New(250, "USD"), Money{}, New(250, "USD"), New(250, "USD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Add(tt.b); got != tt.wantAdd {
+				t.Errorf("Add = %s, want %s", got, tt.wantAdd)
+			}
+			if got := tt.a.Sub(tt.b); got != tt.wantSub {
+				t.Errorf("Sub = %s, want %s", got, tt.wantSub)
+			}
+		})
+	}
+}
+
+func TestAddCurrencyMismatch(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrCurrencyMismatch) {
+			t.Fatalf("recover() = %v, want ErrCurrencyMismatch", err)
+		}
+	}()
+	New(100, "TRY").Add(New(100, "USD"))
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{New(1250, "TRY"), "12.50 TRY"},
+		{New(5, "USD"), "0.05 USD"},
+		{New(-1250, "EUR"), "-12.50 EUR"},
+		{New(1234, "JPY"), "1234 JPY"},
+		{New(1005, "KWD"), "1.005 KWD"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalBSONValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  Money
+	}{
+		{"document", bson.M{"amount": int64(1250), "currency": "USD"}, New(1250, "USD")},
+		{"document without currency", bson.M{"amount": int64(1250)}, New(1250, DefaultCurrency)},
+		{"legacy double", 12.5, New(1250, DefaultCurrency)},
+		{"legacy int32", int32(12), New(1200, DefaultCurrency)},
+		{"legacy int64", int64(12), New(1200, DefaultCurrency)},
+		{"null", nil, Money{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := bson.Marshal(bson.M{"price": tt.value})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc struct {
+				Price Money `bson:"price"`
+			}
+			if err := bson.Unmarshal(data, &doc); err != nil {
+				t.Fatalf("Unmarshal error = %v", err)
+			}
+			if doc.Price != tt.want {
+				t.Fatalf("Unmarshal = %s, want %s", doc.Price, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		m        Money
+		rate     string
+		currency string
+		want     Money
+	}{
+		{"same exponent", New(10000, "TRY"), "0.031", "USD", New(310, "USD")},
+		{"rounded to the nearest minor unit", New(1, "USD"), "32.45", "TRY", New(32, "TRY")},
+		{"half rounds up", New(1, "USD"), "32.5", "TRY", New(33, "TRY")},
+		{"negative half rounds down", New(-1, "USD"), "32.5", "TRY", New(-33, "TRY")},
+		{"to a currency without minor units", New(1250, "USD"), "151.37", "JPY", New(1892, "JPY")},
+		{"from a currency without minor units", New(1000, "JPY"), "0.0066", "USD", New(660, "USD")},
+		{"to a currency with three digits", New(1000, "USD"), "0.3071", "KWD", New(3071, "KWD")},
+		{"exact decimal rate", New(333, "EUR"), "1.1", "USD", New(366, "USD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, ok := ParseRate(tt.rate)
+			if !ok {
+				t.Fatalf("ParseRate(%q) failed", tt.rate)
+			}
+			if got := tt.m.Convert(rate, tt.currency); got != tt.want {
+				t.Fatalf("%s.Convert(%s, %s) = %s, want %s", tt.m, tt.rate, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		rate string
+		ok   bool
+	}{
+		{"32.4517", true},
+		{" 1 ", true},
+		{"1/3", true},
+		{"0", false},
+		{"-1.5", false},
+		{"", false},
+		{"abc", false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := ParseRate(tt.rate); ok != tt.ok {
+			t.Errorf("ParseRate(%q) ok = %v, want %v", tt.rate, ok, tt.ok)
+		}
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+		ok   bool
+	}{
+		{"USD", "USD", true},
+		{" try ", "TRY", true},
+		{"jpy", "JPY", true},
+		{"XXX", "XXX", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseCurrency(tt.code)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseCurrency(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -31,3 +32,12 @@ func GetMongoCollection(client *mongo.Client, dbName, colName string) (*mongo.Co
 	fmt.Println("Connected databased")
 	return col, nil
 }
+
+// VersionFilter matches documents at the given version. Documents written before
+// versioning was introduced have no version field and count as version 0.
+func VersionFilter(version int) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
@@ -0,0 +1,136 @@
+// Package pagination implements keyset (cursor) pagination for list
+// endpoints. A listing is ordered by one field and then by _id, and a page
+// continues strictly after the last item of the previous one, so pages stay
+// cheap on large collections and do not shift when documents are inserted.
+package pagination
+
+import (
+	"encoding/base64"
+	"strconv"
+	"tesodev-korpes/pkg/customError"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// Page is the response envelope shared by the list endpoints. NextCursor is
+// empty on the last page; Total is only set when it was asked for.
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// Params are the pagination query parameters of a list request.
+type Params struct {
+	Limit     int
+	After     *Cursor
+	WithTotal bool
+}
+
+// Cursor is the position of the last item of a page: its value of the sort
+// field and its id. The field and direction are kept so a cursor cannot be
+// replayed against a listing sorted differently.
+type Cursor struct {
+	Field string      `bson:"f"`
+	Desc  bool        `bson:"d"`
+	Value interface{} `bson:"v"`
+	Id    string      `bson:"i"`
+}
+
+// ParseParams reads the limit, cursor and total query parameters. A missing or
+// unparsable limit falls back to DefaultLimit and limits above MaxLimit are
+// capped; an unreadable cursor is an error.
+func ParseParams(limit, cursor, total string) (Params, error) {
+	params := Params{Limit: DefaultLimit}
+
+	if l, err := strconv.Atoi(limit); err == nil && l > 0 {
+		params.Limit = min(l, MaxLimit)
+	}
+
+	if cursor != "" {
+		after, err := DecodeCursor(cursor)
+		if err != nil {
+			return Params{}, err
+		}
+		params.After = after
+	}
+
+	params.WithTotal, _ = strconv.ParseBool(total)
+	return params, nil
+}
+
+// Encode turns the cursor into an opaque URL-safe token. BSON keeps the type
+// of the sort value, so dates and integers compare correctly when decoded.
+func (c *Cursor) Encode() string {
+	data, err := bson.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, customError.NewBadRequest(customError.InvalidCursor)
+	}
+
+	var cursor Cursor
+	if err := bson.Unmarshal(data, &cursor); err != nil || cursor.Field == "" || cursor.Id == "" {
+		return nil, customError.NewBadRequest(customError.InvalidCursor)
+	}
+	return &cursor, nil
+}
+
+// Sort orders a listing by field and then by _id, in the same direction.
+func Sort(field string, desc bool) bson.D {
+	direction := 1
+	if desc {
+		direction = -1
+	}
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}
+}
+
+// After narrows filter to the items following the cursor in a listing sorted
+// with Sort(field, desc). The filter is returned unchanged without a cursor.
+func After(filter bson.M, field string, desc bool, after *Cursor) (bson.M, error) {
+	if after == nil {
+		return filter, nil
+	}
+	if after.Field != field || after.Desc != desc {
+		return nil, customError.NewBadRequest(customError.InvalidCursor)
+	}
+
+	op := "$gt"
+	if desc {
+		op = "$lt"
+	}
+	keyset := bson.M{"$or": bson.A{
+		bson.M{field: bson.M{op: after.Value}},
+		bson.M{field: after.Value, "_id": bson.M{op: after.Id}},
+	}}
+
+	if len(filter) == 0 {
+		return keyset, nil
+	}
+	return bson.M{"$and": bson.A{filter, keyset}}, nil
+}
+
+// Trim drops the extra item a listing fetches beyond the limit to learn
+// whether another page follows, and returns the cursor of that page, or ""
+// when items is the last page. key returns the sort value and id of an item.
+func Trim[T any](items []T, params Params, field string, desc bool, key func(T) (interface{}, string)) ([]T, string) {
+	if len(items) <= params.Limit {
+		return items, ""
+	}
+
+	items = items[:params.Limit]
+	value, id := key(items[len(items)-1])
+	next := &Cursor{Field: field, Desc: desc, Value: value, Id: id}
+	return items, next.Encode()
+}
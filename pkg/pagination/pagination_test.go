@@ -0,0 +1,166 @@
+package pagination
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseParams(t *testing.T) {
+	cursor := (&Cursor{Field: "created_at", Value: "2026-01-01", Id: "a"}).Encode()
+
+	tests := []struct {
+		name      string
+		limit     string
+		cursor    string
+		total     string
+		wantLimit int
+		wantAfter bool
+		wantTotal bool
+		wantErr   bool
+	}{
+		{"defaults", "", "", "", DefaultLimit, false, false, false},
+		{"limit", "25", "", "", 25, false, false, false},
+		{"limit capped", "1000", "", "", MaxLimit, false, false, false},
+		{"zero limit", "0", "", "", DefaultLimit, false, false, false},
+		{"negative limit", "-5", "", "", DefaultLimit, false, false, false},
+		{"unparsable limit", "ten", "", "", DefaultLimit, false, false, false},
+		{"with total", "", "", "true", DefaultLimit, false, true, false},
+		{"unparsable total", "", "", "yes", DefaultLimit, false, false, false},
+		{"cursor", "5", cursor, "1", 5, true, true, false},
+		{"not base64", "", "%%%", "", 0, false, false, true},
+		{"not a cursor", "", "aGVsbG8", "", 0, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ParseParams(tt.limit, tt.cursor, tt.total)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseParams error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseParams error = %v", err)
+			}
+			if params.Limit != tt.wantLimit || (params.After != nil) != tt.wantAfter || params.WithTotal != tt.wantTotal {
+				t.Fatalf("ParseParams = %+v, want limit %d, cursor %v, total %v", params, tt.wantLimit, tt.wantAfter, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"string", "ORDERED"},
+		{"integer", int64(1250)},
+		{"date", at},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := (&Cursor{Field: "f", Desc: true, Value: tt.value, Id: "id-1"}).Encode()
+			got, err := DecodeCursor(token)
+			if err != nil {
+				t.Fatalf("DecodeCursor error = %v", err)
+			}
+
+			// Dates come back as BSON dates; compare them as times.
+			value := got.Value
+			if dt, ok := value.(interface{ Time() time.Time }); ok {
+				value = dt.Time().UTC()
+			}
+			if got.Field != "f" || !got.Desc || got.Id != "id-1" || !reflect.DeepEqual(value, tt.value) {
+				t.Fatalf("DecodeCursor = %+v (value %T %v), want %v", got, got.Value, value, tt.value)
+			}
+		})
+	}
+}
+
+func TestAfter(t *testing.T) {
+	asc := &Cursor{Field: "created_at", Value: "v", Id: "i"}
+	desc := &Cursor{Field: "created_at", Desc: true, Value: "v", Id: "i"}
+
+	keyset := func(op string) bson.M {
+		return bson.M{"$or": bson.A{
+			bson.M{"created_at": bson.M{op: "v"}},
+			bson.M{"created_at": "v", "_id": bson.M{op: "i"}},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		filter  bson.M
+		desc    bool
+		after   *Cursor
+		want    bson.M
+		wantErr bool
+	}{
+		{"no cursor", bson.M{"status": "PAID"}, false, nil, bson.M{"status": "PAID"}, false},
+		{"ascending", nil, false, asc, keyset("$gt"), false},
+		{"descending", bson.M{}, true, desc, keyset("$lt"), false},
+		{"combined with the filter", bson.M{"status": "PAID"}, false, asc, bson.M{"$and": bson.A{bson.M{"status": "PAID"}, keyset("$gt")}}, false},
+		{"other direction", nil, true, asc, nil, true},
+		{"other field", nil, false, &Cursor{Field: "total_price", Value: "v", Id: "i"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := After(tt.filter, "created_at", tt.desc, tt.after)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("After error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("After = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrim(t *testing.T) {
+	key := func(n int) (interface{}, string) { return n * 10, string(rune('a' + n)) }
+
+	tests := []struct {
+		name      string
+		items     []int
+		limit     int
+		wantItems []int
+		wantNext  *Cursor
+	}{
+		{"empty", []int{}, 2, []int{}, nil},
+		{"fewer than the limit", []int{0}, 2, []int{0}, nil},
+		{"exactly the limit", []int{0, 1}, 2, []int{0, 1}, nil},
+		{"one more than the limit", []int{0, 1, 2}, 2, []int{0, 1}, &Cursor{Field: "f", Value: int32(10), Id: "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, next := Trim(tt.items, Params{Limit: tt.limit}, "f", false, key)
+			if !reflect.DeepEqual(items, tt.wantItems) {
+				t.Fatalf("Trim items = %v, want %v", items, tt.wantItems)
+			}
+			if tt.wantNext == nil {
+				if next != "" {
+					t.Fatalf("Trim cursor = %q, want none", next)
+				}
+				return
+			}
+
+			cursor, err := DecodeCursor(next)
+			if err != nil {
+				t.Fatalf("DecodeCursor error = %v", err)
+			}
+			if !reflect.DeepEqual(cursor, tt.wantNext) {
+				t.Fatalf("Trim cursor = %+v, want %+v", cursor, tt.wantNext)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,54 +16,34 @@ type ServiceUrls struct {
 	CustomerServiceURL string
 }
 
+// AuthConfig names the PEM files of the JWT keys. SigningKeyFile holds the
+// private key tokens are signed with; VerificationKeysDir holds the public
+// keys, one <kid>.pem per key, that tokens are also accepted from. Tokens
+// must carry Issuer and Audience. UserStatusCacheTTL bounds how stale the
+// cached account status may be, RevocationCacheTTL how long a token revoked
+// by another service may still be accepted; zero turns either cache off.
 type AuthConfig struct {
-	JWTSecret string
+	SigningKeyFile      string
+	SigningKeyID        string
+	VerificationKeysDir string
+	Issuer              string
+	Audience            string
+	UserStatusCacheTTL  time.Duration
+	RevocationCacheTTL  time.Duration
 }
 
+// Config holds the RBAC policy shared by all services. The policy is swapped
+// atomically on reload, so readers always see one complete version.
 type Config struct {
-	RoleMapping       map[string]string
-	EndpointRoles     map[string][]string
-	EndpointRolesPath map[string][]string
-}
+	policyFile string
+	policy     atomic.Pointer[Policy]
 
-var Cfg = Config{
-	EndpointRoles: map[string][]string{
-		"CustomerRead":   {"admin", "manager", "user"},
-		"CustomerWrite":  {"admin", "manager"},
-		"CustomerDelete": {"admin"},
-		"CustomerList":   {"admin", "manager"},
-
-		"OrderCreate": {"premium", "non-premium", "user"},
-		"OrderRead":   {"admin", "manager", "user"},
-		"OrderUpdate": {"admin", "manager"},
-		"OrderCancel": {"admin", "manager", "user"},
-		"OrderList":   {"admin", "manager"},
-	},
-	EndpointRolesPath: map[string][]string{
-
-		"POST /customer/create": []string{},
-		"POST /customer/login":  []string{},
-
-		"GET /customer/verify":       {"admin"},
-		"GET /customer/list":         {"admin", "manager", "user"},
-		"GET /customer/:id":          {"admin", "manager", "user"},
-		"GET /customer/email/:email": {"admin", "manager", "user"},
-		"PUT /customer/:id":          {"admin", "manager", "user"},
-		"DELETE /customer/:id":       {"admin"},
-
-		"POST /order":              {"admin", "manager", "user"},
-		"GET /order/list":          {"admin", "manager", "user"},
-		"GET /order/:id":           {"admin", "manager", "user"},
-		"PATCH /order/:id/ship":    {"admin", "manager", "user"},
-		"PATCH /order/:id/deliver": {"admin", "manager", "user"},
-		"DELETE /order/cancel/:id": {"admin", "manager", "user"},
-	},
-	RoleMapping: map[string]string{
-		"premium":     "/internal/price/premium/:id",
-		"non-premium": "/internal/price/non-premium/:id",
-	},
+	mu     sync.Mutex
+	checks []PolicyCheck
 }
 
+var Cfg Config
+
 var cfgs = map[string]DbConfig{
 	"prod": {
 		MongoDuration: time.Second * 100,
@@ -90,11 +72,27 @@ func init() {
 		CustomerServiceURL: os.Getenv("CUSTOMER_SERVICE_URL"),
 	}
 	authConfig = AuthConfig{
-		JWTSecret: os.Getenv("JWT_SECRET"),
+		SigningKeyFile:      os.Getenv("JWT_SIGNING_KEY_FILE"),
+		SigningKeyID:        os.Getenv("JWT_SIGNING_KEY_ID"),
+		VerificationKeysDir: os.Getenv("JWT_VERIFICATION_KEYS_DIR"),
+		Issuer:              getenv("JWT_ISSUER", "tesodev-korpes/customer"),
+		Audience:            getenv("JWT_AUDIENCE", "tesodev-korpes"),
+		UserStatusCacheTTL:  30 * time.Second,
+		RevocationCacheTTL:  5 * time.Second,
 	}
-
-	if authConfig.JWTSecret == "" {
-		panic("JWT_SECRET environment variable not set")
+	if ttl := os.Getenv("USER_STATUS_CACHE_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d < 0 {
+			panic("USER_STATUS_CACHE_TTL must be a non-negative duration such as 30s")
+		}
+		authConfig.UserStatusCacheTTL = d
+	}
+	if ttl := os.Getenv("REVOCATION_CACHE_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d < 0 {
+			panic("REVOCATION_CACHE_TTL must be a non-negative duration such as 5s")
+		}
+		authConfig.RevocationCacheTTL = d
 	}
 }
 
@@ -114,3 +112,10 @@ func GetServiceURLs() ServiceUrls {
 func GetAuthConfig() AuthConfig {
 	return authConfig
 }
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
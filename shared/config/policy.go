@@ -0,0 +1,232 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PublicPermission marks a route that needs no authentication.
+const PublicPermission = "public"
+
+var policyMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true,
+	"PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+// RoleDefinition is a role as written in the policy file.
+type RoleDefinition struct {
+	Inherits    []string `json:"inherits,omitempty" yaml:"inherits,omitempty"`
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+}
+
+// PolicyFile is the layout of the RBAC policy file, YAML or JSON.
+type PolicyFile struct {
+	Roles       map[string]RoleDefinition `json:"roles" yaml:"roles"`
+	Routes      map[string]string         `json:"routes" yaml:"routes"`
+	Memberships map[string]string         `json:"memberships" yaml:"memberships"`
+}
+
+// Policy is a validated policy file with role inheritance resolved: every
+// route maps straight to the roles allowed on it.
+type Policy struct {
+	routes      map[string][]string
+	memberships map[string]string
+}
+
+// LoadPolicy reads and validates the policy file at path. Files ending in
+// .json are decoded as JSON, everything else as YAML.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file PolicyFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&file)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	policy, err := file.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Compile validates the file and resolves role inheritance. It rejects
+// unknown or cyclic inherited roles, malformed route keys, and permissions
+// that no role holds.
+func (f *PolicyFile) Compile() (*Policy, error) {
+	var problems []string
+
+	if len(f.Roles) == 0 {
+		problems = append(problems, "no roles defined")
+	}
+	for name, role := range f.Roles {
+		if strings.TrimSpace(name) == "" {
+			problems = append(problems, "role with an empty name")
+		}
+		for _, parent := range role.Inherits {
+			if _, ok := f.Roles[parent]; !ok {
+				problems = append(problems, fmt.Sprintf("role %q inherits unknown role %q", name, parent))
+			}
+		}
+		for _, permission := range role.Permissions {
+			if permission == PublicPermission {
+				problems = append(problems, fmt.Sprintf("role %q grants the reserved permission %q", name, PublicPermission))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		if cycle := f.inheritanceCycle(); cycle != nil {
+			problems = append(problems, "role inheritance cycle: "+strings.Join(cycle, " -> "))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, policyError(problems)
+	}
+
+	granted := make(map[string][]string)
+	for name := range f.Roles {
+		for permission := range f.permissions(name) {
+			granted[permission] = append(granted[permission], name)
+		}
+	}
+
+	routes := make(map[string][]string, len(f.Routes))
+	for key, permission := range f.Routes {
+		if !isPolicyKey(key) {
+			problems = append(problems, fmt.Sprintf("route %q is not \"METHOD /path\"", key))
+			continue
+		}
+		if permission == PublicPermission {
+			routes[key] = []string{}
+			continue
+		}
+		roles, ok := granted[permission]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("route %q needs permission %q, which no role holds", key, permission))
+			continue
+		}
+		sort.Strings(roles)
+		routes[key] = roles
+	}
+
+	memberships := make(map[string]string, len(f.Memberships))
+	for membership, path := range f.Memberships {
+		if !strings.HasPrefix(path, "/") {
+			problems = append(problems, fmt.Sprintf("membership %q routes to %q, which is not a path", membership, path))
+			continue
+		}
+		memberships[membership] = path
+	}
+
+	if len(problems) > 0 {
+		return nil, policyError(problems)
+	}
+	return &Policy{routes: routes, memberships: memberships}, nil
+}
+
+// RouteRoles returns the roles allowed on the route key "METHOD /path". An
+// empty list means the route is public; found is false when the policy does
+// not mention the route.
+func (p *Policy) RouteRoles(key string) (roles []string, found bool) {
+	roles, found = p.routes[key]
+	return roles, found
+}
+
+// MembershipRoute returns the internal path template of a membership tier.
+func (p *Policy) MembershipRoute(membership string) (string, bool) {
+	path, ok := p.memberships[membership]
+	return path, ok
+}
+
+// permissions collects the permissions of a role and of every role it
+// inherits from. Inheritance must already be known to be acyclic.
+func (f *PolicyFile) permissions(name string) map[string]bool {
+	result := make(map[string]bool)
+	var walk func(string)
+	walk = func(role string) {
+		for _, permission := range f.Roles[role].Permissions {
+			result[permission] = true
+		}
+		for _, parent := range f.Roles[role].Inherits {
+			walk(parent)
+		}
+	}
+	walk(name)
+	return result
+}
+
+// inheritanceCycle returns the roles of one inheritance cycle, or nil.
+func (f *PolicyFile) inheritanceCycle() []string {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(f.Roles))
+	var path []string
+
+	var visit func(string) []string
+	visit = func(role string) []string {
+		switch state[role] {
+		case done:
+			return nil
+		case visiting:
+			for i, r := range path {
+				if r == role {
+					return append(append([]string{}, path[i:]...), role)
+				}
+			}
+		}
+		state[role] = visiting
+		path = append(path, role)
+		for _, parent := range f.Roles[role].Inherits {
+			if cycle := visit(parent); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[role] = done
+		return nil
+	}
+
+	names := make([]string, 0, len(f.Roles))
+	for name := range f.Roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+func isPolicyKey(key string) bool {
+	method, path, ok := strings.Cut(key, " ")
+	return ok && policyMethods[method] && strings.HasPrefix(path, "/") && !strings.ContainsAny(path, " \t")
+}
+
+func policyError(problems []string) error {
+	sort.Strings(problems)
+	return errors.New("invalid policy: " + strings.Join(problems, "; "))
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const defaultPolicyFile = "shared/config/rbac.yaml"
+
+// PolicyCheck vets a policy before it goes live, e.g. that every route of a
+// running service still has a policy.
+type PolicyCheck func(*Policy) error
+
+// Policy returns the policy currently in force.
+func (c *Config) Policy() *Policy {
+	return c.policy.Load()
+}
+
+// LoadPolicyFile loads the policy at path and remembers the path for later
+// reloads.
+func (c *Config) LoadPolicyFile(path string) error {
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.runChecks(policy); err != nil {
+		return err
+	}
+	c.policyFile = path
+	c.policy.Store(policy)
+	return nil
+}
+
+// LoadConfiguredPolicy loads the policy file named by RBAC_POLICY_FILE, or
+// the default one. main calls it before any service is booted.
+func (c *Config) LoadConfiguredPolicy() error {
+	return c.LoadPolicyFile(getenv("RBAC_POLICY_FILE", defaultPolicyFile))
+}
+
+// ReloadPolicy reads the policy file again. On any error the current policy
+// stays in force.
+func (c *Config) ReloadPolicy() error {
+	c.mu.Lock()
+	path := c.policyFile
+	c.mu.Unlock()
+	if path == "" {
+		return errors.New("no policy file loaded")
+	}
+	return c.LoadPolicyFile(path)
+}
+
+// AddPolicyCheck runs check against the current policy and, if it passes,
+// against every policy loaded afterwards.
+func (c *Config) AddPolicyCheck(check PolicyCheck) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := check(c.policy.Load()); err != nil {
+		return err
+	}
+	c.checks = append(c.checks, check)
+	return nil
+}
+
+// WatchPolicy reloads the policy on SIGHUP and whenever the file's size or
+// modification time changes, polled every interval. It never returns; run it
+// in its own goroutine.
+func (c *Config) WatchPolicy(interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.mu.Lock()
+	path := c.policyFile
+	c.mu.Unlock()
+	last, _ := os.Stat(path)
+
+	for {
+		select {
+		case <-hangup:
+			c.reloadAndLog("SIGHUP")
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil || (last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size()) {
+				continue
+			}
+			last = info
+			c.reloadAndLog("file change")
+		}
+	}
+}
+
+func (c *Config) reloadAndLog(reason string) {
+	if err := c.ReloadPolicy(); err != nil {
+		log.Printf("rbac: reload on %s rejected, keeping current policy: %v", reason, err)
+		return
+	}
+	log.Printf("rbac: policy reloaded on %s", reason)
+}
+
+func (c *Config) runChecks(policy *Policy) error {
+	for _, check := range c.checks {
+		if err := check(policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}